@@ -0,0 +1,119 @@
+package humanlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestIDHeader is the header HTTPMiddleware reads and sets the
+// request ID in.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// HTTPMiddlewareOptions configures HTTPMiddleware.
+type HTTPMiddlewareOptions struct {
+	// RequestIDHeader is the header propagating the request ID. An
+	// incoming ID is reused; otherwise one is generated. The ID is
+	// echoed in the response.
+	// Default: "X-Request-ID"
+	RequestIDHeader string
+
+	// NewRequestID generates request IDs.
+	// Default: 16 random bytes, hex-encoded
+	NewRequestID func() string
+
+	// Message is the message of access-log records.
+	// Default: "HTTP request"
+	Message string
+
+	// Level returns the level of the record for a response status.
+	// Default: Error for 5xx, Warn for 4xx, Info otherwise
+	Level func(status int) slog.Level
+
+	// Skip, if set, disables logging for requests it returns true for,
+	// such as health checks. Skipped requests still get a request ID.
+	Skip func(r *http.Request) bool
+}
+
+// HTTPMiddleware returns net/http middleware that assigns each request an
+// ID, stores it in the context with WithRequestID, times the request and
+// logs one record per request with the request ID and an HTTPRequest
+// under the "request" key.
+func HTTPMiddleware(logger *slog.Logger, opts *HTTPMiddlewareOptions) func(http.Handler) http.Handler {
+	var options HTTPMiddlewareOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.RequestIDHeader == "" {
+		options.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if options.NewRequestID == nil {
+		options.NewRequestID = newRequestID
+	}
+	if options.Message == "" {
+		options.Message = "HTTP request"
+	}
+	if options.Level == nil {
+		options.Level = statusLevel
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(options.RequestIDHeader)
+			if id == "" {
+				id = options.NewRequestID()
+			}
+			w.Header().Set(options.RequestIDHeader, id)
+			ctx := WithRequestID(r.Context(), id)
+			r = r.WithContext(ctx)
+
+			rw := NewResponseWriter(w)
+			next.ServeHTTP(rw, r)
+
+			if options.Skip != nil && options.Skip(r) {
+				return
+			}
+			// A handler that writes nothing implicitly responds 200 OK,
+			// unless it took over the connection
+			status := rw.Status()
+			if status == 0 && !rw.Hijacked() {
+				status = http.StatusOK
+			}
+			logger.LogAttrs(ctx, options.Level(status), options.Message,
+				slog.String(string(RequestIDKey), id),
+				slog.Any("request", HTTPRequest{
+					Method:       r.Method,
+					URL:          r.URL.String(),
+					RemoteAddr:   r.RemoteAddr,
+					UserAgent:    r.UserAgent(),
+					StatusCode:   status,
+					Duration:     time.Since(start),
+					ResponseSize: rw.BytesWritten(),
+				}),
+			)
+		})
+	}
+}
+
+// statusLevel is the default HTTPMiddlewareOptions.Level.
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// newRequestID returns a random 128-bit hex request ID.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,57 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelInfo, UseJSON: true}))
+
+	var ctxID string
+	handler := HTTPMiddleware(logger, &HTTPMiddlewareOptions{
+		NewRequestID: func() string { return "generated" },
+		Skip:         func(r *http.Request) bool { return r.URL.Path == "/healthz" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID, _ = r.Context().Value(RequestIDKey).(string)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	if got := rec.Header().Get(DefaultRequestIDHeader); got != "generated" || ctxID != "generated" {
+		t.Errorf("request ID header = %q, context = %q, want generated", got, ctxID)
+	}
+	m := decodeJSONLine(t, &buf)
+	request, _ := m["request"].(map[string]any)
+	if m["msg"] != "HTTP request" || m["level"] != "INFO" || m["request_id"] != "generated" {
+		t.Errorf("record = %v", m)
+	}
+	if request["status_code"] != 200.0 || request["response_size"] != 5.0 || request["url"] != "/hello" {
+		t.Errorf("request = %v", request)
+	}
+
+	buf.Reset()
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set(DefaultRequestIDHeader, "incoming")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	m = decodeJSONLine(t, &buf)
+	request, _ = m["request"].(map[string]any)
+	if m["level"] != "WARN" || m["request_id"] != "incoming" || request["status_code"] != 404.0 {
+		t.Errorf("404 record = %v", m)
+	}
+
+	buf.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if buf.Len() != 0 {
+		t.Errorf("skipped request logged: %q", buf.String())
+	}
+}
@@ -0,0 +1,216 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxAlertEntries bounds the records remembered within an alert window.
+const maxAlertEntries = 10000
+
+// Alert summarizes a burst of records that crossed an AlertHandler's
+// threshold.
+type Alert struct {
+	// Count is the number of records within the window.
+	Count int `json:"count"`
+
+	// Window is the configured window length.
+	Window time.Duration `json:"window"`
+
+	// First and Last are the times of the oldest and newest record.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+
+	// Messages counts the records by message, most frequent first.
+	Messages []MessageCount `json:"messages"`
+}
+
+// MessageCount is the number of records with a message.
+type MessageCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// AlertOptions configures an AlertHandler.
+type AlertOptions struct {
+	// Level is the minimum level counted. Default: slog.LevelError
+	Level slog.Leveler
+
+	// Threshold is the number of records within Window that must be
+	// exceeded to raise an alert.
+	Threshold int
+
+	// Window is the sliding time window records are counted in.
+	// Default: 1m
+	Window time.Duration
+
+	// Cooldown is the minimum time between alerts. Default: Window
+	Cooldown time.Duration
+
+	// OnAlert is called with a summary of the burst. It is called
+	// synchronously from Handle, outside any lock, so it should not block;
+	// see WebhookAlert.
+	OnAlert func(Alert)
+}
+
+// AlertHandler is a slog.Handler that passes records through and raises
+// an alert when more than Threshold records at or above Level occur within
+// Window, at most once per Cooldown.
+type AlertHandler struct {
+	h     slog.Handler
+	state *alertState
+}
+
+// alertState is shared by an AlertHandler and every handler derived from it.
+type alertState struct {
+	opts AlertOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries []alertEntry
+	quiet   time.Time // no alerts before this time
+}
+
+type alertEntry struct {
+	time    time.Time
+	message string
+}
+
+// NewAlertHandler returns a handler that passes records to h and raises
+// alerts on error bursts.
+func NewAlertHandler(h slog.Handler, opts AlertOptions) *AlertHandler {
+	if opts.Level == nil {
+		opts.Level = slog.LevelError
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = opts.Window
+	}
+	return &AlertHandler{h: h, state: &alertState{opts: opts, now: time.Now}}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (a *AlertHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return a.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler and counts it towards
+// the alert threshold.
+func (a *AlertHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := a.h.Handle(ctx, r)
+	if r.Level >= a.state.opts.Level.Level() {
+		if alert, ok := a.state.observe(r.Message); ok && a.state.opts.OnAlert != nil {
+			a.state.opts.OnAlert(alert)
+		}
+	}
+	return err
+}
+
+// WithAttrs returns a new AlertHandler sharing the alert state, whose
+// records include the given attributes.
+func (a *AlertHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AlertHandler{h: a.h.WithAttrs(attrs), state: a.state}
+}
+
+// WithGroup returns a new AlertHandler sharing the alert state, whose
+// records use the given group.
+func (a *AlertHandler) WithGroup(name string) slog.Handler {
+	return &AlertHandler{h: a.h.WithGroup(name), state: a.state}
+}
+
+// observe records a message and returns an alert if the threshold has
+// been crossed outside the cooldown.
+func (s *alertState) observe(message string) (Alert, bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-s.opts.Window)
+	i := 0
+	for i < len(s.entries) && !s.entries[i].time.After(cutoff) {
+		i++
+	}
+	s.entries = append(s.entries[i:], alertEntry{time: now, message: message})
+	if len(s.entries) > maxAlertEntries {
+		s.entries = s.entries[len(s.entries)-maxAlertEntries:]
+	}
+
+	if len(s.entries) <= s.opts.Threshold || now.Before(s.quiet) {
+		return Alert{}, false
+	}
+	s.quiet = now.Add(s.opts.Cooldown)
+	return s.summarize(), true
+}
+
+// summarize must be called with s.mu held.
+func (s *alertState) summarize() Alert {
+	counts := make(map[string]int)
+	for _, e := range s.entries {
+		counts[e.message]++
+	}
+	messages := make([]MessageCount, 0, len(counts))
+	for msg, n := range counts {
+		messages = append(messages, MessageCount{Message: msg, Count: n})
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Count != messages[j].Count {
+			return messages[i].Count > messages[j].Count
+		}
+		return messages[i].Message < messages[j].Message
+	})
+	return Alert{
+		Count:    len(s.entries),
+		Window:   s.opts.Window,
+		First:    s.entries[0].time,
+		Last:     s.entries[len(s.entries)-1].time,
+		Messages: messages,
+	}
+}
+
+// WebhookAlert returns an OnAlert callback that POSTs the alert as JSON
+// to url in the background. Delivery errors are passed to onError, which
+// may be nil.
+func WebhookAlert(client *http.Client, url string, onError func(error)) func(Alert) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return func(alert Alert) {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		go func() {
+			resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+			if err == nil {
+				_ = resp.Body.Close()
+				if resp.StatusCode >= 300 {
+					err = &webhookError{status: resp.Status}
+				}
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}()
+	}
+}
+
+// webhookError reports an unsuccessful webhook response.
+type webhookError struct {
+	status string
+}
+
+func (e *webhookError) Error() string {
+	return "humanlog: alert webhook returned " + e.status
+}
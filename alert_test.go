@@ -0,0 +1,74 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAlertHandler(t *testing.T) {
+	var buf bytes.Buffer
+	var alerts []Alert
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h := NewAlertHandler(NewHandler(&buf, &Options{DisableColor: true}), AlertOptions{
+		Threshold: 2,
+		Window:    10 * time.Second,
+		Cooldown:  time.Minute,
+		OnAlert:   func(a Alert) { alerts = append(alerts, a) },
+	})
+	h.state.now = func() time.Time { return now }
+	logger := slog.New(h)
+
+	logger.Warn("Not counted")
+	logger.Error("DB timeout")
+	now = now.Add(20 * time.Second) // the first error leaves the window
+	logger.Error("DB timeout")
+	logger.Error("Cache down")
+	if len(alerts) != 0 {
+		t.Fatalf("got %d alerts, want none at the threshold", len(alerts))
+	}
+
+	logger.Error("DB timeout")
+	logger.Error("DB timeout") // within cooldown
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	a := alerts[0]
+	if a.Count != 3 || a.Messages[0] != (MessageCount{"DB timeout", 2}) || a.Messages[1] != (MessageCount{"Cache down", 1}) {
+		t.Errorf("alert = %+v", a)
+	}
+
+	now = now.Add(time.Minute)
+	logger.Error("DB timeout")
+	logger.Error("DB timeout")
+	logger.Error("DB timeout")
+	if len(alerts) != 2 {
+		t.Errorf("got %d alerts, want a second one after the cooldown", len(alerts))
+	}
+}
+
+func TestWebhookAlert(t *testing.T) {
+	received := make(chan Alert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		_ = json.NewDecoder(r.Body).Decode(&a)
+		received <- a
+	}))
+	defer srv.Close()
+
+	WebhookAlert(srv.Client(), srv.URL, func(err error) { t.Errorf("webhook error = %v", err) })(Alert{Count: 7})
+
+	select {
+	case a := <-received:
+		if a.Count != 7 {
+			t.Errorf("received alert = %+v, want Count 7", a)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
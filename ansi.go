@@ -0,0 +1,108 @@
+package humanlog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSIMode controls how ANSI escape sequences already embedded in messages
+// and values, such as captured subprocess output, are rendered.
+type ANSIMode int
+
+const (
+	// ANSIEscape renders sequences visibly, e.g. \x1b[31m, like other
+	// control characters. This is the default.
+	ANSIEscape ANSIMode = iota
+	// ANSIStrip removes the sequences, keeping the text they style.
+	ANSIStrip
+	// ANSIPassThrough writes the sequences unchanged so the terminal
+	// interprets them. They do not count towards the message width, and
+	// truncated or colored text is followed by a reset.
+	ANSIPassThrough
+)
+
+// ansiSequence matches CSI sequences such as colors and cursor movement,
+// OSC sequences such as hyperlinks, and two-character escapes.
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|\x1b[@-Z\\\\-_]")
+
+// stripANSI removes ANSI escape sequences from s.
+func stripANSI(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+// visibleWidth returns the number of characters of s shown on screen,
+// not counting ANSI escape sequences.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(stripANSI(s))
+}
+
+// truncateVisible cuts s after width visible characters, keeping the
+// escape sequences before the cut, and reports whether it cut anything.
+func truncateVisible(s string, width int) (string, bool) {
+	var sb strings.Builder
+	n := 0
+	for s != "" {
+		if s[0] == '\x1b' {
+			if loc := ansiSequence.FindStringIndex(s); loc != nil && loc[0] == 0 {
+				sb.WriteString(s[:loc[1]])
+				s = s[loc[1]:]
+				continue
+			}
+		}
+		if n == width {
+			return sb.String(), true
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		sb.WriteString(s[:size])
+		s = s[size:]
+		n++
+	}
+	return sb.String(), false
+}
+
+// cleanText applies the ANSI and control character handling of opts to
+// text that is written unquoted.
+func cleanText(s string, opts *Options) string {
+	switch opts.ANSI {
+	case ANSIStrip:
+		s = stripANSI(s)
+	case ANSIPassThrough:
+		return mapOutsideANSI(s, func(part string) string { return sanitize(part, opts.ControlChars) })
+	}
+	return sanitize(s, opts.ControlChars)
+}
+
+// quoteANSI quotes s like strconv.Quote but leaves its ANSI escape
+// sequences unescaped.
+func quoteANSI(s string) string {
+	return `"` + mapOutsideANSI(s, func(part string) string {
+		q := strconv.Quote(part)
+		return q[1 : len(q)-1]
+	}) + `"`
+}
+
+// mapOutsideANSI applies f to the parts of s between ANSI escape
+// sequences, ending with a reset if s contains any.
+func mapOutsideANSI(s string, f func(string) string) string {
+	locs := ansiSequence.FindAllStringIndex(s, -1)
+	if len(locs) == 0 {
+		return f(s)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, loc := range locs {
+		sb.WriteString(f(s[last:loc[0]]))
+		sb.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(f(s[last:]))
+	if !strings.HasSuffix(s, colorReset) {
+		sb.WriteString(colorReset)
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestANSIModes(t *testing.T) {
+	const red = "\x1b[31m"
+	log := func(mode ANSIMode) string {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &Options{ANSI: mode, Deterministic: true, TimeFormat: TimeFormatSeconds, MessageWidth: 8}))
+		logger.Info("Build "+red+"failed"+colorReset, "out", red+"FAIL"+colorReset, "line", red+"exit 1"+colorReset)
+		return buf.String()
+	}
+
+	tests := []struct {
+		mode ANSIMode
+		want string
+	}{
+		{ANSIEscape, `[00:00:00] INFO  Build... line="\x1b[31mexit 1\x1b[0m" out="\x1b[31mFAIL\x1b[0m"` + "\n"},
+		{ANSIStrip, `[00:00:00] INFO  Build... line="exit 1" out=FAIL` + "\n"},
+		{ANSIPassThrough, "[00:00:00] INFO  Build... line=\"" + red + "exit 1" + colorReset + "\" out=" + red + "FAIL" + colorReset + "\n"},
+	}
+	for _, tt := range tests {
+		if got := log(tt.mode); got != tt.want {
+			t.Errorf("mode %d output =\n%q\nwant\n%q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestANSIPassThroughPadding(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{ANSI: ANSIPassThrough, DisableColor: true, MessageWidth: 6})
+	if got, want := h.formatMessage("\x1b[1mok\x1b[0m"), "\x1b[1mok\x1b[0m    "; got != want {
+		t.Errorf("formatMessage() = %q, want %q", got, want)
+	}
+	if got, want := (Column{Width: 4}).fit("\x1b[1mok\x1b[0m"), "\x1b[1mok\x1b[0m  "; got != want {
+		t.Errorf("fit() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,355 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for rendering composite KindAny values.
+const (
+	defaultAnyMaxDepth    = 5
+	defaultAnyMaxElements = 20
+)
+
+// AnyFormat controls how composite KindAny values (structs, maps, slices)
+// are rendered.
+type AnyFormat int
+
+const (
+	// AnyDefault renders values with fmt's %v verb. This is the default.
+	AnyDefault AnyFormat = iota
+	// AnyJSON renders values as compact JSON, e.g. {"id":1,"tags":["a","b"]}.
+	AnyJSON
+	// AnyGoSyntax renders values in Go-like syntax, e.g. {ID:1 Tags:[a b]}.
+	AnyGoSyntax
+)
+
+// anyRenderer walks a value with depth, size and cycle limits.
+type anyRenderer struct {
+	format      AnyFormat
+	maxDepth    int
+	maxElements int
+	sb          strings.Builder
+	visiting    map[uintptr]bool
+}
+
+// formatAny renders v according to opts.AnyFormat. Values that are not
+// structs, maps, slices, arrays or pointers to them use fmt's %v verb.
+func formatAny(v any, opts *Options) string {
+	if opts.AnyFormat == AnyDefault || !isComposite(reflect.ValueOf(v)) {
+		return fmt.Sprint(v)
+	}
+
+	r := &anyRenderer{
+		format:      opts.AnyFormat,
+		maxDepth:    opts.AnyMaxDepth,
+		maxElements: opts.AnyMaxElements,
+		visiting:    make(map[uintptr]bool),
+	}
+	if r.maxDepth <= 0 {
+		r.maxDepth = defaultAnyMaxDepth
+	}
+	if r.maxElements <= 0 {
+		r.maxElements = defaultAnyMaxElements
+	}
+	r.render(reflect.ValueOf(v), 0)
+	return r.sb.String()
+}
+
+// selfFormat renders v through the first standard interface it implements,
+// in priority order: fmt.Stringer, encoding.TextMarshaler, json.Marshaler.
+// isJSON reports whether s is compact JSON that needs no further quoting.
+// ok is false if v implements none of them, is a nil pointer, or
+// marshaling fails.
+func selfFormat(v any) (s string, isJSON, ok bool) {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return "", false, false
+	}
+
+	switch x := v.(type) {
+	case fmt.Stringer:
+		return x.String(), false, true
+	case encoding.TextMarshaler:
+		text, err := x.MarshalText()
+		if err != nil {
+			return "", false, false
+		}
+		return string(text), false, true
+	case json.Marshaler:
+		data, err := x.MarshalJSON()
+		if err != nil {
+			return "", false, false
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return "", false, false
+		}
+		return compact.String(), true, true
+	}
+	return "", false, false
+}
+
+// isComposite reports whether v is a value formatAny renders structurally.
+func isComposite(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		// Leave opaque library structs such as time.Time to %v
+		return v.Type() != reflect.TypeOf(time.Time{})
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *anyRenderer) render(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		r.writeNil()
+		return
+	}
+
+	// Values that describe themselves are rendered as strings
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case time.Time:
+			r.writeString(x.Format(time.RFC3339))
+			return
+		case error:
+			if v.Kind() != reflect.Pointer || !v.IsNil() {
+				r.writeString(x.Error())
+				return
+			}
+		default:
+			if s, isJSON, ok := selfFormat(x); ok {
+				if isJSON {
+					r.sb.WriteString(s)
+				} else {
+					r.writeString(s)
+				}
+				return
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			r.writeNil()
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			if r.enter(v.Pointer()) {
+				return
+			}
+			defer delete(r.visiting, v.Pointer())
+			if r.format == AnyGoSyntax && v.Elem().Kind() == reflect.Struct {
+				r.sb.WriteByte('&')
+			}
+		}
+		r.render(v.Elem(), depth)
+
+	case reflect.Struct:
+		if r.tooDeep(depth) {
+			return
+		}
+		r.renderStruct(v, depth)
+
+	case reflect.Map:
+		if v.IsNil() {
+			r.writeNil()
+			return
+		}
+		if r.enter(v.Pointer()) || r.tooDeep(depth) {
+			return
+		}
+		defer delete(r.visiting, v.Pointer())
+		r.renderMap(v, depth)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			r.writeNil()
+			return
+		}
+		if v.Len() > 0 {
+			if r.enter(v.Pointer()) {
+				return
+			}
+			defer delete(r.visiting, v.Pointer())
+		}
+		if r.tooDeep(depth) {
+			return
+		}
+		r.renderList(v, depth)
+
+	case reflect.Array:
+		if r.tooDeep(depth) {
+			return
+		}
+		r.renderList(v, depth)
+
+	case reflect.String:
+		r.writeString(v.String())
+
+	case reflect.Bool:
+		r.sb.WriteString(strconv.FormatBool(v.Bool()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.sb.WriteString(strconv.FormatInt(v.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		r.sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		r.sb.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+
+	default:
+		// Channels, functions and other opaque kinds
+		r.writeString(v.Type().String())
+	}
+}
+
+func (r *anyRenderer) renderStruct(v reflect.Value, depth int) {
+	t := v.Type()
+	r.sb.WriteByte('{')
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if r.format == AnyJSON {
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if n == r.maxElements {
+			r.writeSeparator(n)
+			r.writeMore(t.NumField() - i)
+			break
+		}
+		r.writeSeparator(n)
+		r.writeKey(name)
+		r.render(v.Field(i), depth+1)
+		n++
+	}
+	r.sb.WriteByte('}')
+}
+
+func (r *anyRenderer) renderMap(v reflect.Value, depth int) {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	if r.format == AnyJSON {
+		r.sb.WriteByte('{')
+	} else {
+		r.sb.WriteString("map[")
+	}
+	for i, k := range keys {
+		r.writeSeparator(i)
+		if i == r.maxElements {
+			r.writeMore(len(keys) - i)
+			break
+		}
+		r.writeKey(fmt.Sprint(k.Interface()))
+		r.render(v.MapIndex(k), depth+1)
+	}
+	if r.format == AnyJSON {
+		r.sb.WriteByte('}')
+	} else {
+		r.sb.WriteByte(']')
+	}
+}
+
+func (r *anyRenderer) renderList(v reflect.Value, depth int) {
+	r.sb.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		r.writeSeparator(i)
+		if i == r.maxElements {
+			r.writeMore(v.Len() - i)
+			break
+		}
+		r.render(v.Index(i), depth+1)
+	}
+	r.sb.WriteByte(']')
+}
+
+// enter marks ptr as being rendered. It reports true, after writing a cycle
+// marker, if ptr is already on the current path.
+func (r *anyRenderer) enter(ptr uintptr) bool {
+	if r.visiting[ptr] {
+		r.writeString("<cycle>")
+		return true
+	}
+	r.visiting[ptr] = true
+	return false
+}
+
+// tooDeep reports true, after writing an elision marker, once depth
+// exceeds the configured maximum.
+func (r *anyRenderer) tooDeep(depth int) bool {
+	if depth < r.maxDepth {
+		return false
+	}
+	r.writeString("...")
+	return true
+}
+
+func (r *anyRenderer) writeSeparator(i int) {
+	if i == 0 {
+		return
+	}
+	if r.format == AnyJSON {
+		r.sb.WriteByte(',')
+	} else {
+		r.sb.WriteByte(' ')
+	}
+}
+
+func (r *anyRenderer) writeKey(name string) {
+	if r.format == AnyJSON {
+		r.sb.WriteString(strconv.Quote(name))
+	} else {
+		r.sb.WriteString(name)
+	}
+	r.sb.WriteByte(':')
+}
+
+func (r *anyRenderer) writeMore(n int) {
+	r.writeString(fmt.Sprintf("...+%d more", n))
+}
+
+func (r *anyRenderer) writeNil() {
+	if r.format == AnyJSON {
+		r.sb.WriteString("null")
+	} else {
+		r.sb.WriteString("nil")
+	}
+}
+
+func (r *anyRenderer) writeString(s string) {
+	if r.format == AnyJSON || needsQuoting(s) {
+		r.sb.WriteString(strconv.Quote(s))
+	} else {
+		r.sb.WriteString(s)
+	}
+}
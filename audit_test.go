@@ -0,0 +1,101 @@
+package humanlog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditWriter_Verify(t *testing.T) {
+	var out bytes.Buffer
+	w := NewAuditWriter(&out, nil)
+	logger := slog.New(NewHandler(w, &Options{DisableColor: true}))
+	logger.Info("User deleted", "user", 42)
+	logger.Warn("Role changed", "role", "admin")
+	_, _ = w.Write([]byte("partial "))
+	_, _ = w.Write([]byte("line\n"))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "user=42 chain=") || !strings.HasPrefix(lines[2], "partial line chain=") {
+		t.Fatalf("output = %q, want three chained lines", out.String())
+	}
+	summary, err := VerifyAuditLog(bytes.NewReader(out.Bytes()), nil)
+	if err != nil || summary.Lines != 3 {
+		t.Fatalf("VerifyAuditLog() = %+v, %v, want 3 lines", summary, err)
+	}
+
+	tests := []struct {
+		name     string
+		lines    []string
+		wantLine int
+	}{
+		{"edited", []string{lines[0], strings.Replace(lines[1], "admin", "guest", 1), lines[2]}, 2},
+		{"removed", []string{lines[0], lines[2]}, 2},
+		{"reordered", []string{lines[1], lines[0], lines[2]}, 1},
+		{"unchained", []string{lines[0], "INFO injected"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyAuditLog(strings.NewReader(strings.Join(tt.lines, "\n")+"\n"), nil)
+			var ae *AuditError
+			if !errors.As(err, &ae) || ae.Line != tt.wantLine {
+				t.Errorf("VerifyAuditLog() error = %v, want an AuditError on line %d", err, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestAuditWriter_Signatures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	w := NewAuditWriter(&out, &AuditOptions{SignKey: priv, SignEvery: 2})
+	for _, line := range []string{"a", "b", "c"} {
+		_, _ = w.Write([]byte(line + "\n"))
+	}
+
+	summary, err := VerifyAuditLog(bytes.NewReader(out.Bytes()), pub)
+	if err != nil || summary.Signatures != 1 || summary.Unsigned != 1 {
+		t.Errorf("before Close: VerifyAuditLog() = %+v, %v, want 1 signature and 1 unsigned line", summary, err)
+	}
+
+	_ = w.Close()
+	summary, err = VerifyAuditLog(bytes.NewReader(out.Bytes()), pub)
+	if err != nil || summary.Lines != 5 || summary.Signatures != 2 || summary.Unsigned != 0 {
+		t.Errorf("after Close: VerifyAuditLog() = %+v, %v, want 5 lines, 2 signatures", summary, err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if _, err := VerifyAuditLog(bytes.NewReader(out.Bytes()), otherPub); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("VerifyAuditLog(other key) error = %v, want an invalid signature", err)
+	}
+}
+
+func TestOpenAuditLog_Continues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for _, line := range []string{"first\n", "second\n"} {
+		w, err := OpenAuditLog(path, nil)
+		if err != nil {
+			t.Fatalf("OpenAuditLog() error = %v", err)
+		}
+		_, _ = w.Write([]byte(line))
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary, err := VerifyAuditLog(bytes.NewReader(data), nil); err != nil || summary.Lines != 2 {
+		t.Errorf("VerifyAuditLog() = %+v, %v, want 2 chained lines across opens", summary, err)
+	}
+}
@@ -0,0 +1,162 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// AuditOutcome is the result of an audited action.
+type AuditOutcome int
+
+const (
+	// AuditSuccess means the action was performed.
+	AuditSuccess AuditOutcome = iota + 1
+	// AuditFailure means the action was attempted and failed.
+	AuditFailure
+	// AuditDenied means the action was refused, e.g. by authorization.
+	AuditDenied
+)
+
+// String returns "success", "failure" or "denied".
+func (o AuditOutcome) String() string {
+	switch o {
+	case AuditSuccess:
+		return "success"
+	case AuditFailure:
+		return "failure"
+	case AuditDenied:
+		return "denied"
+	default:
+		return fmt.Sprintf("AuditOutcome(%d)", int(o))
+	}
+}
+
+// AuditEvent is one entry of the audit trail.
+type AuditEvent struct {
+	// Actor identifies who acted, e.g. a user or service account ID.
+	Actor string
+
+	// Action is what was done, e.g. "user.delete".
+	Action string
+
+	// Resource is what it was done to, e.g. "user/42".
+	Resource string
+
+	// Outcome is the result. It must be set explicitly.
+	Outcome AuditOutcome
+
+	// Reasons explain the outcome. At least one is required for failures
+	// and denials.
+	Reasons []string
+
+	// Attrs add context, e.g. the request ID. They cannot replace the
+	// schema fields.
+	Attrs []slog.Attr
+}
+
+// AuditLogger writes audit events with a fixed schema to a dedicated
+// handler, apart from diagnostic logs, so their level settings and
+// destinations are independent. Invalid events are refused rather than
+// recorded with missing or redacted fields, and so are events the handler
+// would drop, since an audit trail must not lose entries silently:
+//
+//	w, _ := humanlog.OpenAuditLog("audit.log", nil)
+//	audit := humanlog.NewAuditLogger(humanlog.NewHandler(w, &humanlog.Options{DisableColor: true}))
+//	err := audit.Log(ctx, humanlog.AuditEvent{
+//		Actor: "alice", Action: "user.delete", Resource: "user/42", Outcome: humanlog.AuditSuccess,
+//	})
+//
+// Records carry the message "audit" and the attributes actor, action,
+// resource, outcome and reasons, followed by the event's Attrs.
+type AuditLogger struct {
+	h slog.Handler
+}
+
+// NewAuditLogger returns an AuditLogger writing to h.
+func NewAuditLogger(h slog.Handler) *AuditLogger {
+	return &AuditLogger{h: h}
+}
+
+// auditSchemaKeys are the attribute keys reserved for the schema fields.
+var auditSchemaKeys = []string{"actor", "action", "resource", "outcome", "reasons"}
+
+// Log validates e and writes it. It returns an error, writing nothing, if
+// a required field is missing or redacted, the outcome is unknown, an
+// attribute reuses a schema key or the handler is not enabled for the
+// event's level; otherwise it returns the handler's error. Successes are
+// logged at Info, failures and denials at Warn.
+func (l *AuditLogger) Log(ctx context.Context, e AuditEvent) error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+
+	level := slog.LevelInfo
+	if e.Outcome != AuditSuccess {
+		level = slog.LevelWarn
+	}
+	if !l.h.Enabled(ctx, level) {
+		return fmt.Errorf("humanlog: audit handler drops %s records", level)
+	}
+	r := slog.NewRecord(time.Now(), level, "audit", 0)
+	r.AddAttrs(
+		slog.String("actor", e.Actor),
+		slog.String("action", e.Action),
+		slog.String("resource", e.Resource),
+		slog.String("outcome", e.Outcome.String()),
+	)
+	if len(e.Reasons) > 0 {
+		r.AddAttrs(slog.String("reasons", strings.Join(e.Reasons, "; ")))
+	}
+	r.AddAttrs(e.Attrs...)
+	return l.h.Handle(ctx, r)
+}
+
+// validate reports every problem that makes e unfit for the audit trail.
+func (e *AuditEvent) validate() error {
+	var errs []error
+	for _, f := range []struct{ name, value string }{
+		{"actor", e.Actor},
+		{"action", e.Action},
+		{"resource", e.Resource},
+	} {
+		switch {
+		case strings.TrimSpace(f.value) == "":
+			errs = append(errs, fmt.Errorf("humanlog: audit event has no %s", f.name))
+		case isRedacted(f.value):
+			errs = append(errs, fmt.Errorf("humanlog: audit event %s is redacted", f.name))
+		}
+	}
+	if e.Outcome < AuditSuccess || e.Outcome > AuditDenied {
+		errs = append(errs, fmt.Errorf("humanlog: audit event has unknown outcome %d", int(e.Outcome)))
+	} else if e.Outcome != AuditSuccess && len(e.Reasons) == 0 {
+		errs = append(errs, fmt.Errorf("humanlog: audit event with outcome %s needs a reason", e.Outcome))
+	}
+	for _, reason := range e.Reasons {
+		if isRedacted(reason) {
+			errs = append(errs, errors.New("humanlog: audit event reason is redacted"))
+			break
+		}
+	}
+	for _, a := range e.Attrs {
+		for _, key := range auditSchemaKeys {
+			if a.Key == key {
+				errs = append(errs, fmt.Errorf("humanlog: audit event attribute %q replaces a schema field", a.Key))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isRedacted reports whether s is a placeholder left by redaction, such as
+// "[REDACTED]", "<redacted>" or a run of asterisks.
+func isRedacted(s string) bool {
+	s = strings.Trim(strings.TrimSpace(s), "[]<>()")
+	if strings.EqualFold(s, "redacted") || strings.EqualFold(s, "filtered") {
+		return true
+	}
+	return len(s) >= 3 && strings.Trim(s, "*") == ""
+}
@@ -0,0 +1,68 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogger_Log(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{DisableColor: true, Deterministic: true})
+	audit := NewAuditLogger(h)
+
+	err := audit.Log(context.Background(), AuditEvent{
+		Actor:    "alice",
+		Action:   "user.delete",
+		Resource: "user/42",
+		Outcome:  AuditDenied,
+		Reasons:  []string{"not an admin", "user is protected"},
+		Attrs:    []slog.Attr{slog.String("request_id", "r1")},
+	})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{"WARN", "audit", "actor=alice", "action=user.delete", "resource=user/42", "outcome=denied", `reasons="not an admin; user is protected"`, "request_id=r1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %q", got, want)
+		}
+	}
+}
+
+func TestAuditLogger_Refuses(t *testing.T) {
+	valid := AuditEvent{Actor: "alice", Action: "login", Resource: "session", Outcome: AuditSuccess}
+	tests := []struct {
+		name  string
+		edit  func(e *AuditEvent)
+		level slog.Level // of the handler
+		want  string
+	}{
+		{"missing actor", func(e *AuditEvent) { e.Actor = " " }, 0, "no actor"},
+		{"redacted resource", func(e *AuditEvent) { e.Resource = "[REDACTED]" }, 0, "resource is redacted"},
+		{"masked actor", func(e *AuditEvent) { e.Actor = "****" }, 0, "actor is redacted"},
+		{"no outcome", func(e *AuditEvent) { e.Outcome = 0 }, 0, "unknown outcome"},
+		{"failure without reason", func(e *AuditEvent) { e.Outcome = AuditFailure }, 0, "needs a reason"},
+		{"redacted reason", func(e *AuditEvent) { e.Outcome, e.Reasons = AuditDenied, []string{"<redacted>"} }, 0, "reason is redacted"},
+		{"handler drops level", func(e *AuditEvent) { e.Outcome, e.Reasons = AuditFailure, []string{"timeout"} }, slog.LevelError, "drops WARN records"},
+		{"schema key attr", func(e *AuditEvent) { e.Attrs = []slog.Attr{slog.String("actor", "mallory")} }, 0, "replaces a schema field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := valid
+			tt.edit(&e)
+			h := NewHandler(&buf, &Options{Level: tt.level})
+			err := NewAuditLogger(h).Log(context.Background(), e)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Log() error = %v, want it to mention %q", err, tt.want)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("Log() wrote %q for an invalid event", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// JSONFields names the built-in fields of JSON log lines read by a
+// JSONBridge.
+type JSONFields struct {
+	Time    string
+	Level   string
+	Message string
+}
+
+// Field names of zerolog's and logrus's JSON output.
+var (
+	ZerologFields = JSONFields{Time: "time", Level: "level", Message: "message"}
+	LogrusFields  = JSONFields{Time: "time", Level: "level", Message: "msg"}
+)
+
+// JSONBridge is an io.Writer that parses JSON log lines written by another
+// logging library and re-logs them through a slog.Handler, so
+// applications mixing libraries get uniform humanlog output. Fields other
+// than the time, level and message become attributes in their original
+// order. Lines that are not JSON objects are logged as the message of an
+// Info record.
+type JSONBridge struct {
+	h      slog.Handler
+	fields JSONFields
+	lines  lineSplitter
+}
+
+// NewJSONBridge returns a JSONBridge reading lines with the given fields.
+func NewJSONBridge(h slog.Handler, fields JSONFields) *JSONBridge {
+	b := &JSONBridge{h: h, fields: fields}
+	b.lines.emit = b.logLine
+	return b
+}
+
+// NewZerologWriter returns a JSONBridge for zerolog, used in place of
+// zerolog.ConsoleWriter:
+//
+//	log := zerolog.New(humanlog.NewZerologWriter(handler))
+func NewZerologWriter(h slog.Handler) *JSONBridge {
+	return NewJSONBridge(h, ZerologFields)
+}
+
+// NewLogrusWriter returns a JSONBridge for logrus. humanlog has no logrus
+// dependency, so instead of a Hook the bridge reads logrus's JSON output:
+//
+//	logrus.SetFormatter(&logrus.JSONFormatter{})
+//	logrus.SetOutput(humanlog.NewLogrusWriter(handler))
+func NewLogrusWriter(h slog.Handler) *JSONBridge {
+	return NewJSONBridge(h, LogrusFields)
+}
+
+// Write logs every complete line in p and buffers the remainder. It never
+// returns an error.
+func (b *JSONBridge) Write(p []byte) (int, error) {
+	b.lines.write(p)
+	return len(p), nil
+}
+
+// Close logs any buffered partial line.
+func (b *JSONBridge) Close() error {
+	b.lines.flush()
+	return nil
+}
+
+func (b *JSONBridge) logLine(line []byte) {
+	r, ok := b.parse(line)
+	if !ok {
+		r = slog.NewRecord(time.Now(), slog.LevelInfo, string(line), 0)
+	}
+	ctx := context.Background()
+	if b.h.Enabled(ctx, r.Level) {
+		_ = b.h.Handle(ctx, r)
+	}
+}
+
+// parse converts a JSON object line to a record, keeping field order.
+func (b *JSONBridge) parse(line []byte) (slog.Record, bool) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return slog.Record{}, false
+	}
+
+	t, level, msg := time.Time{}, slog.LevelInfo, ""
+	var attrs []slog.Attr
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return slog.Record{}, false
+		}
+		key, _ := tok.(string)
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return slog.Record{}, false
+		}
+
+		switch key {
+		case b.fields.Time:
+			if parsed, ok := bridgeTime(v); ok {
+				t = parsed
+				continue
+			}
+		case b.fields.Level:
+			if s, ok := v.(string); ok {
+				if l, ok := bridgeLevel(s); ok {
+					level = l
+					continue
+				}
+			}
+		case b.fields.Message:
+			if s, ok := v.(string); ok {
+				msg = s
+				continue
+			}
+		}
+		attrs = append(attrs, bridgeAttr(key, v))
+	}
+	if _, err := dec.Token(); err != nil {
+		return slog.Record{}, false
+	}
+
+	if t.IsZero() {
+		t = time.Now()
+	}
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(attrs...)
+	return r, true
+}
+
+// bridgeTime parses an RFC 3339 time or a Unix time in seconds or, for
+// values too large to be seconds, milliseconds.
+func bridgeTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		if n > 1e12 {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
+
+// bridgeLevel maps a level name used by other logging libraries.
+func bridgeLevel(name string) (slog.Level, bool) {
+	name = strings.ToLower(name)
+	if name == "trace" {
+		return slog.LevelDebug - 4, true
+	}
+	level, ok := stdLevelNames[name]
+	return level, ok
+}
+
+// bridgeAttr converts a decoded JSON value to an attribute.
+func bridgeAttr(key string, v any) slog.Attr {
+	switch v := v.(type) {
+	case string:
+		return slog.String(key, v)
+	case bool:
+		return slog.Bool(key, v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return slog.Int64(key, n)
+		}
+		f, _ := v.Float64()
+		return slog.Float64(key, f)
+	default:
+		return slog.Any(key, v)
+	}
+}
@@ -0,0 +1,61 @@
+package humanlog
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/lepinkainen/humanlog/humanlogtest"
+)
+
+func TestZerologWriter(t *testing.T) {
+	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug - 4)
+	w := NewZerologWriter(capture)
+
+	_, _ = w.Write([]byte(`{"level":"warn","user":"ana","attempt":3,"ratio":0.5,"ok":false,"time":"2024-05-01T10:00:00Z","message":"Retrying"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"trace","time":1714557600,"message":"Deep"}` + "\nnot json\n"))
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+
+	e := entries[0]
+	if e.Level != slog.LevelWarn || e.Message != "Retrying" || !e.Time.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("entry 0 = %+v", e)
+	}
+	wantKeys := []string{"user", "attempt", "ratio", "ok"}
+	if len(e.Attrs) != len(wantKeys) {
+		t.Fatalf("entry 0 attrs = %v", e.Attrs)
+	}
+	for i, key := range wantKeys {
+		if e.Attrs[i].Key != key {
+			t.Errorf("attr %d key = %q, want %q", i, e.Attrs[i].Key, key)
+		}
+	}
+	if e.Attrs[1].Value.Kind() != slog.KindInt64 || e.Attrs[2].Value.Kind() != slog.KindFloat64 {
+		t.Errorf("numeric attrs = %v", e.Attrs)
+	}
+
+	if entries[1].Level != slog.LevelDebug-4 || entries[1].Time.Unix() != 1714557600 {
+		t.Errorf("entry 1 = %+v", entries[1])
+	}
+	if entries[2].Level != slog.LevelInfo || entries[2].Message != "not json" {
+		t.Errorf("entry 2 = %+v", entries[2])
+	}
+}
+
+func TestLogrusWriter(t *testing.T) {
+	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug)
+	w := NewLogrusWriter(capture)
+	_, _ = w.Write([]byte(`{"component":"db","level":"warning","msg":"Slow query","time":"2024-05-01T10:00:00+02:00"}`))
+	_ = w.Close()
+
+	entries := capture.Entries()
+	if len(entries) != 1 || entries[0].Level != slog.LevelWarn || entries[0].Message != "Slow query" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if v, _ := entries[0].AttrValue("component"); v.String() != "db" {
+		t.Errorf("component = %v", v)
+	}
+}
@@ -0,0 +1,47 @@
+package humanlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAppendDuration(t *testing.T) {
+	durations := []time.Duration{
+		0, 1, 999, time.Microsecond, 1500 * time.Nanosecond, time.Millisecond + 20*time.Microsecond,
+		time.Second, 90 * time.Second, 26*time.Hour + 3*time.Minute + 4*time.Second + 5,
+		-time.Minute, math.MaxInt64, math.MinInt64,
+	}
+	for _, d := range durations {
+		if got := string(appendDuration(nil, d)); got != d.String() {
+			t.Errorf("appendDuration(%d) = %q, want %q", int64(d), got, d.String())
+		}
+	}
+}
+
+func TestHandler_HandleAllocs(t *testing.T) {
+	h := NewHandler(io.Discard, &Options{TimeFormat: TimeFormatMillis})
+	hh := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).WithGroup("req")
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "Served request", 0)
+	r.AddAttrs(
+		slog.String("method", "GET"),
+		slog.Int("status", 200),
+		slog.Duration("latency", 1500*time.Microsecond),
+		slog.Bool("cached", false),
+		slog.Float64("ratio", 0.5),
+		slog.String("path", "/api/v1/users"),
+	)
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := hh.Handle(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("Handle allocated %.0f times per record, want 0", allocs)
+	}
+}
@@ -0,0 +1,52 @@
+package humanlog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// defaultMaxBytes is the number of bytes rendered from a []byte attribute
+// when Options.MaxBytes is not set.
+const defaultMaxBytes = 64
+
+// BytesFormat controls how []byte attribute values are rendered.
+type BytesFormat int
+
+const (
+	// BytesHex renders byte slices as lowercase hexadecimal. This is the default.
+	BytesHex BytesFormat = iota
+	// BytesBase64 renders byte slices as standard base64.
+	BytesBase64
+	// BytesSummary renders only the length, e.g. "<128 bytes>".
+	BytesSummary
+)
+
+// formatBytes renders b according to opts, truncating it to the configured
+// size limit so binary payloads never flood the terminal.
+func formatBytes(b []byte, opts *Options) string {
+	if opts.BytesFormat == BytesSummary {
+		return fmt.Sprintf("<%d bytes>", len(b))
+	}
+
+	limit := opts.MaxBytes
+	if limit <= 0 {
+		limit = defaultMaxBytes
+	}
+	shown := b
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var s string
+	if opts.BytesFormat == BytesBase64 {
+		s = base64.StdEncoding.EncodeToString(shown)
+	} else {
+		s = hex.EncodeToString(shown)
+	}
+
+	if len(shown) < len(b) {
+		return fmt.Sprintf("%s...<%d bytes>", s, len(b))
+	}
+	return s
+}
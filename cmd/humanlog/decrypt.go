@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+// runDecrypt executes "humanlog decrypt", which writes the plaintext of
+// logs written through a humanlog.EncryptedWriter. The key is read from
+// the --key-file file or the HUMANLOG_KEY environment variable, as hex or
+// base64. Pipe the output into humanlog to pretty-print JSON logs.
+func runDecrypt(_ context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("humanlog decrypt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	keyFile := fs.String("key-file", "", "read the encryption key from `file`; default: $HUMANLOG_KEY")
+	if err := fs.Parse(interleaved(fs, args)); err != nil {
+		return 2
+	}
+
+	encoded := os.Getenv("HUMANLOG_KEY")
+	if *keyFile != "" {
+		b, err := os.ReadFile(*keyFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "humanlog:", err)
+			return 2
+		}
+		encoded = string(b)
+	}
+	if encoded == "" {
+		fmt.Fprintln(stderr, "humanlog: decrypt requires --key-file or HUMANLOG_KEY")
+		return 2
+	}
+	key, err := humanlog.ParseEncryptionKey(encoded)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		if err := humanlog.DecryptLogs(stdout, stdin, key); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+	for _, path := range fs.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(stderr, "humanlog:", err)
+			return 1
+		}
+		err = humanlog.DecryptLogs(stdout, f, key)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return 1
+		}
+	}
+	return 0
+}
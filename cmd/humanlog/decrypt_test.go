@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+func TestRunDecrypt(t *testing.T) {
+	key := humanlog.GenerateEncryptionKey()
+	keyFile := filepath.Join(t.TempDir(), "log.key")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var sealed bytes.Buffer
+	w, err := humanlog.NewEncryptedWriter(&sealed, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedWriter() error = %v", err)
+	}
+	_, _ = w.Write([]byte("first\n"))
+	_, _ = w.Write([]byte("second\n"))
+
+	var out, errOut strings.Builder
+	if code := run(context.Background(), []string{"decrypt", "--key-file", keyFile}, &sealed, &out, &errOut); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Errorf("output = %q, want the plaintext", got)
+	}
+
+	errOut.Reset()
+	if code := run(context.Background(), []string{"decrypt"}, strings.NewReader(""), &out, &errOut); code != 2 {
+		t.Errorf("run() without a key = %d, want 2", code)
+	}
+}
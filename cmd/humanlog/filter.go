@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// filter selects the records and lines that are printed.
+type filter struct {
+	level slog.Level
+	match *regexp.Regexp
+	where []condition
+}
+
+// keep reports whether the record is printed.
+func (f *filter) keep(r slog.Record) bool {
+	if r.Level < f.level {
+		return false
+	}
+	if f.match != nil && !f.match.MatchString(r.Message) {
+		return false
+	}
+	for _, c := range f.where {
+		if !c.holds(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// keepLine reports whether a line that is not a structured record is
+// printed. Such lines have no level or attributes, so they are dropped by
+// --where and matched against their full text by --match.
+func (f *filter) keepLine(line string) bool {
+	if len(f.where) > 0 {
+		return false
+	}
+	return f.match == nil || f.match.MatchString(line)
+}
+
+// condition is a --where comparison of an attribute with a value.
+type condition struct {
+	key, op, value string
+}
+
+// conditionOps are the --where operators, longest first so "<=" is not
+// read as "<".
+var conditionOps = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// parseCondition parses an expression such as "status>=500".
+func parseCondition(expr string) (condition, error) {
+	for _, op := range conditionOps {
+		if i := strings.Index(expr, op); i > 0 {
+			return condition{key: strings.TrimSpace(expr[:i]), op: op, value: strings.TrimSpace(expr[i+len(op):])}, nil
+		}
+	}
+	return condition{}, fmt.Errorf("invalid --where %q, want key<op>value with one of %s", expr, strings.Join(conditionOps, " "))
+}
+
+// holds reports whether the record has the attribute and it compares as
+// required. Values are compared as numbers when both sides are numeric
+// and as strings otherwise.
+func (c condition) holds(r slog.Record) bool {
+	var found bool
+	var actual string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == c.key {
+			found, actual = true, a.Value.String()
+			return false
+		}
+		return true
+	})
+	if !found {
+		return c.op == "!="
+	}
+
+	cmp := strings.Compare(actual, c.value)
+	if x, err := strconv.ParseFloat(actual, 64); err == nil {
+		if y, err := strconv.ParseFloat(c.value, 64); err == nil {
+			switch {
+			case x < y:
+				cmp = -1
+			case x > y:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
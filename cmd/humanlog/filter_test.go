@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	rec := func(level slog.Level, msg string, attrs ...slog.Attr) slog.Record {
+		r := slog.NewRecord(time.Time{}, level, msg, 0)
+		r.AddAttrs(attrs...)
+		return r
+	}
+	cond := func(expr string) condition {
+		c, err := parseCondition(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	f := &filter{
+		level: slog.LevelWarn,
+		match: regexp.MustCompile(`^Request`),
+		where: []condition{cond("status>=500"), cond("method!=HEAD")},
+	}
+	tests := []struct {
+		name string
+		r    slog.Record
+		want bool
+	}{
+		{"match", rec(slog.LevelError, "Request failed", slog.Int("status", 503), slog.String("method", "GET")), true},
+		{"below level", rec(slog.LevelInfo, "Request failed", slog.Int("status", 503)), false},
+		{"message mismatch", rec(slog.LevelError, "Query failed", slog.Int("status", 503)), false},
+		{"numeric compare", rec(slog.LevelError, "Request failed", slog.Int("status", 80)), false},
+		{"excluded value", rec(slog.LevelError, "Request failed", slog.Int("status", 500), slog.String("method", "HEAD")), false},
+		{"missing attribute", rec(slog.LevelError, "Request failed"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.keep(tt.r); got != tt.want {
+				t.Errorf("keep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if f.keepLine("Request plain") {
+		t.Error("keepLine() kept a plain line despite --where")
+	}
+	if !(&filter{match: regexp.MustCompile("panic")}).keepLine("goroutine panic:") {
+		t.Error("keepLine() dropped a matching plain line")
+	}
+	if _, err := parseCondition("status"); err == nil {
+		t.Error("parseCondition() accepted an expression without operator")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// defaultPollInterval is how often followed files are checked for new data.
+const defaultPollInterval = 250 * time.Millisecond
+
+// follow sends every line appended to the file at path to lines until ctx
+// is done, starting at the current end of the file, or at its beginning if
+// fromStart is set. It detects rotation, where path is replaced by a new
+// file, and truncation, and continues with the new content. A file that
+// does not exist yet is waited for.
+func follow(ctx context.Context, path string, fromStart bool, interval time.Duration, lines chan<- []byte) error {
+	var f *os.File
+	var partial []byte
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	// Files appearing later are read in full
+	open := func(atEnd bool) error {
+		nf, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		if atEnd {
+			if _, err := nf.Seek(0, io.SeekEnd); err != nil {
+				nf.Close()
+				return err
+			}
+		}
+		f = nf
+		partial = nil
+		return nil
+	}
+	if err := open(!fromStart); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	buf := make([]byte, 32*1024)
+	drain := func() error {
+		for {
+			n, err := f.Read(buf)
+			partial = append(partial, buf[:n]...)
+			for {
+				i := bytes.IndexByte(partial, '\n')
+				if i < 0 {
+					break
+				}
+				select {
+				case lines <- bytes.Clone(partial[:i]):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				partial = partial[i+1:]
+			}
+			if err == io.EOF || (err == nil && n == 0) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if f != nil {
+			if err := drain(); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if f == nil {
+			if err := open(false); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+
+		cur, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		next, err := os.Stat(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// Rotated away and not recreated yet
+		case err != nil:
+			return err
+		case !os.SameFile(cur, next):
+			// Finish the rotated file before switching to the new one
+			if err := drain(); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			f.Close()
+			f = nil
+			if err := open(false); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		default:
+			if offset, err := f.Seek(0, io.SeekCurrent); err == nil && next.Size() < offset {
+				// Truncated in place
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				partial = nil
+			}
+		}
+	}
+}
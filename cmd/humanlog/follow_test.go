@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan []byte)
+	done := make(chan error, 1)
+	go func() { done <- follow(ctx, path, false, 5*time.Millisecond, lines) }()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return string(line)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a line")
+			return ""
+		}
+	}
+	appendTo := func(name, data string) {
+		t.Helper()
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Let follow open the file at its end before appending
+	time.Sleep(20 * time.Millisecond)
+	appendTo(path, "first\nsec")
+	if got := next(); got != "first" {
+		t.Errorf("line = %q, want first", got)
+	}
+	appendTo(path, "ond\n")
+	if got := next(); got != "second" {
+		t.Errorf("line = %q, want second", got)
+	}
+
+	// Rotate: the old file is renamed and a new one created
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(path+".1", "late\n")
+	appendTo(path, "rotated\n")
+	if got := next(); got != "late" {
+		t.Errorf("line = %q, want late", got)
+	}
+	if got := next(); got != "rotated" {
+		t.Errorf("line = %q, want rotated", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("follow() = %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Command humanlog pretty-prints structured logs.
+//
+// It reads newline-delimited JSON or logfmt records from standard input,
+// the named files, commands or Kubernetes pods and renders them with the
+// humanlog handler. Lines that are neither are printed unchanged. Several
+// inputs are merged by timestamp and each line is prefixed with a colored
+// label naming its source.
+//
+// The stats subcommand summarizes a log stream instead of printing it, and
+// the view subcommand browses it interactively in the terminal. The decrypt
+// subcommand restores logs written through a humanlog.EncryptedWriter and
+// the verify subcommand checks audit logs written through a
+// humanlog.AuditWriter.
+//
+// Usage:
+//
+//	kubectl logs deploy/api | humanlog --level warn
+//	humanlog --time-format millis < app.log
+//	humanlog -f app.log worker.log --level warn --match 'timeout' --where 'status>=500'
+//	humanlog --highlight 'req-[0-9a-f]+' < app.log
+//	humanlog --pod prod/api-0 --pod prod/api-1 --cmd 'ssh db journalctl -f -o cat'
+//	humanlog stats app.log
+//	humanlog view app.log
+//	humanlog decrypt --key-file log.key app.log.enc | humanlog
+//	humanlog verify --public-key audit.pub audit.log
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+// maxLineSize is the longest input line passed on; longer lines are
+// truncated.
+const maxLineSize = 1024 * 1024
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command and returns its exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "stats":
+			return runStats(ctx, args[1:], stdin, stdout, stderr)
+		case "view":
+			return runView(ctx, args[1:], stdin, stdout, stderr)
+		case "decrypt":
+			return runDecrypt(ctx, args[1:], stdin, stdout, stderr)
+		case "verify":
+			return runVerify(ctx, args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fs := flag.NewFlagSet("humanlog", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	level := humanlog.LevelFlag(slog.LevelDebug)
+	fs.Var(&level, "level", "minimum `level` shown: trace, debug, info, warn or error")
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colored output")
+	width := fs.Int("width", 40, "message column `width`")
+	timeFormat := fs.String("time-format", "seconds", "time `format`: seconds, millis, micros, relative or a Go layout")
+	followFiles := fs.Bool("f", false, "follow the named files as they grow, like tail -F")
+	match := fs.String("match", "", "show only records whose message matches the `regexp`")
+	var where, highlights, commands, pods stringList
+	fs.Var(&highlights, "highlight", "highlight matches of the `regexp` anywhere in the output; repeatable")
+	fs.Var(&where, "where", "show only records whose attribute matches the `condition`, e.g. status>=500; repeatable")
+	fs.Var(&commands, "cmd", "read the output of the shell `command`; repeatable")
+	fs.Var(&pods, "pod", "follow the logs of the Kubernetes `pod`, as pod or namespace/pod; repeatable")
+	window := fs.Duration("merge-window", defaultMergeWindow, "how long to wait for slower inputs when merging by timestamp")
+	if err := fs.Parse(interleaved(fs, args)); err != nil {
+		return 2
+	}
+
+	f := &filter{level: level.Level()}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			fmt.Fprintln(stderr, "humanlog: invalid --match:", err)
+			return 2
+		}
+		f.match = re
+	}
+	for _, expr := range where {
+		c, err := parseCondition(expr)
+		if err != nil {
+			fmt.Fprintln(stderr, "humanlog:", err)
+			return 2
+		}
+		f.where = append(f.where, c)
+	}
+	var patterns []humanlog.HighlightPattern
+	for _, expr := range highlights {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			fmt.Fprintln(stderr, "humanlog: invalid --highlight:", err)
+			return 2
+		}
+		patterns = append(patterns, humanlog.HighlightPattern{Regexp: re, Style: humanlog.StyleBold + humanlog.StyleRed})
+	}
+	if *followFiles && fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "humanlog: -f requires at least one file")
+		return 2
+	}
+
+	opts := &humanlog.Options{
+		Level:        level.Level(),
+		TimeFormat:   timeLayout(*timeFormat),
+		DisableColor: *noColor,
+		MessageWidth: *width,
+
+		HighlightPatterns: patterns,
+	}
+	p := &printer{w: stdout, h: humanlog.NewHandler(stdout, opts), filter: f}
+
+	var sources []source
+	for _, path := range fs.Args() {
+		if path == "-" {
+			sources = append(sources, readerSource("stdin", stdin))
+			continue
+		}
+		sources = append(sources, fileSource(path, *followFiles))
+	}
+	for _, line := range commands {
+		sources = append(sources, commandSource(line))
+	}
+	for _, pod := range pods {
+		sources = append(sources, podSource(pod))
+	}
+	if len(sources) == 0 {
+		sources = append(sources, readerSource("stdin", stdin))
+	}
+	var prefixes []string
+	if len(sources) > 1 {
+		prefixes = labels(sources, *noColor)
+	}
+
+	if err := merge(ctx, sources, prefixes, *window, p); err != nil {
+		fmt.Fprintln(stderr, "humanlog:", err)
+		return 1
+	}
+	return 0
+}
+
+// interleaved moves the flags in args before the file names, so flags may
+// follow the files as in "humanlog -f app.log --level warn".
+func interleaved(fs *flag.FlagSet, args []string) []string {
+	var flags, files []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			files = append(files, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			files = append(files, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		// Non-boolean flags take the next argument as their value
+		if fl := fs.Lookup(name); fl != nil && !isBoolFlag(fl) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(append(flags, "--"), files...)
+}
+
+// isBoolFlag reports whether fl is set without a value.
+func isBoolFlag(fl *flag.Flag) bool {
+	b, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// timeLayout resolves a --time-format value.
+func timeLayout(name string) string {
+	switch name {
+	case "seconds":
+		return humanlog.TimeFormatSeconds
+	case "millis":
+		return humanlog.TimeFormatMillis
+	case "micros":
+		return humanlog.TimeFormatMicros
+	case "relative":
+		return humanlog.TimeFormatRelative
+	default:
+		return name
+	}
+}
+
+// printer formats lines that pass its filter through h, copying lines that
+// are not structured records to w unchanged.
+type printer struct {
+	w      io.Writer
+	h      slog.Handler
+	filter *filter
+}
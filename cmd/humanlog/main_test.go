@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		ok    bool
+		level slog.Level
+		msg   string
+		attrs string
+	}{
+		{
+			name:  "slog JSON",
+			line:  `{"time":"2024-05-01T10:00:00Z","level":"WARN","msg":"Slow","req":{"method":"GET","n":2},"tags":["a", "b"]}`,
+			ok:    true,
+			level: slog.LevelWarn,
+			msg:   "Slow",
+			attrs: "req.method=GET req.n=2 tags=[\"a\",\"b\"]",
+		},
+		{
+			name:  "logfmt",
+			line:  `time=2024-05-01T10:00:00Z level=error msg="Query failed" table=users ms=12.5 cached=false`,
+			ok:    true,
+			level: slog.LevelError,
+			msg:   "Query failed",
+			attrs: "table=users ms=12.5 cached=false",
+		},
+		{
+			name:  "zap",
+			line:  `{"level":"warn","ts":1714557600.5,"caller":"server/http.go:42","msg":"Slow","ms":900}`,
+			ok:    true,
+			level: slog.LevelWarn,
+			msg:   "Slow",
+			attrs: "ms=900 source=server/http.go:42",
+		},
+		{
+			name:  "slog source",
+			line:  `{"time":"2024-05-01T10:00:00Z","level":"INFO","source":{"function":"main.main","file":"/src/app/main.go","line":7},"msg":"Up"}`,
+			ok:    true,
+			level: slog.LevelInfo,
+			msg:   "Up",
+			attrs: "source=main.go:7",
+		},
+		{
+			name:  "google cloud",
+			line:  `{"severity":"ERROR","message":"Boom","timestamp":"2024-05-01T10:00:00.123Z"}`,
+			ok:    true,
+			level: slog.LevelError,
+			msg:   "Boom",
+		},
+		{
+			name:  "pino",
+			line:  `{"level":50,"time":1714557600123,"msg":"Crash","pid":1}`,
+			ok:    true,
+			level: slog.LevelError,
+			msg:   "Crash",
+			attrs: "pid=1",
+		},
+		{
+			name:  "log15 logfmt",
+			line:  `t=2024-05-01T10:00:00Z lvl=warning msg=Tick n=1`,
+			ok:    true,
+			level: slog.LevelWarn,
+			msg:   "Tick",
+			attrs: "n=1",
+		},
+		{name: "prose", line: "Starting server on port=8080", ok: false},
+		{name: "broken JSON", line: `{"msg":`, ok: false},
+		{name: "empty", line: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := parseLine([]byte(tt.line))
+			if ok != tt.ok {
+				t.Fatalf("parseLine() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			var attrs []string
+			r.Attrs(func(a slog.Attr) bool {
+				attrs = append(attrs, a.String())
+				return true
+			})
+			if r.Level != tt.level || r.Message != tt.msg || strings.Join(attrs, " ") != tt.attrs {
+				t.Errorf("parseLine() = %v %q %q", r.Level, r.Message, strings.Join(attrs, " "))
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, v := range []slog.Value{
+		slog.StringValue("2024-05-01T10:00:00Z"),
+		slog.Int64Value(want.Unix()),
+		slog.Int64Value(want.UnixMilli()),
+		slog.Int64Value(want.UnixMicro()),
+		slog.Int64Value(want.UnixNano()),
+		slog.Float64Value(float64(want.Unix())),
+	} {
+		got, ok := parseTime(v)
+		if !ok || !got.Equal(want) {
+			t.Errorf("parseTime(%v) = %v, %v", v, got, ok)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	in := strings.Join([]string{
+		`{"time":"2024-05-01T10:00:00Z","level":"DEBUG","msg":"Hidden"}`,
+		`{"time":"2024-05-01T10:00:01Z","level":"WARN","msg":"Shown","user":"ana"}`,
+		`plain text line`,
+	}, "\n")
+
+	var out, errOut bytes.Buffer
+	code := run(context.Background(), []string{"--level", "info", "--no-color", "--width", "10", "--time-format", "millis"}, strings.NewReader(in), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+
+	got := out.String()
+	if strings.Contains(got, "Hidden") {
+		t.Errorf("debug record shown with --level info: %q", got)
+	}
+	if !strings.Contains(got, "WARN  Shown      user=ana") || !strings.Contains(got, ".000]") {
+		t.Errorf("formatted record missing, got %q", got)
+	}
+	if !strings.Contains(got, "plain text line\n") {
+		t.Errorf("plain line not passed through, got %q", got)
+	}
+
+	if code := run(context.Background(), []string{"--level", "loud"}, strings.NewReader(""), &out, &errOut); code != 2 {
+		t.Errorf("run() with bad level = %d, want 2", code)
+	}
+}
+
+func TestRunFollow(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out, errOut syncBuffer
+	done := make(chan int, 1)
+	go func() {
+		done <- run(ctx, []string{"-f", a, b, "--no-color", "--level", "warn", "--where", "status>=500"}, strings.NewReader(""), &out, &errOut)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	for path, line := range map[string]string{
+		a: `{"level":"ERROR","msg":"Failed","status":503}` + "\n" + `{"level":"ERROR","msg":"Client","status":404}` + "\n",
+		b: `{"level":"INFO","msg":"Quiet","status":500}` + "\n" + `{"level":"WARN","msg":"Slow","status":504}` + "\n",
+	} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(line)
+		f.Close()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && !(strings.Contains(out.String(), "Failed") && strings.Contains(out.String(), "Slow")) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if code := <-done; code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "Failed") || !strings.Contains(got, "Slow") {
+		t.Errorf("matching records missing, got %q", got)
+	}
+	if strings.Contains(got, "Client") || strings.Contains(got, "Quiet") {
+		t.Errorf("filtered records shown, got %q", got)
+	}
+
+	if code := run(context.Background(), []string{"-f"}, strings.NewReader(""), &out, &errOut); code != 2 {
+		t.Errorf("run() -f without files = %d, want 2", code)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
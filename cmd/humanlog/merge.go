@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultMergeWindow is how long a line waits for slower sources before it
+// is printed out of timestamp order.
+const defaultMergeWindow = 100 * time.Millisecond
+
+// labelColors are the colors given to source labels in turn.
+var labelColors = []string{
+	"\033[36m", "\033[35m", "\033[32m", "\033[33m", "\033[34m",
+	"\033[96m", "\033[95m", "\033[92m", "\033[93m", "\033[94m",
+}
+
+// maxLabelWidth is the widest source label column.
+const maxLabelWidth = 24
+
+// labels returns the line prefix of each source: its name padded to a
+// common width, colored unless disabled, followed by a separator.
+func labels(sources []source, disableColor bool) []string {
+	width := 0
+	for _, s := range sources {
+		width = max(width, min(len(s.name), maxLabelWidth))
+	}
+	prefixes := make([]string, len(sources))
+	for i, s := range sources {
+		name := s.name
+		if len(name) > width {
+			name = name[:width-1] + "…"
+		}
+		name = fmt.Sprintf("%-*s |", width, name)
+		if !disableColor {
+			name = labelColors[i%len(labelColors)] + name + "\033[0m"
+		}
+		prefixes[i] = name + " "
+	}
+	return prefixes
+}
+
+// mergedLine is a line read from one of the merged sources.
+type mergedLine struct {
+	src  int
+	line []byte
+	err  error
+	done bool
+}
+
+// entry is a parsed line waiting to be printed.
+type entry struct {
+	line []byte
+	rec  slog.Record
+	ok   bool
+	time time.Time
+}
+
+// merge reads all sources concurrently and prints their lines in
+// timestamp order, prefixed by the source label when prefixes is not nil.
+// A line waits at most window for sources that have nothing pending, so
+// live streams are merged in near real time. Lines without a timestamp
+// keep the time of the source's previous line.
+func merge(ctx context.Context, sources []source, prefixes []string, window time.Duration, p *printer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	in := make(chan mergedLine)
+	var wg sync.WaitGroup
+	for i, s := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lines := make(chan []byte)
+			errc := make(chan error, 1)
+			go func() {
+				errc <- s.run(ctx, lines)
+				close(lines)
+			}()
+			for line := range lines {
+				select {
+				case in <- mergedLine{src: i, line: line}:
+				case <-ctx.Done():
+				}
+			}
+			select {
+			case in <- mergedLine{src: i, err: <-errc, done: true}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	queues := make([][]entry, len(sources))
+	last := make([]time.Time, len(sources))
+	closed := make([]bool, len(sources))
+	timer := time.NewTimer(window)
+	timer.Stop()
+	defer timer.Stop()
+	waiting := false
+	var err error
+
+	// pending reports whether any line is queued and whether every source
+	// that is still open has one, so the earliest can be printed.
+	pending := func() (queued, all bool) {
+		all = true
+		for i, q := range queues {
+			queued = queued || len(q) > 0
+			all = all && (len(q) > 0 || closed[i])
+		}
+		return queued, all
+	}
+	// emit prints the earliest queued line
+	emit := func() {
+		first := -1
+		for i, q := range queues {
+			if len(q) > 0 && (first < 0 || q[0].time.Before(queues[first][0].time)) {
+				first = i
+			}
+		}
+		e := queues[first][0]
+		queues[first] = queues[first][1:]
+		prefix := ""
+		if prefixes != nil {
+			prefix = prefixes[first]
+		}
+		if err = p.print(ctx, prefix, e); err != nil {
+			cancel()
+		}
+	}
+
+	for in != nil {
+		queued, all := pending()
+		if err == nil && queued && all {
+			emit()
+			continue
+		}
+		switch {
+		case queued && !waiting:
+			timer.Reset(window)
+			waiting = true
+		case !queued && waiting:
+			timer.Stop()
+			waiting = false
+		}
+
+		select {
+		case m, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			if m.done {
+				closed[m.src] = true
+				if m.err != nil && err == nil {
+					err = fmt.Errorf("%s: %w", sources[m.src].name, m.err)
+					cancel()
+				}
+				continue
+			}
+			rec, ok := parseLine(m.line)
+			if ok && !rec.Time.IsZero() {
+				last[m.src] = rec.Time
+			}
+			queues[m.src] = append(queues[m.src], entry{line: m.line, rec: rec, ok: ok, time: last[m.src]})
+		case <-timer.C:
+			// Waited long enough for the slower sources
+			waiting = false
+			for queued, _ := pending(); err == nil && queued; queued, _ = pending() {
+				emit()
+			}
+		}
+	}
+	for queued, _ := pending(); err == nil && queued; queued, _ = pending() {
+		emit()
+	}
+	return err
+}
+
+// print prints a parsed line that passes the filter, writing prefix first.
+func (p *printer) print(ctx context.Context, prefix string, e entry) error {
+	if !e.ok {
+		if !p.filter.keepLine(string(e.line)) {
+			return nil
+		}
+		_, err := fmt.Fprintf(p.w, "%s%s\n", prefix, e.line)
+		return err
+	}
+	if !p.filter.keep(e.rec) || !p.h.Enabled(ctx, e.rec.Level) {
+		return nil
+	}
+	if prefix != "" {
+		if _, err := io.WriteString(p.w, prefix); err != nil {
+			return err
+		}
+	}
+	return p.h.Handle(ctx, e.rec)
+}
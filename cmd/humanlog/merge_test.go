@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunMerge(t *testing.T) {
+	dir := t.TempDir()
+	api, worker := filepath.Join(dir, "api.log"), filepath.Join(dir, "worker.log")
+	files := map[string]string{
+		api: `{"time":"2024-05-01T10:00:00Z","level":"INFO","msg":"One"}` + "\n" +
+			`{"time":"2024-05-01T10:00:03Z","level":"INFO","msg":"Four"}` + "\n",
+		worker: `{"time":"2024-05-01T10:00:01Z","level":"INFO","msg":"Two"}` + "\n" +
+			"continued\n" +
+			`{"time":"2024-05-01T10:00:02Z","level":"INFO","msg":"Three"}` + "\n",
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out, errOut syncBuffer
+	code := run(context.Background(), []string{"--no-color", "--width", "5", api, worker, "--cmd", "echo level=info msg=Five time=2024-05-01T10:00:04Z"}, strings.NewReader(""), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		label, rest, _ := strings.Cut(line, " | ")
+		fields := strings.Fields(rest)
+		got = append(got, strings.TrimSpace(label)+":"+fields[len(fields)-1])
+	}
+	want := []string{
+		"api.log:One",
+		"worker.log:Two",
+		"worker.log:continued",
+		"worker.log:Three",
+		"api.log:Four",
+		"echo level=info msg=Fiv…:Five",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("merged output:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLabels(t *testing.T) {
+	sources := []source{{name: "api"}, {name: "worker"}}
+	plain := labels(sources, true)
+	if plain[0] != "api    | " || plain[1] != "worker | " {
+		t.Errorf("labels() = %q", plain)
+	}
+	colored := labels(sources, false)
+	if !strings.HasPrefix(colored[0], labelColors[0]) || !strings.HasPrefix(colored[1], labelColors[1]) {
+		t.Errorf("labels() colors = %q", colored)
+	}
+}
+
+func TestPodSource(t *testing.T) {
+	if s := podSource("prod/api-0"); s.name != "api-0" {
+		t.Errorf("podSource() name = %q, want api-0", s.name)
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+// parseLine parses a JSON object or logfmt line into a record. Records
+// without a time get the zero time, which the handler replaces with the
+// current time.
+func parseLine(line []byte) (slog.Record, bool) {
+	line = bytes.TrimSpace(line)
+	var fields []slog.Attr
+	var ok bool
+	if len(line) > 0 && line[0] == '{' {
+		fields, ok = parseJSON(line, "")
+	} else {
+		fields, ok = parseLogfmt(string(line))
+	}
+	if !ok || len(fields) == 0 {
+		return slog.Record{}, false
+	}
+	return newRecord(fields), true
+}
+
+// Field names recognised for the built-in fields, in order of preference.
+// They cover slog, zap, zerolog, logrus, bunyan/pino, Google Cloud and
+// Elastic layouts.
+var (
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t"}
+	levelKeys   = []string{"level", "lvl", "severity", "@level", "loglevel"}
+	messageKeys = []string{"msg", "message", "@message", "@m"}
+	callerKeys  = []string{"caller", "source", "logger.caller"}
+)
+
+// Flattened keys of structured source locations, e.g. slog's
+// {"source":{"file":...,"line":...}}.
+var sourceLocations = [][2]string{
+	{"source.file", "source.line"},
+	{"logging.googleapis.com/sourceLocation.file", "logging.googleapis.com/sourceLocation.line"},
+	{"log.origin.file.name", "log.origin.file.line"},
+}
+
+// newRecord builds a record, detecting the time, level and message fields
+// and rendering the caller as a trailing "source=file:line" attribute. The
+// other fields are kept as attributes.
+func newRecord(fields []slog.Attr) slog.Record {
+	used := make([]bool, len(fields))
+	find := func(keys []string, parse func(slog.Value) bool) {
+		for _, key := range keys {
+			for i, f := range fields {
+				if !used[i] && f.Key == key && parse(f.Value) {
+					used[i] = true
+					return
+				}
+			}
+		}
+	}
+
+	var t time.Time
+	level, msg, source := slog.LevelInfo, "", ""
+	find(timeKeys, func(v slog.Value) (ok bool) {
+		t, ok = parseTime(v)
+		return ok
+	})
+	find(levelKeys, func(v slog.Value) (ok bool) {
+		level, ok = parseLevelValue(v)
+		return ok
+	})
+	find(messageKeys, func(v slog.Value) bool {
+		msg = v.String()
+		return v.Kind() == slog.KindString
+	})
+	find(callerKeys, func(v slog.Value) bool {
+		source = v.String()
+		return v.Kind() == slog.KindString && source != ""
+	})
+	if source == "" {
+		source = structuredSource(fields, used)
+	}
+
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	for i, f := range fields {
+		if !used[i] {
+			attrs = append(attrs, f)
+		}
+	}
+	if source != "" {
+		attrs = append(attrs, slog.String(slog.SourceKey, source))
+	}
+
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(attrs...)
+	return r
+}
+
+// structuredSource combines a flattened file and line pair into
+// "file:line", using the file's base name like the handler does.
+func structuredSource(fields []slog.Attr, used []bool) string {
+	for _, loc := range sourceLocations {
+		fileIdx, lineIdx := -1, -1
+		for i, f := range fields {
+			switch f.Key {
+			case loc[0]:
+				fileIdx = i
+			case loc[1]:
+				lineIdx = i
+			}
+		}
+		if fileIdx < 0 {
+			continue
+		}
+
+		// Drop the rest of the location, such as the function name
+		prefix := loc[0][:strings.LastIndexByte(loc[0], '.')+1]
+		for i, f := range fields {
+			if strings.HasPrefix(f.Key, prefix) {
+				used[i] = true
+			}
+		}
+		source := path.Base(fields[fileIdx].Value.String())
+		if lineIdx >= 0 {
+			source += ":" + fields[lineIdx].Value.String()
+		}
+		return source
+	}
+	return ""
+}
+
+// timeLayouts are the string timestamp layouts recognised besides
+// RFC 3339.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// parseTime parses a string timestamp or a numeric Unix timestamp, whose
+// unit of seconds, milliseconds, microseconds or nanoseconds is inferred
+// from its magnitude.
+func parseTime(v slog.Value) (time.Time, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v.String()); err == nil {
+				return t, true
+			}
+		}
+	case slog.KindInt64:
+		return unixTime(float64(v.Int64())), true
+	case slog.KindFloat64:
+		return unixTime(v.Float64()), true
+	}
+	return time.Time{}, false
+}
+
+// unixTime converts a Unix timestamp of inferred unit.
+func unixTime(n float64) time.Time {
+	switch {
+	case n < 1e11:
+		sec := math.Floor(n)
+		return time.Unix(int64(sec), int64((n-sec)*1e9))
+	case n < 1e14:
+		return time.UnixMicro(int64(n * 1e3))
+	case n < 1e17:
+		return time.UnixMicro(int64(n))
+	default:
+		return time.Unix(0, int64(n))
+	}
+}
+
+// levelAliases maps level names slog does not parse itself.
+var levelAliases = map[string]slog.Level{
+	"warning":   slog.LevelWarn,
+	"err":       slog.LevelError,
+	"notice":    slog.LevelInfo + 2,
+	"fatal":     slog.LevelError + 4,
+	"critical":  slog.LevelError + 4,
+	"dpanic":    slog.LevelError + 4,
+	"alert":     slog.LevelError + 8,
+	"emergency": slog.LevelError + 8,
+	"panic":     slog.LevelError + 8,
+}
+
+// parseLevelValue parses a level name or a bunyan/pino numeric level.
+func parseLevelValue(v slog.Value) (slog.Level, bool) {
+	if v.Kind() == slog.KindInt64 {
+		switch n := v.Int64(); {
+		case n >= 60:
+			return slog.LevelError + 4, true
+		case n >= 50:
+			return slog.LevelError, true
+		case n >= 40:
+			return slog.LevelWarn, true
+		case n >= 30:
+			return slog.LevelInfo, true
+		case n >= 20:
+			return slog.LevelDebug, true
+		case n >= 10:
+			return humanlog.LevelTrace, true
+		}
+		return 0, false
+	}
+	return parseLevel(v.String())
+}
+
+// parseLevel parses slog level names such as "INFO" or "WARN+2" and the
+// aliases used by other loggers.
+func parseLevel(name string) (slog.Level, bool) {
+	if level, ok := levelAliases[strings.ToLower(name)]; ok {
+		return level, true
+	}
+	var level humanlog.LevelFlag
+	if err := level.Set(name); err != nil {
+		return 0, false
+	}
+	return level.Level(), true
+}
+
+// parseJSON decodes a JSON object into attributes in field order. Nested
+// objects are flattened into dotted keys and arrays are kept as JSON text.
+func parseJSON(data []byte, prefix string) ([]slog.Attr, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+
+	var attrs []slog.Attr
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key := prefix + tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, false
+		}
+
+		switch raw[0] {
+		case '{':
+			nested, ok := parseJSON(raw, key+".")
+			if !ok {
+				return nil, false
+			}
+			attrs = append(attrs, nested...)
+		case '[':
+			var compact bytes.Buffer
+			_ = json.Compact(&compact, raw)
+			attrs = append(attrs, slog.String(key, compact.String()))
+		default:
+			var v any
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err != nil {
+				return nil, false
+			}
+			attrs = append(attrs, jsonAttr(key, v))
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, false
+	}
+	return attrs, true
+}
+
+// jsonAttr converts a decoded JSON scalar to an attribute.
+func jsonAttr(key string, v any) slog.Attr {
+	switch v := v.(type) {
+	case string:
+		return slog.String(key, v)
+	case bool:
+		return slog.Bool(key, v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return slog.Int64(key, n)
+		}
+		f, _ := v.Float64()
+		return slog.Float64(key, f)
+	default:
+		return slog.String(key, "null")
+	}
+}
+
+// parseLogfmt parses key=value pairs with optionally quoted values. Every
+// token must be a pair, so prose containing an "=" is not mistaken for
+// logfmt.
+func parseLogfmt(line string) ([]slog.Attr, bool) {
+	var attrs []slog.Attr
+	for line != "" {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || strings.ContainsAny(line[:eq], " \t\"") {
+			return nil, false
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := closingQuote(line)
+			if end < 0 {
+				return nil, false
+			}
+			s, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, line = s, line[end+1:]
+			attrs = append(attrs, slog.String(key, value))
+		} else {
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				end = len(line)
+			}
+			value, line = line[:end], line[end:]
+			attrs = append(attrs, logfmtAttr(key, value))
+		}
+
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			return nil, false
+		}
+		line = strings.TrimLeft(line, " \t")
+	}
+	return attrs, true
+}
+
+// closingQuote returns the index of the quote ending the quoted string at
+// the start of s, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// logfmtAttr converts an unquoted logfmt value, recognising numbers and
+// booleans.
+func logfmtAttr(key, value string) slog.Attr {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return slog.Int64(key, n)
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return slog.Float64(key, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		return slog.Bool(key, b)
+	}
+	return slog.String(key, value)
+}
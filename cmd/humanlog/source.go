@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// source is a named input producing lines.
+type source struct {
+	name string
+	// run sends the input's lines until it ends or ctx is done.
+	run func(ctx context.Context, lines chan<- []byte) error
+}
+
+// readerSource reads lines from r.
+func readerSource(name string, r io.Reader) source {
+	return source{name: name, run: func(ctx context.Context, lines chan<- []byte) error {
+		return readLines(ctx, r, lines)
+	}}
+}
+
+// fileSource reads the file at path, or follows it as it grows.
+func fileSource(path string, followFile bool) source {
+	return source{name: filepath.Base(path), run: func(ctx context.Context, lines chan<- []byte) error {
+		if followFile {
+			return follow(ctx, path, false, defaultPollInterval, lines)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return readLines(ctx, f, lines)
+	}}
+}
+
+// commandSource runs the shell command line and reads its standard output
+// and standard error.
+func commandSource(line string) source {
+	return execSource(line, "sh", "-c", line)
+}
+
+// podSource streams the logs of a Kubernetes pod given as "pod" or
+// "namespace/pod" with kubectl.
+func podSource(pod string) source {
+	name := pod
+	args := []string{"logs", "--follow"}
+	if ns, p, ok := strings.Cut(pod, "/"); ok {
+		name = p
+		args = append(args, "--namespace", ns)
+	}
+	return execSource(name, "kubectl", append(args, name)...)
+}
+
+// execSource runs a program and reads its combined output.
+func execSource(name, program string, args ...string) source {
+	return source{name: name, run: func(ctx context.Context, lines chan<- []byte) error {
+		cmd := exec.CommandContext(ctx, program, args...)
+		pr, pw := io.Pipe()
+		cmd.Stdout, cmd.Stderr = pw, pw
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		done := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			pw.Close()
+			done <- err
+		}()
+
+		err := readLines(ctx, pr, lines)
+		pr.Close()
+		if werr := <-done; ctx.Err() == nil && werr != nil && err == nil {
+			err = fmt.Errorf("%s: %w", name, werr)
+		}
+		return err
+	}}
+}
+
+// readLines sends every line of r to lines until r ends or ctx is done.
+// Lines longer than maxLineSize are cut to that length rather than ending
+// the stream.
+func readLines(ctx context.Context, r io.Reader, lines chan<- []byte) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if room := maxLineSize - len(line); len(chunk) > room {
+			chunk = chunk[:max(room, 0)]
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == nil || len(line) > 0 {
+			line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			select {
+			case lines <- bytes.Clone(line):
+			case <-ctx.Done():
+				return nil
+			}
+			line = line[:0]
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReadLines_LongLine(t *testing.T) {
+	in := "first\n" + strings.Repeat("x", 2*maxLineSize) + "\r\nlast"
+	lines := make(chan []byte, 10)
+	if err := readLines(context.Background(), strings.NewReader(in), lines); err != nil {
+		t.Fatalf("readLines() error = %v", err)
+	}
+	close(lines)
+
+	var got [][]byte
+	for line := range lines {
+		got = append(got, line)
+	}
+	if len(got) != 3 {
+		t.Fatalf("readLines() sent %d lines, want 3", len(got))
+	}
+	if string(got[0]) != "first" || string(got[2]) != "last" {
+		t.Errorf("lines = %q, %q, want first and last", got[0], got[2])
+	}
+	if want := bytes.Repeat([]byte("x"), maxLineSize); !bytes.Equal(got[1], want) {
+		t.Errorf("long line has %d bytes, want it truncated to %d", len(got[1]), maxLineSize)
+	}
+}
+
+func TestRun_DashReadsStdin(t *testing.T) {
+	var out, errOut bytes.Buffer
+	code := run(context.Background(), []string{"--no-color", "-"}, strings.NewReader(`{"level":"INFO","msg":"From stdin"}`+"\n"), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "From stdin") {
+		t.Errorf("output = %q, want the record read from stdin", out.String())
+	}
+}
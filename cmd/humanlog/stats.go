@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+// runStats executes "humanlog stats", which summarizes a log stream: the
+// record count per level, the most frequent message templates and error
+// fingerprints, percentiles of duration attributes and the time range.
+func runStats(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("humanlog stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	top := fs.Int("top", 10, "`number` of message templates and error groups shown")
+	if err := fs.Parse(interleaved(fs, args)); err != nil {
+		return 2
+	}
+
+	st := newStats()
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- readInputs(ctx, fs.Args(), stdin, lines)
+		close(lines)
+	}()
+	for line := range lines {
+		st.add(line)
+	}
+	if err := <-errc; err != nil {
+		fmt.Fprintln(stderr, "humanlog:", err)
+		return 1
+	}
+
+	if err := st.write(stdout, *top); err != nil {
+		fmt.Fprintln(stderr, "humanlog:", err)
+		return 1
+	}
+	return 0
+}
+
+// readInputs sends the lines of the files at paths, or of stdin if there
+// are none, one file after the other.
+func readInputs(ctx context.Context, paths []string, stdin io.Reader, lines chan<- []byte) error {
+	if len(paths) == 0 {
+		return readLines(ctx, stdin, lines)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = readLines(ctx, f, lines)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// stats accumulates the summary of a log stream.
+type stats struct {
+	records     int
+	unparsed    int
+	levels      map[slog.Level]int
+	first, last time.Time
+	durations   map[string][]time.Duration
+
+	// templates counts every record by message template and errors
+	// fingerprints the error records.
+	templates *humanlog.ErrorAggregator
+	errors    *humanlog.ErrorAggregator
+}
+
+func newStats() *stats {
+	templates := humanlog.NewErrorAggregator(slog.DiscardHandler, &humanlog.ErrorAggregatorOptions{Level: slog.Level(math.MinInt)})
+	return &stats{
+		levels:    make(map[slog.Level]int),
+		durations: make(map[string][]time.Duration),
+		templates: templates,
+		errors:    humanlog.NewErrorAggregator(templates, nil),
+	}
+}
+
+// add counts a line.
+func (s *stats) add(line []byte) {
+	r, ok := parseLine(line)
+	if !ok {
+		if len(strings.TrimSpace(string(line))) > 0 {
+			s.unparsed++
+		}
+		return
+	}
+
+	s.records++
+	s.levels[r.Level]++
+	if !r.Time.IsZero() {
+		if s.first.IsZero() || r.Time.Before(s.first) {
+			s.first = r.Time
+		}
+		if r.Time.After(s.last) {
+			s.last = r.Time
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if d, ok := durationValue(a); ok {
+			s.durations[a.Key] = append(s.durations[a.Key], d)
+		}
+		return true
+	})
+	_ = s.errors.Handle(context.Background(), r)
+}
+
+// durationUnits maps the key suffixes of numeric duration attributes, such
+// as "elapsed_ms", to their unit.
+var durationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"_ns", time.Nanosecond},
+	{"_us", time.Microsecond},
+	{"_ms", time.Millisecond},
+	{"_seconds", time.Second},
+	{"_sec", time.Second},
+	{"_s", time.Second},
+}
+
+// durationValue reports whether a holds a duration: a string such as
+// "1.5s", or a number whose key names its unit.
+func durationValue(a slog.Attr) (time.Duration, bool) {
+	var n float64
+	switch a.Value.Kind() {
+	case slog.KindString:
+		d, err := time.ParseDuration(a.Value.String())
+		return d, err == nil && strings.TrimLeft(a.Value.String(), "+-0123456789.") != ""
+	case slog.KindInt64:
+		n = float64(a.Value.Int64())
+	case slog.KindFloat64:
+		n = a.Value.Float64()
+	default:
+		return 0, false
+	}
+	for _, u := range durationUnits {
+		if strings.HasSuffix(a.Key, u.suffix) {
+			return time.Duration(n * float64(u.unit)), true
+		}
+	}
+	return 0, false
+}
+
+// write prints the summary, showing at most top templates and error
+// groups.
+func (s *stats) write(w io.Writer, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Records\t%d\n", s.records)
+	if s.unparsed > 0 {
+		fmt.Fprintf(tw, "Unstructured lines\t%d\n", s.unparsed)
+	}
+	if !s.first.IsZero() {
+		fmt.Fprintf(tw, "Time range\t%s – %s (%s)\n",
+			s.first.Format(time.RFC3339), s.last.Format(time.RFC3339), s.last.Sub(s.first).Round(time.Second))
+	}
+
+	levels := make([]slog.Level, 0, len(s.levels))
+	for level := range s.levels {
+		levels = append(levels, level)
+	}
+	slices.Sort(levels)
+	slices.Reverse(levels)
+	if len(levels) > 0 {
+		fmt.Fprintln(tw, "\nLevels")
+	}
+	for _, level := range levels {
+		fmt.Fprintf(tw, "  %s\t%d\n", level, s.levels[level])
+	}
+
+	if groups := s.templates.Groups(); len(groups) > 0 {
+		fmt.Fprintln(tw, "\nTop messages")
+		for _, g := range groups[:min(top, len(groups))] {
+			fmt.Fprintf(tw, "  %d\t%s\n", g.Count, g.Template)
+		}
+	}
+
+	if groups := s.errors.Groups(); len(groups) > 0 {
+		fmt.Fprintln(tw, "\nTop errors")
+		for _, g := range groups[:min(top, len(groups))] {
+			fmt.Fprintf(tw, "  %d\t%s\t%s\tlast %q\n", g.Count, g.Fingerprint, g.Template, g.Sample)
+		}
+	}
+
+	keys := make([]string, 0, len(s.durations))
+	for key := range s.durations {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if len(keys) > 0 {
+		fmt.Fprintln(tw, "\nDurations\tcount\tp50\tp95\tmax")
+	}
+	for _, key := range keys {
+		ds := s.durations[key]
+		slices.Sort(ds)
+		fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\n", key, len(ds), percentile(ds, 50), percentile(ds, 95), ds[len(ds)-1])
+	}
+	return tw.Flush()
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunStats(t *testing.T) {
+	in := strings.Join([]string{
+		`{"time":"2024-05-01T10:00:00Z","level":"INFO","msg":"Request 1 served","latency":"10ms"}`,
+		`{"time":"2024-05-01T10:00:30Z","level":"INFO","msg":"Request 2 served","latency":"30ms"}`,
+		`{"time":"2024-05-01T10:01:00Z","level":"INFO","msg":"Request 3 served","latency":"20ms","db_ms":4}`,
+		`{"time":"2024-05-01T10:02:00Z","level":"ERROR","msg":"Query 7 failed"}`,
+		`{"time":"2024-05-01T10:03:00Z","level":"ERROR","msg":"Query 8 failed"}`,
+		`plain text`,
+	}, "\n")
+
+	var out, errOut strings.Builder
+	if code := run(context.Background(), []string{"stats", "--top", "1"}, strings.NewReader(in), &out, &errOut); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+
+	// Compare with runs of spaces collapsed, as columns align per section
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Records 5",
+		"Unstructured lines 1",
+		"2024-05-01T10:00:00Z – 2024-05-01T10:03:00Z (3m0s)",
+		"ERROR 2",
+		"INFO 3",
+		"3 Request <n> served",
+		`Query <n> failed last "Query 8 failed"`,
+		"latency 3 20ms 30ms 30ms",
+		"db_ms 1 4ms 4ms 4ms",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stats output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "2 Query") {
+		t.Errorf("--top 1 showed a second template:\n%s", got)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// terminal is the controlling terminal switched to raw mode.
+type terminal struct {
+	tty     *os.File
+	resized chan os.Signal
+}
+
+// openTerminal is only supported on Unix, where stty switches the terminal
+// to raw mode.
+func openTerminal() (*terminal, error) {
+	return nil, errors.New("the viewer is not supported on this platform")
+}
+
+func (t *terminal) size() (width, height int, err error) { return 0, 0, errors.ErrUnsupported }
+
+func (t *terminal) close() error { return nil }
@@ -0,0 +1,66 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// terminal is the controlling terminal switched to raw mode.
+type terminal struct {
+	tty     *os.File
+	state   string // stty settings restored by close
+	resized chan os.Signal
+}
+
+// openTerminal opens the controlling terminal, so logs can still be piped
+// to standard input, and switches it to raw mode with stty.
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	state, err := stty(tty, "-g")
+	if err == nil {
+		_, err = stty(tty, "raw", "-echo")
+	}
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("stty: %w", err)
+	}
+
+	t := &terminal{tty: tty, state: state, resized: make(chan os.Signal, 1)}
+	signal.Notify(t.resized, syscall.SIGWINCH)
+	return t, nil
+}
+
+// size returns the terminal's width and height in cells.
+func (t *terminal) size() (width, height int, err error) {
+	out, err := stty(t.tty, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	_, err = fmt.Sscan(out, &height, &width)
+	return width, height, err
+}
+
+// close restores the terminal settings.
+func (t *terminal) close() error {
+	signal.Stop(t.resized)
+	_, err := stty(t.tty, t.state)
+	t.tty.Close()
+	return err
+}
+
+// stty runs stty on the terminal and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+func TestRunVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "audit.pub")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(pub)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var log bytes.Buffer
+	w := humanlog.NewAuditWriter(&log, &humanlog.AuditOptions{SignKey: priv})
+	_, _ = w.Write([]byte("INFO  Login user=1\nINFO  Logout user=1\n"))
+	_ = w.Close()
+	path := filepath.Join(dir, "audit.log")
+	if err := os.WriteFile(path, log.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut strings.Builder
+	if code := run(context.Background(), []string{"verify", "--public-key", keyFile, path}, nil, &out, &errOut); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, errOut.String())
+	}
+	if got := out.String(); !strings.Contains(got, "ok, 3 lines, 1 signatures") {
+		t.Errorf("output = %q, want a verified summary", got)
+	}
+
+	tampered := strings.Replace(log.String(), "user=1", "user=2", 1)
+	errOut.Reset()
+	if code := run(context.Background(), []string{"verify"}, strings.NewReader(tampered), &out, &errOut); code != 1 {
+		t.Errorf("run() on a tampered log = %d, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "line 1") {
+		t.Errorf("stderr = %q, want the broken line", errOut.String())
+	}
+}
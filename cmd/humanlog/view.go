@@ -0,0 +1,535 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+// Escape sequences used to draw the viewer.
+const (
+	enterScreen = "\033[?1049h\033[?25l" // alternate screen, hidden cursor
+	leaveScreen = "\033[?25h\033[?1049l"
+	reverse     = "\033[7m"
+	reset       = "\033[0m"
+)
+
+// redrawInterval limits how often the screen is redrawn for new lines.
+const redrawInterval = 50 * time.Millisecond
+
+// runView executes "humanlog view", an interactive viewer with
+// scrollback, incremental search, level toggles, attribute filters and
+// follow/pause. It reads standard input or follows the named files from
+// their beginning, and takes keys from the controlling terminal.
+func runView(ctx context.Context, args []string, stdin io.Reader, _, stderr io.Writer) int {
+	fs := flag.NewFlagSet("humanlog view", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colored output")
+	width := fs.Int("width", 40, "message column `width`")
+	timeFormat := fs.String("time-format", "seconds", "time `format`: seconds, millis, micros, relative or a Go layout")
+	if err := fs.Parse(interleaved(fs, args)); err != nil {
+		return 2
+	}
+
+	t, err := openTerminal()
+	if err != nil {
+		fmt.Fprintln(stderr, "humanlog: view needs a terminal:", err)
+		return 1
+	}
+	defer t.close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lines := make(chan []byte)
+	inputErrs := make(chan error, fs.NArg()+1)
+	go readViewInputs(ctx, fs.Args(), stdin, lines, inputErrs)
+	keys := make(chan string)
+	go readKeys(t.tty, keys)
+
+	v := newViewer(formatter(&humanlog.Options{
+		Level:        slog.Level(math.MinInt),
+		TimeFormat:   timeLayout(*timeFormat),
+		DisableColor: *noColor,
+		MessageWidth: *width,
+	}))
+	v.resize(t.size())
+
+	out := bufio.NewWriter(t.tty)
+	out.WriteString(enterScreen)
+	defer func() {
+		out.WriteString(leaveScreen)
+		out.Flush()
+	}()
+	draw := func() {
+		v.draw(out)
+		out.Flush()
+	}
+	draw()
+
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+	dirty := false
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case line, ok := <-lines:
+			if !ok {
+				lines = nil
+				continue
+			}
+			v.add(line)
+			dirty = true
+		case err := <-inputErrs:
+			v.message = err.Error()
+			dirty = true
+		case k, ok := <-keys:
+			if !ok || !v.key(k) {
+				return 0
+			}
+			draw()
+		case <-t.resized:
+			v.resize(t.size())
+			draw()
+		case <-ticker.C:
+			if dirty {
+				draw()
+				dirty = false
+			}
+		}
+	}
+}
+
+// formatter returns a function rendering a record as one line with the
+// humanlog handler.
+func formatter(opts *humanlog.Options) func(slog.Record) string {
+	var buf bytes.Buffer
+	h := humanlog.NewHandler(&buf, opts)
+	return func(r slog.Record) string {
+		buf.Reset()
+		_ = h.Handle(context.Background(), r)
+		return strings.TrimRight(buf.String(), "\n")
+	}
+}
+
+// readViewInputs sends the lines of standard input, or of the files at
+// paths followed from their beginning, reporting failures to errs.
+func readViewInputs(ctx context.Context, paths []string, stdin io.Reader, lines chan<- []byte, errs chan<- error) {
+	if len(paths) == 0 {
+		if err := readLines(ctx, stdin, lines); err != nil {
+			errs <- err
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := follow(ctx, path, true, defaultPollInterval, lines); err != nil {
+				errs <- fmt.Errorf("%s: %w", path, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// keyNames maps terminal input sequences to key names.
+var keyNames = map[string]string{
+	"\r": "enter", "\n": "enter", "\x1b": "esc", "\x7f": "backspace", "\b": "backspace",
+	"\x03": "ctrl-c", "\x1b[A": "up", "\x1b[B": "down", "\x1b[5~": "pgup", "\x1b[6~": "pgdn",
+	"\x1b[H": "home", "\x1b[F": "end", "\x1b[1~": "home", "\x1b[4~": "end",
+	"\x1bOA": "up", "\x1bOB": "down", "\x1bOH": "home", "\x1bOF": "end",
+}
+
+// readKeys sends the keys read from r until it fails, then closes keys.
+// Escape sequences are expected to arrive in a single read.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		in := string(buf[:n])
+		if name, ok := keyNames[in]; ok {
+			keys <- name
+			continue
+		}
+		if strings.HasPrefix(in, "\x1b") {
+			continue // unknown sequence
+		}
+		for _, c := range in {
+			if name, ok := keyNames[string(c)]; ok {
+				keys <- name
+			} else {
+				keys <- string(c)
+			}
+		}
+	}
+}
+
+// viewEntry is a line shown by the viewer.
+type viewEntry struct {
+	rec   slog.Record
+	ok    bool   // rec was parsed from the line
+	text  string // formatted line
+	plain string // formatted line without escape sequences
+}
+
+// viewer is the state of the interactive viewer: the lines read so far,
+// the filters and the scroll position. It is driven by key names and
+// drawn to any writer, independent of the terminal.
+type viewer struct {
+	format  func(slog.Record) string
+	entries []viewEntry
+	visible []int // indexes of the entries passing the filters
+
+	hidden [4]bool // level classes toggled off: debug, info, warn, error
+	where  []condition
+	search *regexp.Regexp
+
+	prompt      byte // '/' or ':' while editing a search or filter
+	input       string
+	savedSearch *regexp.Regexp
+	savedTop    int
+
+	top           int // index in visible of the first line shown
+	follow        bool
+	width, height int
+	message       string
+}
+
+// levelNames label the level classes in the status line.
+const levelNames = "DIWE"
+
+func newViewer(format func(slog.Record) string) *viewer {
+	return &viewer{format: format, follow: true, width: 80, height: 24}
+}
+
+// levelClass returns the index of the level's class in viewer.hidden.
+func levelClass(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 0
+	case level < slog.LevelWarn:
+		return 1
+	case level < slog.LevelError:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// add appends a line read from the input.
+func (v *viewer) add(line []byte) {
+	e := viewEntry{text: string(line)}
+	e.rec, e.ok = parseLine(line)
+	if e.ok {
+		e.text = v.format(e.rec)
+	}
+	e.plain = stripEscapes(e.text)
+	v.entries = append(v.entries, e)
+	if v.shows(e) {
+		v.visible = append(v.visible, len(v.entries)-1)
+		if v.follow {
+			v.bottom()
+		}
+	}
+}
+
+// shows reports whether the entry passes the level toggles and attribute
+// filters. Lines that are not records are hidden by attribute filters.
+func (v *viewer) shows(e viewEntry) bool {
+	if !e.ok {
+		return len(v.where) == 0
+	}
+	if v.hidden[levelClass(e.rec.Level)] {
+		return false
+	}
+	for _, c := range v.where {
+		if !c.holds(e.rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// refilter recomputes the visible lines, keeping the first line shown in
+// place where possible.
+func (v *viewer) refilter() {
+	first := -1
+	if v.top < len(v.visible) {
+		first = v.visible[v.top]
+	}
+	v.visible = v.visible[:0]
+	v.top = 0
+	for i, e := range v.entries {
+		if v.shows(e) {
+			if i <= first {
+				v.top = len(v.visible)
+			}
+			v.visible = append(v.visible, i)
+		}
+	}
+	if v.follow {
+		v.bottom()
+	}
+	v.scroll(0)
+}
+
+// rows returns the number of lines shown above the status line.
+func (v *viewer) rows() int {
+	return max(v.height-1, 1)
+}
+
+// resize sets the screen size, ignoring errors reading it.
+func (v *viewer) resize(width, height int, err error) {
+	if err != nil || width <= 0 || height <= 0 {
+		return
+	}
+	v.width, v.height = width, height
+	if v.follow {
+		v.bottom()
+	}
+	v.scroll(0)
+}
+
+// scroll moves the view by n lines, pausing follow when moving up.
+func (v *viewer) scroll(n int) {
+	if n < 0 {
+		v.follow = false
+	}
+	v.top = max(min(v.top+n, len(v.visible)-v.rows()), 0)
+}
+
+// bottom scrolls to the last lines.
+func (v *viewer) bottom() {
+	v.top = max(len(v.visible)-v.rows(), 0)
+}
+
+// key handles a key and reports whether the viewer keeps running.
+func (v *viewer) key(k string) bool {
+	v.message = ""
+	if v.prompt != 0 {
+		v.edit(k)
+		return true
+	}
+
+	switch k {
+	case "q", "ctrl-c":
+		return false
+	case "j", "down", "enter":
+		v.scroll(1)
+	case "k", "up":
+		v.scroll(-1)
+	case " ", "pgdn":
+		v.scroll(v.rows())
+	case "b", "pgup":
+		v.scroll(-v.rows())
+	case "g", "home":
+		v.follow = false
+		v.top = 0
+	case "G", "end":
+		v.follow = true
+		v.bottom()
+	case "f":
+		v.follow = !v.follow
+		if v.follow {
+			v.bottom()
+		}
+	case "/":
+		v.prompt, v.input = '/', ""
+		v.savedSearch, v.savedTop = v.search, v.top
+	case ":":
+		v.prompt, v.input = ':', ""
+		for _, c := range v.where {
+			v.input += " " + c.key + c.op + c.value
+		}
+		v.input = strings.TrimSpace(v.input)
+	case "n":
+		v.jump(v.top+1, 1)
+	case "N":
+		v.jump(v.top-1, -1)
+	case "1", "2", "3", "4":
+		i := int(k[0] - '1')
+		v.hidden[i] = !v.hidden[i]
+		v.refilter()
+	case "esc":
+		v.search = nil
+	}
+	return true
+}
+
+// edit handles a key while a search or filter is being typed. Searches are
+// applied as they are typed; Escape restores the previous one.
+func (v *viewer) edit(k string) {
+	switch k {
+	case "esc", "ctrl-c":
+		if v.prompt == '/' {
+			v.search, v.top = v.savedSearch, v.savedTop
+		}
+		v.prompt = 0
+		return
+	case "enter":
+		if v.prompt == ':' {
+			v.applyWhere()
+		} else if v.input == "" {
+			v.search = nil
+		}
+		v.prompt = 0
+		return
+	case "backspace":
+		if v.input != "" {
+			_, size := utf8.DecodeLastRuneInString(v.input)
+			v.input = v.input[:len(v.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(k) != 1 {
+			return
+		}
+		v.input += k
+	}
+
+	if v.prompt == '/' {
+		re, err := regexp.Compile("(?i)" + v.input)
+		if err != nil || v.input == "" {
+			return
+		}
+		v.search = re
+		v.top = v.savedTop
+		v.jump(v.top, 1)
+	}
+}
+
+// applyWhere replaces the attribute filters by the typed conditions.
+func (v *viewer) applyWhere() {
+	var where []condition
+	for _, expr := range strings.Fields(v.input) {
+		c, err := parseCondition(expr)
+		if err != nil {
+			v.message = err.Error()
+			return
+		}
+		where = append(where, c)
+	}
+	v.where = where
+	v.refilter()
+}
+
+// jump scrolls to the first line matching the search, starting at the
+// visible line from and moving in direction dir.
+func (v *viewer) jump(from, dir int) {
+	if v.search == nil {
+		return
+	}
+	for i := from; i >= 0 && i < len(v.visible); i += dir {
+		if v.search.MatchString(v.entries[v.visible[i]].plain) {
+			v.follow = false
+			v.top = i
+			return
+		}
+	}
+	v.message = "pattern not found"
+}
+
+// draw writes the screen: the visible lines, with search matches in
+// reverse video, and a status line.
+func (v *viewer) draw(w io.Writer) {
+	fmt.Fprint(w, "\033[H")
+	for row := 0; row < v.rows(); row++ {
+		fmt.Fprint(w, "\033[2K")
+		if i := v.top + row; i < len(v.visible) {
+			e := v.entries[v.visible[i]]
+			if v.search != nil && v.search.MatchString(e.plain) {
+				fmt.Fprint(w, reverse, truncate(e.plain, v.width), reset)
+			} else {
+				fmt.Fprint(w, truncate(e.text, v.width), reset)
+			}
+		}
+		fmt.Fprint(w, "\r\n")
+	}
+	status := truncate(v.status(), v.width)
+	fmt.Fprint(w, "\033[2K", reverse, status, strings.Repeat(" ", max(v.width-utf8.RuneCountInString(status), 0)), reset)
+}
+
+// status returns the text of the status line.
+func (v *viewer) status() string {
+	if v.prompt != 0 {
+		return string(v.prompt) + v.input
+	}
+
+	mode := "PAUSED"
+	if v.follow {
+		mode = "FOLLOW"
+	}
+	last := min(v.top+v.rows(), len(v.visible))
+	levels := []byte(levelNames)
+	for i, hidden := range v.hidden {
+		if hidden {
+			levels[i] = '-'
+		}
+	}
+	s := fmt.Sprintf(" %s  %d-%d/%d  levels %s", mode, min(v.top+1, last), last, len(v.visible), levels)
+	if v.search != nil {
+		s += "  /" + strings.TrimPrefix(v.search.String(), "(?i)")
+	}
+	for i, c := range v.where {
+		if i == 0 {
+			s += "  where"
+		}
+		s += " " + c.key + c.op + c.value
+	}
+	if v.message != "" {
+		return s + "  " + v.message
+	}
+	return s + "  q quit  / search  : filter  1-4 levels  f follow"
+}
+
+// escapeSequence matches the SGR color sequences written by the handler.
+var escapeSequence = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// stripEscapes removes color sequences from s.
+func stripEscapes(s string) string {
+	return escapeSequence.ReplaceAllString(s, "")
+}
+
+// truncate shortens s to width visible characters, keeping its color
+// sequences.
+func truncate(s string, width int) string {
+	var b strings.Builder
+	n := 0
+	for s != "" {
+		if s[0] == '\033' {
+			if loc := escapeSequence.FindStringIndex(s); loc != nil && loc[0] == 0 {
+				b.WriteString(s[:loc[1]])
+				s = s[loc[1]:]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		if n == width {
+			break
+		}
+		if r != '\t' && r >= ' ' {
+			b.WriteRune(r)
+			n++
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
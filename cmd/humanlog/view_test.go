@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+func newTestViewer(t *testing.T, height int) *viewer {
+	t.Helper()
+	v := newViewer(formatter(&humanlog.Options{DisableColor: true, MessageWidth: 10, TimeFormat: humanlog.TimeFormatSeconds, Deterministic: true}))
+	v.resize(60, height, nil)
+	return v
+}
+
+func TestViewerFollowAndScroll(t *testing.T) {
+	v := newTestViewer(t, 4)
+	for i := range 10 {
+		v.add(fmt.Appendf(nil, `{"level":"INFO","msg":"Line %d"}`, i))
+	}
+	if v.top != 7 || !v.follow {
+		t.Fatalf("top = %d follow = %v, want 7 true", v.top, v.follow)
+	}
+
+	v.key("k")
+	v.add([]byte(`{"level":"INFO","msg":"Line 10"}`))
+	if v.top != 6 || v.follow {
+		t.Errorf("after scrolling up top = %d follow = %v, want 6 false", v.top, v.follow)
+	}
+	v.key("G")
+	if v.top != 8 || !v.follow {
+		t.Errorf("after G top = %d follow = %v, want 8 true", v.top, v.follow)
+	}
+	if !v.key("x") || v.key("q") {
+		t.Error("key() should only stop on q")
+	}
+}
+
+func TestViewerFilters(t *testing.T) {
+	v := newTestViewer(t, 10)
+	v.add([]byte(`{"level":"DEBUG","msg":"Cache hit"}`))
+	v.add([]byte(`{"level":"INFO","msg":"Served","status":200}`))
+	v.add([]byte(`{"level":"ERROR","msg":"Failed","status":503}`))
+	v.add([]byte(`plain text`))
+
+	v.key("1")
+	if len(v.visible) != 3 {
+		t.Errorf("with debug hidden %d lines visible, want 3", len(v.visible))
+	}
+	for _, k := range []string{":", "s", "t", "a", "t", "u", "s", ">", "=", "5", "0", "0", "enter"} {
+		v.key(k)
+	}
+	if len(v.visible) != 1 || v.entries[v.visible[0]].rec.Message != "Failed" {
+		t.Errorf("with status>=500 visible = %v", v.visible)
+	}
+	v.key(":")
+	if v.input != "status>=500" {
+		t.Errorf("filter prompt = %q, want the current filter", v.input)
+	}
+	v.key("backspace")
+	v.key("backspace")
+	v.key("backspace")
+	v.key("backspace")
+	v.key("backspace")
+	v.key("enter")
+	if v.message == "" || len(v.visible) != 1 {
+		t.Errorf("invalid filter message %q, %d lines visible, want the filter kept", v.message, len(v.visible))
+	}
+}
+
+func TestViewerSearch(t *testing.T) {
+	v := newTestViewer(t, 3)
+	for i := range 6 {
+		v.add(fmt.Appendf(nil, `{"level":"INFO","msg":"Line %d"}`, i))
+	}
+	v.key("g")
+	for _, k := range []string{"/", "l", "i", "n", "e", " ", "4"} {
+		v.key(k)
+	}
+	if v.top != 4 {
+		t.Errorf("incremental search top = %d, want 4", v.top)
+	}
+	v.key("esc")
+	if v.top != 0 || v.search != nil {
+		t.Errorf("cancelled search top = %d search = %v, want 0 nil", v.top, v.search)
+	}
+
+	for _, k := range []string{"/", "L", "i", "n", "e", " ", "[", "1", "3", "]", "enter"} {
+		v.key(k)
+	}
+	v.key("n")
+	if v.top != 3 {
+		t.Errorf("next match top = %d, want 3", v.top)
+	}
+	v.key("n")
+	if v.top != 3 || v.message != "pattern not found" {
+		t.Errorf("past last match top = %d message = %q", v.top, v.message)
+	}
+
+	var screen strings.Builder
+	v.draw(&screen)
+	out := screen.String()
+	if !strings.Contains(out, reverse+"[00:00:00] INFO  Line 3") || !strings.Contains(out, "PAUSED  4-5/6") || !strings.Contains(out, "/Line [13]") {
+		t.Errorf("draw() = %q", out)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("\033[31mERROR\033[0m boom", 7); got != "\033[31mERROR\033[0m b" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
@@ -0,0 +1,217 @@
+package humanlog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// NameKey is the attribute key rendered by the ColumnName column.
+const NameKey = "logger"
+
+// ColumnKind identifies the content of a layout column.
+type ColumnKind int
+
+const (
+	// ColumnTime shows the record timestamp.
+	ColumnTime ColumnKind = iota
+	// ColumnLevel shows the level name.
+	ColumnLevel
+	// ColumnSource shows the source location as "file.go:line".
+	ColumnSource
+	// ColumnName shows the logger name, taken from the top-level
+	// attribute with key NameKey or else the handler's name.
+	ColumnName
+	// ColumnMessage shows the log message.
+	ColumnMessage
+	// ColumnAttrs shows the remaining attributes as key=value pairs.
+	ColumnAttrs
+)
+
+// Alignment controls how column content is placed within its width.
+type Alignment int
+
+const (
+	// AlignLeft pads content on the right. This is the default.
+	AlignLeft Alignment = iota
+	// AlignRight pads content on the left.
+	AlignRight
+	// AlignCenter pads content evenly on both sides.
+	AlignCenter
+)
+
+// Truncation controls what happens to content wider than its column.
+type Truncation int
+
+const (
+	// TruncateEnd cuts the end of the content and marks it with "...".
+	// This is the default.
+	TruncateEnd Truncation = iota
+	// TruncateStart cuts the start of the content and marks it with "...",
+	// which keeps the most specific part of paths visible.
+	TruncateStart
+	// TruncateCut cuts the end of the content without a marker.
+	TruncateCut
+	// TruncateNone lets content overflow the column.
+	TruncateNone
+)
+
+// Column describes one column of a tabular layout.
+type Column struct {
+	// Kind selects the column content.
+	Kind ColumnKind
+
+	// Width is the fixed width of the column in characters.
+	// Zero means the content's natural width.
+	Width int
+
+	// Align places content narrower than Width.
+	Align Alignment
+
+	// Truncate handles content wider than Width.
+	Truncate Truncation
+}
+
+// DefaultColumns returns a layout equivalent to the standard line format
+// with the source location in its own column.
+func DefaultColumns() []Column {
+	return []Column{
+		{Kind: ColumnTime},
+		{Kind: ColumnLevel, Width: levelWidth},
+		{Kind: ColumnSource, Width: 20, Align: AlignRight, Truncate: TruncateStart},
+		{Kind: ColumnMessage, Width: messageWidth},
+		{Kind: ColumnAttrs},
+	}
+}
+
+// formatColumns renders r using the handler's column layout.
+func (h *Handler) formatColumns(r slog.Record) string {
+	styler := h.opts.levelStyler()
+	hasName, hasSource := false, false
+	for _, col := range h.opts.Columns {
+		hasName = hasName || col.Kind == ColumnName
+		hasSource = hasSource || col.Kind == ColumnSource
+	}
+
+	skipKey := ""
+	if hasName {
+		skipKey = NameKey
+	}
+
+	var sb strings.Builder
+	for i, col := range h.opts.Columns {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		switch col.Kind {
+		case ColumnTime:
+			t := col.fit(h.formatTime(r.Time))
+			if style := h.opts.style(h.opts.TimeStyle); style != "" {
+				t = style + t + colorReset
+			}
+			sb.WriteString(t)
+		case ColumnLevel:
+			level := col.fit(styler.LevelName(r.Level))
+			if color := styler.LevelColor(r.Level); color != "" && !h.opts.DisableColor {
+				level = color + level + colorReset
+			}
+			sb.WriteString(level)
+		case ColumnSource:
+			sb.WriteString(col.fit(h.formatSource(r)))
+		case ColumnName:
+			sb.WriteString(col.fit(h.loggerName(r)))
+		case ColumnMessage:
+			sb.WriteString(col.fit(sanitize(r.Message, h.opts.ControlChars)))
+		case ColumnAttrs:
+			attrs := h.collectAttrs(r, h.opts.AddSource && !hasSource, skipKey)
+			sb.WriteString(col.fit(strings.Join(attrs, " ")))
+		}
+	}
+
+	// Avoid trailing padding when the last columns are empty
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// loggerName returns the value of the top-level NameKey attribute, looking
+// at the record's attributes first and then at the handler's, or else the
+// handler's name.
+func (h *Handler) loggerName(r slog.Record) string {
+	name := ""
+	if len(h.groups) == 0 {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == NameKey {
+				name = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if name != "" {
+		return name
+	}
+	for i := len(h.attrs) - 1; i >= 0; i-- {
+		if h.attrs[i].Key == NameKey {
+			return h.attrs[i].Value.String()
+		}
+	}
+	return h.name
+}
+
+// fit aligns and truncates s to the column width.
+func (c Column) fit(s string) string {
+	if c.Width <= 0 {
+		return s
+	}
+
+	n := visibleWidth(s)
+	if n > c.Width {
+		return c.truncate(s)
+	}
+
+	pad := c.Width - n
+	switch c.Align {
+	case AlignRight:
+		return strings.Repeat(" ", pad) + s
+	case AlignCenter:
+		left := pad / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", pad-left)
+	default:
+		return s + strings.Repeat(" ", pad)
+	}
+}
+
+// truncate shortens s, which is wider than the column, per the policy.
+// Escape sequences before the cut are kept when cutting the end; cutting
+// the start drops them.
+func (c Column) truncate(s string) string {
+	const marker = "..."
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		switch c.Truncate {
+		case TruncateEnd, TruncateCut:
+			cut, end := c.Width, colorReset
+			if c.Truncate == TruncateEnd && c.Width > len(marker) {
+				cut, end = c.Width-len(marker), colorReset+marker
+			}
+			s, _ = truncateVisible(s, cut)
+			return s + end
+		case TruncateStart:
+			s = stripANSI(s)
+		}
+	}
+	runes := []rune(s)
+	switch c.Truncate {
+	case TruncateNone:
+		return s
+	case TruncateCut:
+		return string(runes[:c.Width])
+	case TruncateStart:
+		if c.Width <= len(marker) {
+			return string(runes[len(runes)-c.Width:])
+		}
+		return marker + string(runes[len(runes)-(c.Width-len(marker)):])
+	default:
+		if c.Width <= len(marker) {
+			return string(runes[:c.Width])
+		}
+		return string(runes[:c.Width-len(marker)]) + marker
+	}
+}
@@ -0,0 +1,223 @@
+package humanlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ComponentLevels is a registry of minimum levels for named components,
+// configured with a spec such as "db=debug,http=warn,*=info". Component
+// names are matched exactly first and then against glob patterns (see
+// path.Match) in the order given; the "*" rule, or the default level,
+// applies to everything else. It implements flag.Value, so the spec can
+// come straight from a command-line flag, and it may be reconfigured while
+// loggers are in use.
+type ComponentLevels struct {
+	def slog.Leveler
+
+	mu    sync.RWMutex
+	spec  string
+	exact map[string]slog.Level
+	globs []componentRule
+	star  *slog.Level
+
+	// gen is incremented on every Set so handlers can cache their level
+	gen atomic.Uint64
+}
+
+type componentRule struct {
+	pattern string
+	level   slog.Level
+}
+
+// NewComponentLevels creates a registry whose unmatched components use
+// level def, or slog.LevelInfo if def is nil.
+func NewComponentLevels(def slog.Leveler) *ComponentLevels {
+	if def == nil {
+		def = slog.LevelInfo
+	}
+	return &ComponentLevels{def: def, exact: map[string]slog.Level{}}
+}
+
+// Set replaces the rules with spec, a comma-separated list of
+// component=level pairs. An invalid spec leaves the rules unchanged.
+func (c *ComponentLevels) Set(spec string) error {
+	exact := map[string]slog.Level{}
+	var globs []componentRule
+	var star *slog.Level
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, levelName, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("humanlog: invalid component level %q, want name=level", part)
+		}
+		var level LevelFlag
+		if err := level.Set(strings.TrimSpace(levelName)); err != nil {
+			return err
+		}
+
+		switch {
+		case name == "*":
+			l := level.Level()
+			star = &l
+		case strings.ContainsAny(name, `*?[\`):
+			if _, err := path.Match(name, ""); err != nil {
+				return fmt.Errorf("humanlog: invalid component pattern %q", name)
+			}
+			globs = append(globs, componentRule{pattern: name, level: level.Level()})
+		default:
+			exact[name] = level.Level()
+		}
+	}
+
+	c.mu.Lock()
+	c.spec, c.exact, c.globs, c.star = spec, exact, globs, star
+	c.mu.Unlock()
+	c.gen.Add(1)
+	return nil
+}
+
+// String returns the spec last passed to Set.
+func (c *ComponentLevels) String() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.spec
+}
+
+// Level returns the minimum level for the named component.
+func (c *ComponentLevels) Level(name string) slog.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if name != "" {
+		if level, ok := c.exact[name]; ok {
+			return level
+		}
+		for _, rule := range c.globs {
+			if ok, _ := path.Match(rule.pattern, name); ok {
+				return rule.level
+			}
+		}
+	}
+	if c.star != nil {
+		return *c.star
+	}
+	return c.def.Level()
+}
+
+// Handler wraps h so that records are filtered by the level of the
+// handler's component. Use Named to create component loggers from it.
+//
+// The component level replaces the level check of h, but h still drops
+// records below its own level, so h should be created with the lowest level
+// any component may use, such as slog.LevelDebug.
+func (c *ComponentLevels) Handler(h slog.Handler) *ComponentHandler {
+	return &ComponentHandler{h: h, levels: c, cache: new(componentCache)}
+}
+
+// ComponentHandler is a slog.Handler enabled according to the level
+// configured for its component in a ComponentLevels registry.
+type ComponentHandler struct {
+	h      slog.Handler
+	levels *ComponentLevels
+	name   string
+	cache  *componentCache
+}
+
+// componentCache holds a handler's resolved level for one generation of
+// the registry, so Enabled does not match rules on every call.
+type componentCache struct {
+	entry atomic.Pointer[componentCacheEntry]
+}
+
+type componentCacheEntry struct {
+	gen   uint64
+	level slog.Level
+}
+
+// level returns the handler's minimum level.
+func (h *ComponentHandler) level() slog.Level {
+	gen := h.levels.gen.Load()
+	if e := h.cache.entry.Load(); e != nil && e.gen == gen {
+		return e.level
+	}
+	level := h.levels.Level(h.name)
+	h.cache.entry.Store(&componentCacheEntry{gen: gen, level: level})
+	return level
+}
+
+// Name returns the component name, or "" for the root handler.
+func (h *ComponentHandler) Name() string {
+	return h.name
+}
+
+// Named returns a handler for the named component. Names of nested
+// components are joined with dots, so Named("db") followed by
+// Named("pool") is the component "db.pool". The name is added to records
+// as the NameKey attribute.
+func (h *ComponentHandler) Named(name string) *ComponentHandler {
+	if h.name != "" {
+		name = h.name + "." + name
+	}
+	return &ComponentHandler{
+		h:      h.h.WithAttrs([]slog.Attr{slog.String(NameKey, name)}),
+		levels: h.levels,
+		name:   name,
+		cache:  new(componentCache),
+	}
+}
+
+// Enabled reports whether level is at or above the component's level, or
+// the level set on ctx with WithMinLevel.
+func (h *ComponentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if min, ok := MinLevelFromContext(ctx); ok {
+		return level >= min
+	}
+	return level >= h.level()
+}
+
+// Handle passes the record to the wrapped handler.
+func (h *ComponentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new handler for the same component whose records
+// include the given attributes.
+func (h *ComponentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ComponentHandler{h: h.h.WithAttrs(attrs), levels: h.levels, name: h.name, cache: h.cache}
+}
+
+// WithGroup returns a new handler for the same component that uses the
+// given group.
+func (h *ComponentHandler) WithGroup(name string) slog.Handler {
+	return &ComponentHandler{h: h.h.WithGroup(name), levels: h.levels, name: h.name, cache: h.cache}
+}
+
+// Named returns a logger for the named component. If logger's handler is a
+// ComponentHandler or ConfigHandler the component's level applies, and if
+// it is a Handler the name is appended with WithName; otherwise the name
+// is only added as the NameKey attribute.
+func Named(logger *slog.Logger, name string) *slog.Logger {
+	switch h := logger.Handler().(type) {
+	case *ComponentHandler:
+		return slog.New(h.Named(name))
+	case *ConfigHandler:
+		return slog.New(h.Named(name))
+	case *Handler:
+		return slog.New(h.WithName(name))
+	}
+	return logger.With(slog.String(NameKey, name))
+}
@@ -0,0 +1,101 @@
+package humanlog
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestComponentLevels_Level(t *testing.T) {
+	levels := NewComponentLevels(slog.LevelWarn)
+	if err := levels.Set("db=debug, http.*=error, db.pool=info"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want slog.Level
+	}{
+		{"db", slog.LevelDebug},
+		{"db.pool", slog.LevelInfo},
+		{"http.server", slog.LevelError},
+		{"cache", slog.LevelWarn},
+		{"", slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		if got := levels.Level(tt.name); got != tt.want {
+			t.Errorf("Level(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := levels.Set("*=debug"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := levels.Level("cache"); got != slog.LevelDebug {
+		t.Errorf("Level(cache) after *=debug = %v, want DEBUG", got)
+	}
+
+	for _, spec := range []string{"db", "db=loud", "[=info"} {
+		if err := levels.Set(spec); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", spec)
+		}
+	}
+	if got := levels.String(); got != "*=debug" {
+		t.Errorf("String() after failed Set = %q, want previous spec", got)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	var buf bytes.Buffer
+	levels := NewComponentLevels(nil)
+	if err := levels.Set("db=debug,http=warn"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	root := slog.New(levels.Handler(NewHandler(&buf, &Options{Level: slog.LevelDebug, DisableColor: true})))
+
+	db := Named(root, "db")
+	http := Named(root, "http")
+	db.Debug("Query planned")
+	http.Info("Request served")
+	http.Warn("Slow request")
+	root.Debug("Root debug")
+
+	out := buf.String()
+	if !strings.Contains(out, "Query planned") || !strings.Contains(out, "logger=db") {
+		t.Errorf("db debug record missing, got %q", out)
+	}
+	if strings.Contains(out, "Request served") || strings.Contains(out, "Root debug") {
+		t.Errorf("records below component level were logged: %q", out)
+	}
+	if !strings.Contains(out, "Slow request") {
+		t.Errorf("http warn record missing, got %q", out)
+	}
+
+	// Reconfiguring applies to existing loggers
+	if err := levels.Set("http=info"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	http.Info("Now visible")
+	db.Debug("Now hidden")
+	if !strings.Contains(buf.String(), "Now visible") || strings.Contains(buf.String(), "Now hidden") {
+		t.Errorf("reconfigured levels not applied, got %q", buf.String())
+	}
+
+	if got := Named(db, "pool").Handler().(*ComponentHandler).Name(); got != "db.pool" {
+		t.Errorf("nested Name() = %q, want db.pool", got)
+	}
+}
+
+func TestComponentLevels_Flag(t *testing.T) {
+	levels := NewComponentLevels(nil)
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Var(levels, "log-levels", "component levels")
+	if err := fs.Parse([]string{"-log-levels=db=debug"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := levels.Level("db"); got != slog.LevelDebug {
+		t.Errorf("Level(db) = %v, want DEBUG", got)
+	}
+}
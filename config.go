@@ -0,0 +1,524 @@
+package humanlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config describes a logging setup in a JSON file, so operators can change
+// levels, formats and destinations without rebuilding the service:
+//
+//	{
+//	  "level": "info",
+//	  "components": "db=debug,http.*=warn",
+//	  "redact": ["password", "*_token"],
+//	  "time_format": "15:04:05.000",
+//	  "sinks": [
+//	    {"type": "stderr"},
+//	    {"type": "file", "path": "/var/log/app.log", "format": "json", "level": "debug", "max_size": 10485760}
+//	  ]
+//	}
+//
+// Only JSON is supported, as YAML and TOML would need dependencies.
+type Config struct {
+	// Level is the default minimum level, e.g. "debug" or "warn".
+	// Default: "info"
+	Level string `json:"level"`
+
+	// Components sets the levels of named components, e.g.
+	// "db=debug,*=info", see ComponentLevels. Components are named with
+	// Named; unmatched ones use Level.
+	Components string `json:"components"`
+
+	// Redact lists the attribute keys whose values are replaced with
+	// "[REDACTED]" in every sink, matched like FormatterRegistry
+	// patterns, e.g. "password" or "*_token".
+	Redact []string `json:"redact"`
+
+	// Format is the default output format: "human", "json", "gcp" or
+	// "datadog". Default: "human"
+	Format string `json:"format"`
+
+	// TimeFormat is the human-readable timestamp layout.
+	// Default: TimeFormatSeconds
+	TimeFormat string `json:"time_format"`
+
+	// DisableColor disables ANSI colors in human-readable output.
+	DisableColor bool `json:"disable_color"`
+
+	// AddSource adds the source location to records.
+	AddSource bool `json:"add_source"`
+
+	// MessageWidth is the width of the message column. Default: 40
+	MessageWidth int `json:"message_width"`
+
+	// Service, Environment and Version identify the service.
+	Service     string `json:"service"`
+	Environment string `json:"environment"`
+	Version     string `json:"version"`
+
+	// Sinks are the destinations. Default: a single stderr sink
+	Sinks []SinkConfig `json:"sinks"`
+}
+
+// SinkConfig describes one destination of a Config.
+type SinkConfig struct {
+	// Type is "stdout", "stderr" or "file".
+	Type string `json:"type"`
+
+	// Path is the file written by a "file" sink.
+	Path string `json:"path"`
+
+	// Level and Format override the Config defaults for this sink.
+	Level  string `json:"level"`
+	Format string `json:"format"`
+
+	// MaxSize, MaxBackups and Compress configure rotation of a "file"
+	// sink, see RotateOptions.
+	MaxSize    int64 `json:"max_size"`
+	MaxBackups int   `json:"max_backups"`
+	Compress   bool  `json:"compress"`
+}
+
+// LoadConfig reads and validates a Config from a JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("humanlog: read config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("humanlog: parse config %s: %w", path, err)
+	}
+	if _, err := cfg.options(SinkConfig{}); err != nil {
+		return nil, err
+	}
+	if _, err := cfg.componentLevels(); err != nil {
+		return nil, err
+	}
+	if err := validRedactKeys(cfg.Redact); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Build creates a handler writing to the configured sinks. The returned
+// io.Closer closes the handler, syncing the files opened for "file" sinks,
+// and then the files. If Components is
+// set, the handler is a *ComponentHandler.
+func (c *Config) Build() (slog.Handler, io.Closer, error) {
+	levels, err := c.componentLevels()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validRedactKeys(c.Redact); err != nil {
+		return nil, nil, err
+	}
+
+	sinks := c.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: "stderr"}}
+	}
+
+	var routes []Route
+	var files closers
+	for _, sink := range sinks {
+		opts, err := c.options(sink)
+		if err != nil {
+			_ = files.Close()
+			return nil, nil, err
+		}
+
+		var w io.Writer
+		switch sink.Type {
+		case "stdout":
+			w = os.Stdout
+		case "stderr", "":
+			w = os.Stderr
+		case "file":
+			rw, err := NewRotatingWriter(RotateOptions{
+				Filename:   sink.Path,
+				MaxSize:    sink.MaxSize,
+				MaxBackups: sink.MaxBackups,
+				Compress:   sink.Compress,
+			})
+			if err != nil {
+				_ = files.Close()
+				return nil, nil, err
+			}
+			files = append(files, rw)
+			w = rw
+		default:
+			_ = files.Close()
+			return nil, nil, fmt.Errorf("humanlog: unknown sink type %q", sink.Type)
+		}
+		if levels != nil && sink.Level == "" {
+			// The component levels decide; the sink passes everything on
+			opts.Level = LevelTrace
+		}
+		routes = append(routes, Route{Writer: w, Options: opts})
+	}
+
+	router := NewRouter(routes...)
+	var h slog.Handler = router
+	if len(c.Redact) > 0 {
+		h = &redactHandler{h: h, keys: c.Redact}
+	}
+	if levels != nil {
+		h = levels.Handler(h)
+	}
+	// Closing the routes first syncs the files under SyncOnClose
+	return h, append(closers{router}, files...), nil
+}
+
+// componentLevels returns the registry configured by Components, or nil if
+// it is empty.
+func (c *Config) componentLevels() (*ComponentLevels, error) {
+	if c.Components == "" {
+		return nil, nil
+	}
+	level := slog.LevelInfo
+	if c.Level != "" {
+		var l LevelFlag
+		if err := l.Set(c.Level); err != nil {
+			return nil, err
+		}
+		level = l.Level()
+	}
+	levels := NewComponentLevels(level)
+	if err := levels.Set(c.Components); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// options returns the Options for sink, applying the Config defaults.
+func (c *Config) options(sink SinkConfig) (*Options, error) {
+	opts := DefaultOptions()
+	opts.AddSource = c.AddSource
+	opts.DisableColor = c.DisableColor
+	opts.Service = c.Service
+	opts.Environment = c.Environment
+	opts.Version = c.Version
+	if c.TimeFormat != "" {
+		opts.TimeFormat = c.TimeFormat
+	}
+	if c.MessageWidth > 0 {
+		opts.MessageWidth = c.MessageWidth
+	}
+
+	level := c.Level
+	if sink.Level != "" {
+		level = sink.Level
+	}
+	if level != "" {
+		var l LevelFlag
+		if err := l.Set(level); err != nil {
+			return nil, err
+		}
+		opts.Level = l.Level()
+	}
+
+	format := c.Format
+	if sink.Format != "" {
+		format = sink.Format
+	}
+	if format != "" {
+		var f Format
+		if err := f.Set(format); err != nil {
+			return nil, err
+		}
+		f.apply(opts)
+	}
+	return opts, nil
+}
+
+// closers closes several io.Closers and joins their errors.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var errs []error
+	for _, c := range cs {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// defaultReloadInterval is how often a ConfigHandler checks its file.
+const defaultReloadInterval = 2 * time.Second
+
+// ConfigHandlerOptions configures a ConfigHandler.
+type ConfigHandlerOptions struct {
+	// ReloadInterval is how often the file is checked for changes. A
+	// negative value disables automatic reloading; Reload can still be
+	// called, e.g. on SIGHUP.
+	// Default: 2s
+	ReloadInterval time.Duration
+
+	// OnReload, if set, is called after every reload attempt with its
+	// error, or nil if the new configuration is in effect. A failed reload
+	// keeps the previous configuration. An error reading the file is
+	// reported once, not on every poll until it is fixed.
+	OnReload func(error)
+}
+
+// ConfigHandler is a slog.Handler built from a config file that reloads
+// itself when the file changes, so operators can e.g. switch a running
+// service to debug by editing the file. Changes are detected by polling
+// the file's modification time. Loggers derived with With and WithGroup
+// pick up the new configuration as well.
+type ConfigHandler struct {
+	root  *configRoot
+	chain []func(slog.Handler) slog.Handler // WithAttrs/WithGroup calls to replay
+	cache atomic.Pointer[configCache]
+}
+
+// configRoot is the reloadable state shared by a ConfigHandler and every
+// handler derived from it.
+type configRoot struct {
+	path string
+	opts ConfigHandlerOptions
+
+	mu      sync.Mutex // serializes reloads
+	current atomic.Pointer[configCache]
+	closer  io.Closer
+	modTime time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// configCache is a built handler and the reload generation it belongs to.
+type configCache struct {
+	gen uint64
+	h   slog.Handler
+
+	// inflight counts the Handle calls using h, so a reload closes the
+	// old sinks only once they are done
+	inflight atomic.Int64
+}
+
+// drain waits until no Handle call is using c.
+func (c *configCache) drain() {
+	for c.inflight.Load() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// NewConfigHandler loads the config file at path and, unless disabled,
+// starts watching it for changes. Call Close to stop watching and close
+// the sinks.
+func NewConfigHandler(path string, opts *ConfigHandlerOptions) (*ConfigHandler, error) {
+	var options ConfigHandlerOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.ReloadInterval == 0 {
+		options.ReloadInterval = defaultReloadInterval
+	}
+
+	root := &configRoot{path: path, opts: options}
+	if err := root.reload(); err != nil {
+		return nil, err
+	}
+	if options.ReloadInterval > 0 {
+		root.stop = make(chan struct{})
+		root.done = make(chan struct{})
+		go root.watch()
+	}
+	return &ConfigHandler{root: root}, nil
+}
+
+// Enabled reports whether the current configuration handles records at
+// the given level.
+func (c *ConfigHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return c.handler().Enabled(ctx, level)
+}
+
+// Handle passes the record to the handler built from the current configuration.
+func (c *ConfigHandler) Handle(ctx context.Context, r slog.Record) error {
+	root := c.root.acquire()
+	defer root.inflight.Add(-1)
+	return c.handlerFor(root).Handle(ctx, r)
+}
+
+// WithAttrs returns a new ConfigHandler whose records include the given attributes.
+func (c *ConfigHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return c.derive(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+// WithGroup returns a new ConfigHandler whose records use the given group.
+func (c *ConfigHandler) WithGroup(name string) slog.Handler {
+	return c.derive(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+// Named returns a handler for the named component, like
+// ComponentHandler.Named, whose level is set by the config's Components.
+// Without Components the name is only added as the NameKey attribute.
+func (c *ConfigHandler) Named(name string) *ConfigHandler {
+	return c.derive(func(h slog.Handler) slog.Handler {
+		if ch, ok := h.(*ComponentHandler); ok {
+			return ch.Named(name)
+		}
+		return h.WithAttrs([]slog.Attr{slog.String(NameKey, name)})
+	})
+}
+
+// Reload re-reads the config file and switches to it if it is valid. Like
+// a reload triggered by a change, its result is passed to OnReload.
+func (c *ConfigHandler) Reload() error {
+	err := c.root.reload()
+	c.root.reported(err)
+	return err
+}
+
+// Close stops watching the file and closes the sinks.
+func (c *ConfigHandler) Close() error {
+	r := c.root
+	if r.stop != nil {
+		r.mu.Lock()
+		select {
+		case <-r.stop:
+		default:
+			close(r.stop)
+		}
+		r.mu.Unlock()
+		<-r.done
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closer == nil {
+		return nil
+	}
+	r.current.Load().drain()
+	err := r.closer.Close()
+	r.closer = nil
+	return err
+}
+
+func (c *ConfigHandler) derive(fn func(slog.Handler) slog.Handler) *ConfigHandler {
+	chain := append(append([]func(slog.Handler) slog.Handler{}, c.chain...), fn)
+	return &ConfigHandler{root: c.root, chain: chain}
+}
+
+// handler returns the current handler with this handler's attributes and
+// groups applied, rebuilding it after a reload.
+func (c *ConfigHandler) handler() slog.Handler {
+	return c.handlerFor(c.root.current.Load())
+}
+
+// handlerFor returns root's handler with this handler's attributes and
+// groups applied.
+func (c *ConfigHandler) handlerFor(root *configCache) slog.Handler {
+	if len(c.chain) == 0 {
+		return root.h
+	}
+	if cached := c.cache.Load(); cached != nil && cached.gen == root.gen {
+		return cached.h
+	}
+	h := root.h
+	for _, fn := range c.chain {
+		h = fn(h)
+	}
+	c.cache.Store(&configCache{gen: root.gen, h: h})
+	return h
+}
+
+// acquire returns the current handler, counted as in flight until the
+// caller decrements inflight. The check after counting ensures a reload
+// that swapped it out meanwhile waits for the caller before closing it.
+func (r *configRoot) acquire() *configCache {
+	for {
+		cur := r.current.Load()
+		cur.inflight.Add(1)
+		if r.current.Load() == cur {
+			return cur
+		}
+		cur.inflight.Add(-1)
+	}
+}
+
+// reload loads the file and swaps in the new handler, closing the old
+// sinks once the records being written to them are done.
+func (r *configRoot) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("humanlog: read config: %w", err)
+	}
+	// An invalid file is reported once, not on every poll
+	r.modTime = info.ModTime()
+
+	cfg, err := LoadConfig(r.path)
+	if err != nil {
+		return err
+	}
+	h, closer, err := cfg.Build()
+	if err != nil {
+		return err
+	}
+
+	var gen uint64
+	prev := r.current.Load()
+	if prev != nil {
+		gen = prev.gen + 1
+	}
+	r.current.Store(&configCache{gen: gen, h: h})
+
+	old := r.closer
+	r.closer = closer
+	if old != nil {
+		prev.drain()
+		_ = old.Close()
+	}
+	return nil
+}
+
+// watch polls the file and reloads it when its modification time changes.
+func (r *configRoot) watch() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opts.ReloadInterval)
+	defer ticker.Stop()
+	var statErr string // the last stat error reported, so it is not repeated every tick
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				if err.Error() != statErr {
+					statErr = err.Error()
+					r.reported(fmt.Errorf("humanlog: read config: %w", err))
+				}
+				continue
+			}
+			statErr = ""
+			r.mu.Lock()
+			changed := !info.ModTime().Equal(r.modTime)
+			r.mu.Unlock()
+			if changed {
+				r.reported(r.reload())
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *configRoot) reported(err error) {
+	if r.opts.OnReload != nil {
+		r.opts.OnReload(err)
+	}
+}
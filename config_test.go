@@ -0,0 +1,214 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("set config mtime: %v", err)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"level":      `{"level": "loud"}`,
+		"format":     `{"format": "xml"}`,
+		"syntax":     `{"level": `,
+		"components": `{"components": "db"}`,
+		"redact":     `{"redact": ["["]}`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name+".json")
+		writeConfig(t, path, content, time.Now())
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("LoadConfig(%s) error = nil, want error", name)
+		}
+	}
+}
+
+func TestConfig_ComponentsAndRedact(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	cfg := Config{
+		Level:        "info",
+		Components:   "db=debug",
+		Redact:       []string{"password", "*_token"},
+		DisableColor: true,
+		Sinks:        []SinkConfig{{Type: "file", Path: logPath}},
+	}
+	h, closer, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	logger := slog.New(h)
+	Named(logger, "db").Debug("Query", "password", "hunter2")
+	Named(logger, "http").Debug("Hidden")
+	logger.WithGroup("auth").Info("Login", "user", "ann", "refresh_token", "abc")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{"Query", `password="[REDACTED]"`, "auth.user=ann", `auth.refresh_token="[REDACTED]"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log = %q, want it to contain %q", got, want)
+		}
+	}
+	for _, unwanted := range []string{"hunter2", "abc", "Hidden"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("log = %q, should not contain %q", got, unwanted)
+		}
+	}
+}
+
+func TestConfigHandler_ReloadWhileLogging(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.json")
+	logPath := filepath.Join(dir, "app.log")
+	writeConfig(t, cfgPath, `{"sinks": [{"type": "file", "path": "`+logPath+`"}]}`, time.Now())
+
+	h, err := NewConfigHandler(cfgPath, &ConfigHandlerOptions{ReloadInterval: -1})
+	if err != nil {
+		t.Fatalf("NewConfigHandler() error = %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "Busy", 0)); err != nil {
+					t.Errorf("Handle() during reload error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	// Old sinks are closed only after the records being written finish
+	for range 20 {
+		if err := h.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestConfigHandler_OnReloadMissingFile(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "log.json")
+	writeConfig(t, cfgPath, `{"level": "info"}`, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var mu sync.Mutex
+	var reports []error
+	h, err := NewConfigHandler(cfgPath, &ConfigHandlerOptions{
+		ReloadInterval: 5 * time.Millisecond,
+		OnReload: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewConfigHandler() error = %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	if err := os.Remove(cfgPath); err != nil {
+		t.Fatalf("remove config: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	if len(reports) != 1 || reports[0] == nil {
+		t.Errorf("reports = %v, want the missing file reported once", reports)
+	}
+	mu.Unlock()
+
+	// A manual reload is reported as well
+	if err := h.Reload(); err == nil {
+		t.Fatal("Reload() of a missing file succeeded")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 2 || reports[1] == nil {
+		t.Errorf("reports = %v, want the failed Reload reported", reports)
+	}
+}
+
+func TestConfigHandler_Reload(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.json")
+	logPath := filepath.Join(dir, "app.log")
+	mtime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	writeConfig(t, cfgPath, `{"level": "info", "disable_color": true, "sinks": [{"type": "file", "path": "`+logPath+`"}]}`, mtime)
+
+	reloaded := make(chan error, 1)
+	h, err := NewConfigHandler(cfgPath, &ConfigHandlerOptions{
+		ReloadInterval: 10 * time.Millisecond,
+		OnReload:       func(err error) { reloaded <- err },
+	})
+	if err != nil {
+		t.Fatalf("NewConfigHandler() error = %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	logger := slog.New(h).With("component", "worker")
+	logger.Debug("Hidden")
+	logger.Info("Shown")
+
+	writeConfig(t, cfgPath, `{"level": "debug", "format": "json", "sinks": [{"type": "file", "path": "`+logPath+`"}]}`, mtime.Add(time.Minute))
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("config change was not picked up")
+	}
+	logger.Debug("Now visible")
+
+	// An invalid change keeps the previous configuration
+	writeConfig(t, cfgPath, `{"level": "loud"}`, mtime.Add(2*time.Minute))
+	if err := <-reloaded; err == nil {
+		t.Error("reload of an invalid config succeeded")
+	}
+	logger.Debug("Still visible")
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	got := string(data)
+	if strings.Contains(got, "Hidden") || !strings.Contains(got, "Shown") {
+		t.Errorf("log = %q, want only Info before the reload", got)
+	}
+	if !strings.Contains(got, `"msg":"Now visible","component":"worker"`) || !strings.Contains(got, "Still visible") {
+		t.Errorf("log = %q, want JSON debug records with the derived attrs after the reload", got)
+	}
+}
@@ -0,0 +1,41 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCSVHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewCSVHandler(buf, CSVOptions{Keys: []string{"user", "req.id", "missing"}})
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logger := slog.New(h).With("user", "ann")
+	r := slog.NewRecord(ts, slog.LevelWarn, `Say "hi", then leave`, 0)
+	r.AddAttrs(slog.Group("req", slog.Int("id", 7), slog.String("path", "/")), slog.Bool("dropped", true))
+	if err := logger.Handler().Handle(t.Context(), r); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	logger.WithGroup("req").Debug("Hidden")
+
+	want := "time,level,msg,user,req.id,missing\n" +
+		`2024-01-02T03:04:05Z,WARN,"Say ""hi"", then leave",ann,7,` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSVHandler_TSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewCSVHandler(buf, CSVOptions{Comma: '\t', NoHeader: true, Keys: []string{"req.id"}, Level: slog.LevelDebug})
+
+	r := slog.NewRecord(time.Time{}, slog.LevelDebug, "Cached", 0)
+	r.AddAttrs(slog.Int("id", 9))
+	_ = h.WithGroup("req").Handle(t.Context(), r)
+
+	if got, want := buf.String(), "\tDEBUG\tCached\t9\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDedupTimeout is how long a burst of repeats may pause before its
+// summary is emitted.
+const defaultDedupTimeout = 5 * time.Second
+
+// DedupOptions configures a DedupHandler.
+type DedupOptions struct {
+	// Timeout is how long after the last repeat the burst is considered
+	// over and its summary emitted, even if no other record arrives.
+	// Default: 5s
+	Timeout time.Duration
+}
+
+// DedupHandler is a slog.Handler that collapses identical consecutive
+// records, the classic syslog "last message repeated" behavior. The first
+// record is passed through immediately; its repeats are held back and
+// summarized by a single record annotated "(repeated 57× over 3s)" once a
+// different record arrives, Timeout elapses or the handler is closed.
+//
+// Records are identical if they have the same level, message and
+// attributes, including those added with Logger.With.
+type DedupHandler struct {
+	h      slog.Handler
+	prefix string // fingerprint of the handler's attributes and groups
+	state  *dedupState
+}
+
+// dedupState is shared by a DedupHandler and every handler derived from it,
+// so repeats are detected across loggers writing to the same destination.
+type dedupState struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending *dedupBurst
+	timer   *time.Timer
+}
+
+// dedupBurst is the last emitted record and its held-back repeats.
+type dedupBurst struct {
+	key   string
+	ctx   context.Context
+	h     slog.Handler
+	last  slog.Record
+	first time.Time
+	count int
+}
+
+// NewDedupHandler returns a handler that passes records to h, collapsing
+// consecutive repeats.
+func NewDedupHandler(h slog.Handler, opts *DedupOptions) *DedupHandler {
+	timeout := defaultDedupTimeout
+	if opts != nil && opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	return &DedupHandler{h: h, state: &dedupState{timeout: timeout}}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (d *DedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return d.h.Enabled(ctx, level)
+}
+
+// Handle passes the record through unless it repeats the previous one.
+func (d *DedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	key := d.fingerprint(r)
+
+	s := d.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p := s.pending; p != nil && p.key == key {
+		p.last = r.Clone()
+		p.count++
+		s.resetTimer()
+		return nil
+	}
+
+	err := s.flushLocked()
+	s.pending = &dedupBurst{
+		key:   key,
+		ctx:   context.WithoutCancel(ctx),
+		h:     d.h,
+		first: r.Time,
+	}
+	return errors.Join(err, d.h.Handle(ctx, r))
+}
+
+// WithAttrs returns a new DedupHandler sharing the repeat state, whose
+// records include the given attributes.
+func (d *DedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var sb strings.Builder
+	sb.WriteString(d.prefix)
+	for _, a := range attrs {
+		sb.WriteString(a.String())
+		sb.WriteByte(' ')
+	}
+	return &DedupHandler{h: d.h.WithAttrs(attrs), prefix: sb.String(), state: d.state}
+}
+
+// WithGroup returns a new DedupHandler sharing the repeat state, whose
+// records use the given group.
+func (d *DedupHandler) WithGroup(name string) slog.Handler {
+	return &DedupHandler{h: d.h.WithGroup(name), prefix: d.prefix + name + ".", state: d.state}
+}
+
+// Flush emits the summary of the current burst of repeats, if any.
+func (d *DedupHandler) Flush() error {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	err := d.state.flushLocked()
+	d.state.pending = nil
+	return err
+}
+
+// Close emits any pending summary and stops the timeout timer.
+func (d *DedupHandler) Close() error {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	if d.state.timer != nil {
+		d.state.timer.Stop()
+	}
+	err := d.state.flushLocked()
+	d.state.pending = nil
+	return err
+}
+
+// fingerprint identifies r together with the handler's attributes.
+func (d *DedupHandler) fingerprint(r slog.Record) string {
+	var sb strings.Builder
+	sb.WriteString(r.Level.String())
+	sb.WriteByte(' ')
+	sb.WriteString(r.Message)
+	sb.WriteByte(' ')
+	sb.WriteString(d.prefix)
+	r.Attrs(func(a slog.Attr) bool {
+		sb.WriteString(a.String())
+		sb.WriteByte(' ')
+		return true
+	})
+	return sb.String()
+}
+
+// flushLocked emits the summary of the pending burst if it had repeats.
+// The burst stays pending, so later repeats start a new summary.
+// It must be called with s.mu held.
+func (s *dedupState) flushLocked() error {
+	p := s.pending
+	if p == nil || p.count == 0 {
+		return nil
+	}
+	r := p.last
+	r.Message = fmt.Sprintf("%s (repeated %d× over %s)", r.Message, p.count, formatRepeatSpan(r.Time.Sub(p.first)))
+	p.first = p.last.Time
+	p.count = 0
+	return p.h.Handle(p.ctx, r)
+}
+
+// resetTimer schedules a flush once the burst has been quiet for the
+// timeout. It must be called with s.mu held.
+func (s *dedupState) resetTimer() {
+	if s.timer != nil {
+		s.timer.Reset(s.timeout)
+		return
+	}
+	s.timer = time.AfterFunc(s.timeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = s.flushLocked()
+	})
+}
+
+// formatRepeatSpan rounds the duration of a burst for display.
+func formatRepeatSpan(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Second).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.String()
+	}
+}
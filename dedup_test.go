@@ -0,0 +1,91 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for use from background goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDedupHandler_CollapsesRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewDedupHandler(NewHandler(&buf, &Options{DisableColor: true, MessageWidth: 60}), nil)
+	logger := slog.New(h).With("db", "primary")
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 58 {
+		r := slog.NewRecord(start.Add(time.Duration(i)*50*time.Millisecond), slog.LevelWarn, "Connection refused", 0)
+		_ = logger.Handler().Handle(t.Context(), r)
+	}
+	logger.Info("Recovered")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[1], "Connection refused (repeated 57× over 3s)") || !strings.Contains(lines[1], "db=primary") {
+		t.Errorf("summary line = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "Recovered") {
+		t.Errorf("last line = %q, want Recovered", lines[2])
+	}
+}
+
+func TestDedupHandler_DifferentAttrsAreNotRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewDedupHandler(NewHandler(&buf, &Options{DisableColor: true}), nil))
+
+	logger.Info("Request", "id", 1)
+	logger.Info("Request", "id", 2)
+	logger.With("id", 3).Info("Request")
+
+	if got := strings.Count(buf.String(), "Request"); got != 3 {
+		t.Errorf("emitted %d records, want 3:\n%s", got, buf.String())
+	}
+}
+
+func TestDedupHandler_TimeoutAndClose(t *testing.T) {
+	var buf syncBuffer
+	h := NewDedupHandler(NewHandler(&buf, &Options{DisableColor: true}), &DedupOptions{Timeout: 20 * time.Millisecond})
+	logger := slog.New(h)
+
+	logger.Info("Tick")
+	logger.Info("Tick")
+	logger.Info("Tick")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "repeated 2×") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(buf.String(), "repeated 2×") {
+		t.Fatalf("output = %q, want summary after timeout", buf.String())
+	}
+
+	logger.Info("Tick")
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "repeated 1×") {
+		t.Errorf("output = %q, want summary on Close", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package humanlog
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Defaults for the log file of NewDualLogger.
+const (
+	dualFileMaxSize    = 10 << 20
+	dualFileMaxBackups = 5
+	dualFileTimeFormat = "2006-01-02 15:04:05.000"
+)
+
+// DualLogger is an slog.Logger writing concise records to the console and
+// every record, in full, to a rotating file: the usual setup of command
+// line tools, whose users want to see problems but not the chatter that
+// is still needed to debug a run afterwards.
+type DualLogger struct {
+	*slog.Logger
+	file     *RotatingWriter
+	fileSink *Handler
+}
+
+// NewDualLogger creates a DualLogger that writes records at or above
+// consoleLevel, typically slog.LevelWarn, to standard error without source
+// locations, and records at every level down to LevelTrace to filePath,
+// with dated millisecond timestamps, source locations and no color. The
+// file is rotated at 10 MiB, keeping five compressed backups. Call Close
+// before exiting.
+//
+// Example:
+//
+//	logger, err := humanlog.NewDualLogger(slog.LevelWarn, "logs/tool.log")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer logger.Close()
+//	slog.SetDefault(logger.Logger)
+func NewDualLogger(consoleLevel slog.Level, filePath string) (*DualLogger, error) {
+	return newDualLogger(os.Stderr, consoleLevel, filePath)
+}
+
+func newDualLogger(console io.Writer, consoleLevel slog.Level, filePath string) (*DualLogger, error) {
+	file, err := NewRotatingWriter(RotateOptions{
+		Filename:   filePath,
+		MaxSize:    dualFileMaxSize,
+		MaxBackups: dualFileMaxBackups,
+		Compress:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	consoleOpts := DefaultOptions()
+	consoleOpts.Level = consoleLevel
+	consoleOpts.AddSource = false
+
+	fileOpts := DefaultOptions()
+	fileOpts.Level = LevelTrace
+	fileOpts.TimeFormat = dualFileTimeFormat
+	fileOpts.DisableColor = true
+
+	fileSink := NewHandler(file, fileOpts)
+	h := NewTeeHandler(NewHandler(console, consoleOpts), fileSink)
+	return &DualLogger{Logger: slog.New(h), file: file, fileSink: fileSink}, nil
+}
+
+// Close syncs and closes the log file. Records logged afterwards reach
+// only the console.
+func (d *DualLogger) Close() error {
+	return errors.Join(d.fileSink.Close(), d.file.Close())
+}
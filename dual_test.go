@@ -0,0 +1,63 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDualLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "tool.log")
+	var console bytes.Buffer
+	logger, err := newDualLogger(&console, slog.LevelWarn, path)
+	if err != nil {
+		t.Fatalf("newDualLogger() error = %v", err)
+	}
+
+	logger.Debug("Resolving config", "path", "/etc/tool.conf")
+	logger.Info("Fetched", "items", 12)
+	logger.Warn("Retrying", "attempt", 2)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	logger.Error("After close")
+
+	out := console.String()
+	if strings.Contains(out, "Resolving config") || strings.Contains(out, "Fetched") {
+		t.Errorf("console = %q, want only WARN and above", out)
+	}
+	if !strings.Contains(out, "Retrying") || !strings.Contains(out, "After close") {
+		t.Errorf("console = %q, want WARN and ERROR records", out)
+	}
+	if strings.Contains(out, "dual_test.go") {
+		t.Errorf("console = %q, want no source locations", out)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := string(data)
+	for _, want := range []string{"Resolving config", "path=/etc/tool.conf", "items=12", "Retrying", "dual_test.go"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("file = %q, want it to contain %q", file, want)
+		}
+	}
+	if strings.Contains(file, "\x1b[") || strings.Contains(file, "After close") {
+		t.Errorf("file = %q, want no color and nothing after Close", file)
+	}
+}
+
+func TestNewDualLogger_BadPath(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDualLogger(slog.LevelWarn, filepath.Join(blocker, "tool.log")); err == nil {
+		t.Error("NewDualLogger() error = nil, want error for a path under a file")
+	}
+}
@@ -0,0 +1,166 @@
+package humanlog
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// EncryptionKeySize is the length in bytes of the AES-256 keys used by
+// EncryptedWriter.
+const EncryptionKeySize = 32
+
+// encryptedFrameMagic starts every frame written by an EncryptedWriter.
+const encryptedFrameMagic = "HLE1"
+
+// maxEncryptedFrame is the largest plaintext sealed in one frame; longer
+// writes are split.
+const maxEncryptedFrame = 1 << 20
+
+// EncryptedWriter is an io.Writer that encrypts everything written to it
+// with AES-256-GCM before passing it on, so logs at rest on shared hosts
+// do not leak their contents. Each Write becomes one or more
+// self-contained frames:
+//
+//	"HLE1" | ciphertext length (uint32, big endian) | nonce (12 bytes) | ciphertext
+//
+// Frames are authenticated individually, so a file can be appended to by
+// several processes and still decrypted with DecryptLogs or
+// "humanlog decrypt". Dropped or reordered frames are not detected. Writes
+// from several goroutines are serialized, one frame each.
+type EncryptedWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	aead cipher.AEAD
+}
+
+// NewEncryptedWriter returns an EncryptedWriter sealing output to w with
+// key, which must be EncryptionKeySize bytes long.
+func NewEncryptedWriter(w io.Writer, key []byte) (*EncryptedWriter, error) {
+	aead, err := newLogAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedWriter{w: w, aead: aead}, nil
+}
+
+// Write encrypts p and writes it as frames, each with a single Write call.
+func (e *EncryptedWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxEncryptedFrame)]
+		if _, err := e.w.Write(e.seal(chunk)); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (e *EncryptedWriter) Close() error {
+	if c, ok := e.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// seal returns the frame holding plaintext.
+func (e *EncryptedWriter) seal(plaintext []byte) []byte {
+	nonceSize := e.aead.NonceSize()
+	headerSize := len(encryptedFrameMagic) + 4 + nonceSize
+	frame := make([]byte, headerSize, headerSize+len(plaintext)+e.aead.Overhead())
+	copy(frame, encryptedFrameMagic)
+	nonce := frame[headerSize-nonceSize : headerSize]
+	_, _ = rand.Read(nonce)
+	frame = e.aead.Seal(frame, nonce, plaintext, nil)
+	binary.BigEndian.PutUint32(frame[len(encryptedFrameMagic):], uint32(len(frame)-headerSize))
+	return frame
+}
+
+// DecryptLogs reads frames written by an EncryptedWriter from src and
+// writes the plaintext to dst. It fails on a frame that is truncated,
+// malformed or was not sealed with key.
+func DecryptLogs(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newLogAEAD(key)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(src)
+	header := make([]byte, len(encryptedFrameMagic)+4+aead.NonceSize())
+	var ciphertext, plaintext []byte
+	for frame := 1; ; frame++ {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("humanlog: read frame %d: %w", frame, err)
+		}
+		if string(header[:len(encryptedFrameMagic)]) != encryptedFrameMagic {
+			return fmt.Errorf("humanlog: frame %d: not an encrypted log frame", frame)
+		}
+		n := binary.BigEndian.Uint32(header[len(encryptedFrameMagic):])
+		if n > maxEncryptedFrame+uint32(aead.Overhead()) {
+			return fmt.Errorf("humanlog: frame %d: length %d exceeds the maximum", frame, n)
+		}
+		if cap(ciphertext) < int(n) {
+			ciphertext = make([]byte, n)
+		}
+		ciphertext = ciphertext[:n]
+		if _, err := io.ReadFull(r, ciphertext); err != nil {
+			return fmt.Errorf("humanlog: read frame %d: %w", frame, err)
+		}
+		nonce := header[len(encryptedFrameMagic)+4:]
+		plaintext, err = aead.Open(plaintext[:0], nonce, ciphertext, nil)
+		if err != nil {
+			return fmt.Errorf("humanlog: frame %d: wrong key or corrupted data", frame)
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+	}
+}
+
+// GenerateEncryptionKey returns a new random key for EncryptedWriter.
+func GenerateEncryptionKey() []byte {
+	key := make([]byte, EncryptionKeySize)
+	_, _ = rand.Read(key)
+	return key
+}
+
+// ParseEncryptionKey decodes a key given as hex or standard base64, as in
+// a key file. Surrounding whitespace is ignored.
+func ParseEncryptionKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil || len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("humanlog: encryption key must be %d bytes in hex or base64", EncryptionKeySize)
+	}
+	return key, nil
+}
+
+func newLogAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("humanlog: encryption key is %d bytes, want %d", len(key), EncryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
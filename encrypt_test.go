@@ -0,0 +1,79 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedWriter_RoundTrip(t *testing.T) {
+	key := GenerateEncryptionKey()
+	var sealed bytes.Buffer
+	w, err := NewEncryptedWriter(&sealed, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedWriter() error = %v", err)
+	}
+
+	logger := slog.New(NewHandler(w, &Options{DisableColor: true}))
+	logger.Info("Card charged", "card", "4111-1111")
+	big := strings.Repeat("x", maxEncryptedFrame+10)
+	if _, err := w.Write([]byte(big)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if bytes.Contains(sealed.Bytes(), []byte("4111")) {
+		t.Fatal("ciphertext contains plaintext")
+	}
+
+	var plain bytes.Buffer
+	if err := DecryptLogs(&plain, &sealed, key); err != nil {
+		t.Fatalf("DecryptLogs() error = %v", err)
+	}
+	got := plain.String()
+	if !strings.Contains(got, "Card charged") || !strings.Contains(got, "card=4111-1111") || !strings.HasSuffix(got, big) {
+		t.Errorf("plaintext = %.200q, want the record and the large write", got)
+	}
+}
+
+func TestDecryptLogs_Errors(t *testing.T) {
+	key := GenerateEncryptionKey()
+	var sealed bytes.Buffer
+	w, _ := NewEncryptedWriter(&sealed, key)
+	_, _ = w.Write([]byte("secret\n"))
+
+	tests := []struct {
+		name  string
+		input []byte
+		key   []byte
+		want  string
+	}{
+		{"wrong key", sealed.Bytes(), GenerateEncryptionKey(), "wrong key"},
+		{"truncated", sealed.Bytes()[:sealed.Len()-1], key, "read frame 1"},
+		{"plain text", []byte("not encrypted at all\n"), key, "not an encrypted log"},
+		{"short key", sealed.Bytes(), key[:16], "want 32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecryptLogs(new(bytes.Buffer), bytes.NewReader(tt.input), tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("DecryptLogs() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key := GenerateEncryptionKey()
+	for _, s := range []string{hex.EncodeToString(key), " " + base64.StdEncoding.EncodeToString(key) + "\n"} {
+		got, err := ParseEncryptionKey(s)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("ParseEncryptionKey(%q) = %x, %v, want %x", s, got, err, key)
+		}
+	}
+	if _, err := ParseEncryptionKey("abcd"); err == nil {
+		t.Error("ParseEncryptionKey(short) error = nil, want an error")
+	}
+}
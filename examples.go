@@ -16,18 +16,26 @@ type HTTPRequest struct {
 	UserAgent  string
 	StatusCode int
 	Duration   time.Duration
+
+	// ResponseSize is the number of response body bytes written.
+	// It is omitted when zero.
+	ResponseSize int64
 }
 
 // LogValue implements slog.LogValuer interface
 func (r HTTPRequest) LogValue() slog.Value {
-	return slog.GroupValue(
+	attrs := []slog.Attr{
 		slog.String("method", r.Method),
 		slog.String("url", r.URL),
 		slog.String("remote_addr", r.RemoteAddr),
 		slog.String("user_agent", r.UserAgent),
 		slog.Int("status_code", r.StatusCode),
 		slog.Duration("duration", r.Duration),
-	)
+	}
+	if r.ResponseSize > 0 {
+		attrs = append(attrs, slog.Int64("response_size", r.ResponseSize))
+	}
+	return slog.GroupValue(attrs...)
 }
 
 // DatabaseQuery implements slog.LogValuer for structured database query logging
@@ -0,0 +1,123 @@
+package humanlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultFailbackInterval is how often a failed-over writer retries the
+// destinations ahead of it.
+const defaultFailbackInterval = 30 * time.Second
+
+// FailoverOptions configures a FailoverWriter.
+type FailoverOptions struct {
+	// Retries is the number of times a failed write is retried on the same
+	// destination before failing over to the next one.
+	Retries int
+
+	// RetryDelay is the pause between retries. It blocks the writer, so
+	// keep it short. Default: no pause
+	RetryDelay time.Duration
+
+	// FailbackInterval is how often the primary, and any other destination
+	// ahead of the active one, is tried again after a failover.
+	// Default: 30s
+	FailbackInterval time.Duration
+}
+
+// FailoverWriter is an io.Writer for services where losing logs is
+// unacceptable. It writes to the first of its destinations, e.g. a file or
+// NetworkWriter, and when a write keeps failing moves on to the next one,
+// e.g. os.Stderr or a local spool file. Once the primary works again the
+// writer fails back to it. It is safe for concurrent use.
+type FailoverWriter struct {
+	opts    FailoverOptions
+	writers []io.Writer
+	now     func() time.Time
+
+	mu        sync.Mutex
+	active    int
+	nextProbe time.Time
+}
+
+// NewFailoverWriter creates a FailoverWriter trying writers in order.
+// It panics if no writers are given.
+func NewFailoverWriter(opts FailoverOptions, writers ...io.Writer) *FailoverWriter {
+	if len(writers) == 0 {
+		panic("humanlog: failover writer needs at least one writer")
+	}
+	if opts.FailbackInterval <= 0 {
+		opts.FailbackInterval = defaultFailbackInterval
+	}
+	return &FailoverWriter{opts: opts, writers: writers, now: time.Now}
+}
+
+// Write writes p to the active destination, failing over to the next one
+// if it fails after the configured retries. It returns an error only if
+// every destination from the active one onwards failed.
+func (f *FailoverWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	start := f.active
+	probing := f.active > 0 && !f.now().Before(f.nextProbe)
+	if probing {
+		start = 0
+	}
+
+	var errs []error
+	for i := start; i < len(f.writers); i++ {
+		err := f.writeTo(f.writers[i], p)
+		if err == nil {
+			if i != f.active || probing {
+				f.switchTo(i)
+			}
+			return len(p), nil
+		}
+		errs = append(errs, fmt.Errorf("writer %d: %w", i, err))
+	}
+
+	if probing {
+		f.switchTo(f.active)
+	}
+	return 0, errors.Join(errs...)
+}
+
+// Active returns the index of the destination currently written to,
+// 0 being the primary.
+func (f *FailoverWriter) Active() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// writeTo writes p to w, retrying as configured.
+func (f *FailoverWriter) writeTo(w io.Writer, p []byte) error {
+	var err error
+	for attempt := 0; attempt <= f.opts.Retries; attempt++ {
+		if attempt > 0 && f.opts.RetryDelay > 0 {
+			time.Sleep(f.opts.RetryDelay)
+		}
+		var n int
+		n, err = w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// switchTo makes writer i active and schedules the next fail-back probe.
+// It must be called with f.mu held.
+func (f *FailoverWriter) switchTo(i int) {
+	f.active = i
+	if i > 0 {
+		f.nextProbe = f.now().Add(f.opts.FailbackInterval)
+	}
+}
@@ -0,0 +1,63 @@
+package humanlog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// toggleWriter fails while down is set.
+type toggleWriter struct {
+	bytes.Buffer
+	down  bool
+	calls int
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	w.calls++
+	if w.down {
+		return 0, errors.New("unavailable")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFailoverWriter(t *testing.T) {
+	primary := &toggleWriter{}
+	secondary := &toggleWriter{}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w := NewFailoverWriter(FailoverOptions{Retries: 2, FailbackInterval: time.Minute}, primary, secondary)
+	w.now = func() time.Time { return now }
+
+	_, _ = w.Write([]byte("one\n"))
+	primary.down = true
+	if _, err := w.Write([]byte("two\n")); err != nil {
+		t.Fatalf("Write() error = %v, want failover to succeed", err)
+	}
+	if primary.calls != 4 {
+		t.Errorf("primary called %d times, want 1 success plus 3 failed attempts", primary.calls)
+	}
+	if w.Active() != 1 || secondary.String() != "two\n" {
+		t.Errorf("Active() = %d, secondary = %q; want failover to secondary", w.Active(), secondary.String())
+	}
+
+	// The primary is not retried until the fail-back interval elapses
+	primary.down = false
+	_, _ = w.Write([]byte("three\n"))
+	if w.Active() != 1 || primary.String() != "one\n" {
+		t.Errorf("Active() = %d, primary = %q; want to stay on secondary", w.Active(), primary.String())
+	}
+
+	now = now.Add(time.Minute)
+	_, _ = w.Write([]byte("four\n"))
+	if w.Active() != 0 || primary.String() != "one\nfour\n" {
+		t.Errorf("Active() = %d, primary = %q; want fail-back to primary", w.Active(), primary.String())
+	}
+
+	// Every destination failing is reported
+	primary.down, secondary.down = true, true
+	if _, err := w.Write([]byte("five\n")); err == nil {
+		t.Error("Write() error = nil, want error when every writer fails")
+	}
+}
@@ -0,0 +1,112 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+)
+
+// FilterFunc reports whether a record should be logged.
+type FilterFunc func(ctx context.Context, r slog.Record) bool
+
+// FilterHandler is a slog.Handler that drops records for which its
+// predicate returns false, e.g. to temporarily narrow console output to a
+// single tenant while debugging in production.
+//
+// The record passed to the predicate also carries the attributes added with
+// Logger.With, so filters match them the same way as per-record attributes.
+type FilterHandler struct {
+	h      slog.Handler
+	keep   FilterFunc
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewFilterHandler returns a handler that passes records to h only if keep
+// returns true for them.
+//
+// Example:
+//
+//	h := humanlog.NewFilterHandler(base, humanlog.AttrEquals("tenant", "acme"))
+func NewFilterHandler(h slog.Handler, keep FilterFunc) *FilterHandler {
+	return &FilterHandler{h: h, keep: keep}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (f *FilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return f.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler if the predicate keeps it.
+func (f *FilterHandler) Handle(ctx context.Context, r slog.Record) error {
+	view := r
+	if len(f.attrs) > 0 {
+		view = slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		view.AddAttrs(f.attrs...)
+		r.Attrs(func(a slog.Attr) bool {
+			view.AddAttrs(groupAttr(f.groups, a))
+			return true
+		})
+	}
+	if !f.keep(ctx, view) {
+		return nil
+	}
+	return f.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new FilterHandler whose records include the given attributes.
+func (f *FilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	f2 := *f
+	f2.h = f.h.WithAttrs(attrs)
+	f2.attrs = append([]slog.Attr{}, f.attrs...)
+	for _, a := range attrs {
+		f2.attrs = append(f2.attrs, groupAttr(f.groups, a))
+	}
+	return &f2
+}
+
+// WithGroup returns a new FilterHandler that qualifies attributes with name.
+func (f *FilterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return f
+	}
+	f2 := *f
+	f2.h = f.h.WithGroup(name)
+	f2.groups = append(append([]string{}, f.groups...), name)
+	return &f2
+}
+
+// groupAttr nests a inside groups, outermost first.
+func groupAttr(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+// AttrEquals returns a FilterFunc keeping records that have an attribute
+// named key whose value formats as value. Attributes inside groups are
+// matched by their dotted name, e.g. "request.tenant".
+func AttrEquals(key, value string) FilterFunc {
+	return func(_ context.Context, r slog.Record) bool {
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			for _, fa := range flattenAttrs(nil, "", a) {
+				if fa.Key == key && fa.Value.String() == value {
+					found = true
+					return false
+				}
+			}
+			return true
+		})
+		return found
+	}
+}
+
+// MessageMatches returns a FilterFunc keeping records whose message
+// matches re.
+func MessageMatches(re *regexp.Regexp) FilterFunc {
+	return func(_ context.Context, r slog.Record) bool {
+		return re.MatchString(r.Message)
+	}
+}
@@ -0,0 +1,70 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFilterHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		keep FilterFunc
+		log  func(l *slog.Logger)
+		want []string
+		drop []string
+	}{
+		{
+			name: "record attribute",
+			keep: AttrEquals("tenant", "acme"),
+			log: func(l *slog.Logger) {
+				l.Info("Acme request", "tenant", "acme")
+				l.Info("Other request", "tenant", "globex")
+			},
+			want: []string{"Acme request"},
+			drop: []string{"Other request"},
+		},
+		{
+			name: "logger attribute inside group",
+			keep: AttrEquals("req.tenant", "acme"),
+			log: func(l *slog.Logger) {
+				l.WithGroup("req").With("tenant", "acme").Info("Grouped acme")
+				l.WithGroup("req").Info("Grouped other", "tenant", "globex")
+			},
+			want: []string{"Grouped acme"},
+			drop: []string{"Grouped other"},
+		},
+		{
+			name: "message regex",
+			keep: MessageMatches(regexp.MustCompile(`^cache (hit|miss)`)),
+			log: func(l *slog.Logger) {
+				l.Info("cache miss", "key", "a")
+				l.Info("request served")
+			},
+			want: []string{"cache miss"},
+			drop: []string{"request served"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := NewFilterHandler(NewHandler(&buf, &Options{DisableColor: true}), tt.keep)
+			tt.log(slog.New(h))
+
+			got := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("output = %q, should contain %q", got, s)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(got, s) {
+					t.Errorf("output = %q, should not contain %q", got, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package humanlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrorGroup aggregates error records that share a fingerprint.
+type ErrorGroup struct {
+	// Fingerprint identifies the group.
+	Fingerprint string `json:"fingerprint"`
+
+	// Template is the message with variable parts such as numbers, IDs and
+	// quoted strings replaced by placeholders.
+	Template string `json:"template"`
+
+	// ErrorType is the Go type of the first error attribute, if any.
+	ErrorType string `json:"error_type,omitempty"`
+
+	// Location is the function and line that logged the record, if known.
+	Location string `json:"location,omitempty"`
+
+	// Count is the total number of records in the group.
+	Count uint64 `json:"count"`
+
+	// First and Last are the times of the first and most recent record.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+
+	// Sample is the message of the most recent record.
+	Sample string `json:"sample"`
+
+	recent uint64 // records since the last summary
+}
+
+// ErrorAggregatorOptions configures an ErrorAggregator.
+type ErrorAggregatorOptions struct {
+	// Level is the minimum level fingerprinted. Default: slog.LevelError
+	Level slog.Leveler
+
+	// Interval is how often a summary of the groups seen since the last one
+	// is logged through the wrapped handler. Zero disables summaries.
+	Interval time.Duration
+
+	// SuppressRepeats passes only the first record of each group per
+	// Interval through; the rest are only counted. It requires Interval.
+	SuppressRepeats bool
+}
+
+// ErrorAggregator is a slog.Handler that fingerprints error records by
+// message template, error type and logging location, and counts them. It
+// can periodically log a summary per group instead of every repeat, and
+// serves the table over HTTP.
+type ErrorAggregator struct {
+	h     slog.Handler
+	state *aggregatorState
+}
+
+// aggregatorState is shared by an ErrorAggregator and every handler
+// derived from it.
+type aggregatorState struct {
+	opts ErrorAggregatorOptions
+	h    slog.Handler // the root handler summaries are logged through
+
+	mu     sync.Mutex
+	groups map[string]*ErrorGroup
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewErrorAggregator returns a handler that passes records to h and
+// aggregates error records. If Interval is set, call Close to stop the
+// background summaries.
+func NewErrorAggregator(h slog.Handler, opts *ErrorAggregatorOptions) *ErrorAggregator {
+	var options ErrorAggregatorOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelError
+	}
+
+	s := &aggregatorState{opts: options, h: h, groups: make(map[string]*ErrorGroup)}
+	if options.Interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.summarizeEvery(options.Interval)
+	}
+	return &ErrorAggregator{h: h, state: s}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (ea *ErrorAggregator) Enabled(ctx context.Context, level slog.Level) bool {
+	return ea.h.Enabled(ctx, level)
+}
+
+// Handle counts error records by fingerprint and passes records through,
+// unless SuppressRepeats holds back a repeat.
+func (ea *ErrorAggregator) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= ea.state.opts.Level.Level() {
+		if first := ea.state.observe(r); !first && ea.state.opts.SuppressRepeats && ea.state.opts.Interval > 0 {
+			return nil
+		}
+	}
+	return ea.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new ErrorAggregator sharing the groups, whose
+// records include the given attributes.
+func (ea *ErrorAggregator) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ErrorAggregator{h: ea.h.WithAttrs(attrs), state: ea.state}
+}
+
+// WithGroup returns a new ErrorAggregator sharing the groups, whose
+// records use the given group.
+func (ea *ErrorAggregator) WithGroup(name string) slog.Handler {
+	return &ErrorAggregator{h: ea.h.WithGroup(name), state: ea.state}
+}
+
+// Groups returns a snapshot of the error groups, most frequent first.
+func (ea *ErrorAggregator) Groups() []ErrorGroup {
+	s := ea.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make([]ErrorGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Fingerprint < groups[j].Fingerprint
+	})
+	return groups
+}
+
+// ServeHTTP writes the error groups as a JSON array, most frequent first.
+func (ea *ErrorAggregator) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ea.Groups())
+}
+
+// Close stops the background summaries and logs a final one.
+func (ea *ErrorAggregator) Close() error {
+	s := ea.state
+	if s.stop == nil {
+		return nil
+	}
+	s.mu.Lock()
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	<-s.done
+	return nil
+}
+
+// observe counts r and reports whether it is the first record of its
+// group since the last summary.
+func (s *aggregatorState) observe(r slog.Record) bool {
+	template := messageTemplate(r.Message)
+	errType := recordErrorType(r)
+	location := recordLocation(r)
+	fp := fingerprint(template, errType, location)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.groups[fp]
+	if g == nil {
+		g = &ErrorGroup{Fingerprint: fp, Template: template, ErrorType: errType, Location: location, First: r.Time}
+		s.groups[fp] = g
+	}
+	g.Count++
+	g.recent++
+	g.Last = r.Time
+	g.Sample = r.Message
+	return g.recent == 1
+}
+
+func (s *aggregatorState) summarizeEvery(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize()
+		case <-s.stop:
+			s.summarize()
+			return
+		}
+	}
+}
+
+// summarize logs one record per group seen since the last summary.
+func (s *aggregatorState) summarize() {
+	s.mu.Lock()
+	var records []slog.Record
+	now := time.Now()
+	for _, g := range s.groups {
+		if g.recent == 0 {
+			continue
+		}
+		r := slog.NewRecord(now, slog.LevelWarn, "Error summary", 0)
+		r.AddAttrs(
+			slog.String("fingerprint", g.Fingerprint),
+			slog.Uint64("count", g.recent),
+			slog.Uint64("total", g.Count),
+			slog.String("template", g.Template),
+		)
+		if g.ErrorType != "" {
+			r.AddAttrs(slog.String("error_type", g.ErrorType))
+		}
+		if g.Location != "" {
+			r.AddAttrs(slog.String("location", g.Location))
+		}
+		records = append(records, r)
+		g.recent = 0
+	}
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	for _, r := range records {
+		if s.h.Enabled(ctx, r.Level) {
+			_ = s.h.Handle(ctx, r)
+		}
+	}
+}
+
+var templatePatterns = []struct {
+	re          *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "<str>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{16,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+}
+
+// messageTemplate replaces the variable parts of a message with placeholders.
+func messageTemplate(msg string) string {
+	for _, p := range templatePatterns {
+		msg = p.re.ReplaceAllString(msg, p.placeholder)
+	}
+	return msg
+}
+
+// recordErrorType returns the type of the first error attribute of r.
+func recordErrorType(r slog.Record) string {
+	var errType string
+	r.Attrs(func(a slog.Attr) bool {
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindAny {
+			return true
+		}
+		if err, ok := v.Any().(error); ok {
+			errType = fmt.Sprintf("%T", err)
+			return false
+		}
+		return true
+	})
+	return errType
+}
+
+// recordLocation returns the function and line that logged r.
+func recordLocation(r slog.Record) string {
+	if r.PC == 0 {
+		return ""
+	}
+	f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	if f.Function == "" {
+		return ""
+	}
+	return f.Function + ":" + strconv.Itoa(f.Line)
+}
+
+// fingerprint hashes the parts identifying an error group.
+func fingerprint(parts ...string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
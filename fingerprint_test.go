@@ -0,0 +1,74 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"user 42 not found", "user <n> not found"},
+		{`open "/tmp/a.txt" failed`, "open <str> failed"},
+		{"order 3f2b8c1e-4a5d-4e6f-8a9b-0c1d2e3f4a5b rejected", "order <uuid> rejected"},
+		{"bad pointer 0xc000123456", "bad pointer <hex>"},
+		{"took 1.5s", "took <n>s"},
+	}
+	for _, tt := range tests {
+		if got := messageTemplate(tt.msg); got != tt.want {
+			t.Errorf("messageTemplate(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestErrorAggregator(t *testing.T) {
+	var buf bytes.Buffer
+	agg := NewErrorAggregator(NewHandler(&buf, &Options{DisableColor: true}), &ErrorAggregatorOptions{
+		Interval:        time.Hour,
+		SuppressRepeats: true,
+	})
+	logger := slog.New(agg)
+
+	for i := range 3 {
+		logger.Error("user "+strings.Repeat("9", i+1)+" not found", "err", errors.New("missing"))
+	}
+	logger.Error("read failed", "err", &fs.PathError{Op: "read", Path: "/x", Err: errors.New("EIO")})
+	logger.Info("Not aggregated")
+
+	groups := agg.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+	}
+	if g := groups[0]; g.Count != 3 || g.Template != "user <n> not found" || g.ErrorType != "*errors.errorString" || g.Sample != "user 999 not found" {
+		t.Errorf("first group = %+v", g)
+	}
+	if g := groups[1]; g.ErrorType != "*fs.PathError" || !strings.Contains(g.Location, "TestErrorAggregator") {
+		t.Errorf("second group = %+v", g)
+	}
+	if got := strings.Count(buf.String(), "not found"); got != 1 {
+		t.Errorf("passed through %d repeats, want only the first", got)
+	}
+
+	rec := httptest.NewRecorder()
+	agg.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errors", nil))
+	var served []ErrorGroup
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil || len(served) != 2 {
+		t.Errorf("served %q, err = %v", rec.Body.String(), err)
+	}
+
+	if err := agg.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Error summary") || !strings.Contains(buf.String(), "count=3") {
+		t.Errorf("output = %q, want a summary with count=3 on Close", buf.String())
+	}
+}
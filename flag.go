@@ -0,0 +1,187 @@
+package humanlog
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// LevelFlag is a slog.Level that implements flag.Value and
+// encoding.TextUnmarshaler, accepting names such as "debug", "WARN",
+// "info+2" or "trace".
+type LevelFlag slog.Level
+
+// String returns the level name.
+func (l *LevelFlag) String() string {
+	return levelString(slog.Level(*l))
+}
+
+// Set parses a level name.
+func (l *LevelFlag) Set(s string) error {
+	var level slog.Level
+	if rest, ok := cutPrefixFold(s, "trace"); ok {
+		offset := 0
+		if rest != "" {
+			n, err := strconv.Atoi(rest)
+			if err != nil || rest[0] != '+' && rest[0] != '-' {
+				return fmt.Errorf("humanlog: invalid level %q", s)
+			}
+			offset = n
+		}
+		level = LevelTrace + slog.Level(offset)
+	} else if err := level.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("humanlog: invalid level %q", s)
+	}
+	*l = LevelFlag(level)
+	return nil
+}
+
+// UnmarshalText parses a level name.
+func (l *LevelFlag) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// MarshalText returns the level name.
+func (l LevelFlag) MarshalText() ([]byte, error) {
+	return []byte(levelString(slog.Level(l))), nil
+}
+
+// levelString is slog.Level.String, except that levels near LevelTrace
+// are named relative to it, e.g. "TRACE" rather than "DEBUG-4".
+func levelString(level slog.Level) string {
+	if level < slog.LevelDebug {
+		if level == LevelTrace {
+			return "TRACE"
+		}
+		return fmt.Sprintf("TRACE%+d", int(level-LevelTrace))
+	}
+	return level.String()
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// Level returns the level, implementing slog.Leveler.
+func (l LevelFlag) Level() slog.Level {
+	return slog.Level(l)
+}
+
+// Format is an output format. It implements flag.Value and
+// encoding.TextUnmarshaler.
+type Format int
+
+const (
+	// FormatHuman is the human-readable format. This is the default.
+	FormatHuman Format = iota
+	// FormatJSON is slog's standard JSON.
+	FormatJSON
+	// FormatGoogleCloud is JSON for Google Cloud Logging.
+	FormatGoogleCloud
+	// FormatDatadog is JSON for Datadog.
+	FormatDatadog
+)
+
+var formatNames = []string{"human", "json", "gcp", "datadog"}
+
+// String returns the format name.
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+// Set parses a format name: "human", "json", "gcp" or "datadog".
+func (f *Format) Set(s string) error {
+	for i, name := range formatNames {
+		if strings.EqualFold(s, name) {
+			*f = Format(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("humanlog: unknown format %q, want one of %s", s, strings.Join(formatNames, ", "))
+}
+
+// UnmarshalText parses a format name.
+func (f *Format) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+// MarshalText returns the format name.
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// apply sets the output fields of opts for the format.
+func (f Format) apply(opts *Options) {
+	switch f {
+	case FormatJSON:
+		opts.UseJSON, opts.JSONFormat = true, JSONStandard
+	case FormatGoogleCloud:
+		opts.UseJSON, opts.JSONFormat = true, JSONGoogleCloud
+	case FormatDatadog:
+		opts.UseJSON, opts.JSONFormat = true, JSONDatadog
+	default:
+		opts.UseJSON = false
+	}
+}
+
+// Flags holds the values of the standard logging flags.
+type Flags struct {
+	Level     LevelFlag
+	Format    Format
+	NoColor   bool
+	AddSource bool
+
+	base Options // the options the flags override
+}
+
+// RegisterFlags registers -log-level, -log-format, -log-no-color and
+// -log-source on fs, defaulting to the given options, or DefaultOptions if
+// opts is nil. Call Flags.NewHandler after fs has been parsed.
+//
+// Example:
+//
+//	logFlags := humanlog.RegisterFlags(flag.CommandLine, nil)
+//	flag.Parse()
+//	slog.SetDefault(slog.New(logFlags.NewHandler(os.Stderr)))
+func RegisterFlags(fs *flag.FlagSet, opts *Options) *Flags {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	f := &Flags{Level: LevelFlag(opts.Level), NoColor: opts.DisableColor, AddSource: opts.AddSource}
+	if opts.UseJSON {
+		f.Format = Format(int(FormatJSON) + int(opts.JSONFormat))
+	}
+	f.base = *opts
+
+	fs.Var(&f.Level, "log-level", "minimum log `level`: debug, info, warn or error")
+	fs.Var(&f.Format, "log-format", "log `format`: human, json, gcp or datadog")
+	fs.BoolVar(&f.NoColor, "log-no-color", f.NoColor, "disable colored log output")
+	fs.BoolVar(&f.AddSource, "log-source", f.AddSource, "add source locations to log records")
+	return f
+}
+
+// Options returns the options passed to RegisterFlags with the flag values
+// applied.
+func (f *Flags) Options() *Options {
+	opts := f.base
+	opts.Level = f.Level.Level()
+	opts.DisableColor = f.NoColor
+	opts.AddSource = f.AddSource
+	f.Format.apply(&opts)
+	return &opts
+}
+
+// NewHandler creates a Handler writing to w configured by the flags.
+func (f *Flags) NewHandler(w io.Writer) *Handler {
+	return NewHandler(w, f.Options())
+}
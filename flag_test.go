@@ -0,0 +1,71 @@
+package humanlog
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	flags := RegisterFlags(fs, &Options{Level: slog.LevelInfo, MessageWidth: 20})
+
+	if err := fs.Parse([]string{"-log-level=debug", "-log-format=json"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	opts := flags.Options()
+	if opts.Level != slog.LevelDebug || !opts.UseJSON || opts.JSONFormat != JSONStandard || opts.MessageWidth != 20 {
+		t.Errorf("Options() = %+v, want debug JSON keeping MessageWidth", opts)
+	}
+
+	var buf bytes.Buffer
+	slog.New(flags.NewHandler(&buf)).Debug("From flags")
+	if !strings.Contains(buf.String(), `"msg":"From flags"`) {
+		t.Errorf("output = %q, want JSON debug record", buf.String())
+	}
+
+	if err := fs.Parse([]string{"-log-format=xml"}); err == nil {
+		t.Error("Parse() accepted an unknown format")
+	}
+}
+
+func TestFormat_Text(t *testing.T) {
+	for _, name := range []string{"human", "json", "gcp", "datadog"} {
+		var f Format
+		if err := f.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+			t.Fatalf("UnmarshalText(%q) error = %v", name, err)
+		}
+		if got, _ := f.MarshalText(); string(got) != name {
+			t.Errorf("MarshalText() = %q, want %q", got, name)
+		}
+	}
+}
+
+func TestLevelFlag_Trace(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+		name string
+	}{
+		{"trace", LevelTrace, "TRACE"},
+		{"TRACE+2", LevelTrace + 2, "TRACE+2"},
+		{"debug", slog.LevelDebug, "DEBUG"},
+		{"warn+1", slog.LevelWarn + 1, "WARN+1"},
+	}
+	for _, tt := range tests {
+		var l LevelFlag
+		if err := l.Set(tt.in); err != nil || l.Level() != tt.want {
+			t.Errorf("Set(%q) = %v, %v, want %v", tt.in, l.Level(), err, tt.want)
+		}
+		if got := l.String(); got != tt.name {
+			t.Errorf("Set(%q).String() = %q, want %q", tt.in, got, tt.name)
+		}
+	}
+	var l LevelFlag
+	if err := l.Set("tracer"); err == nil {
+		t.Error(`Set("tracer") error = nil, want an error`)
+	}
+}
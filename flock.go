@@ -0,0 +1,49 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package humanlog
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// lockedWriter holds an advisory exclusive lock on a file for the
+// duration of each write, so processes sharing the file never interleave
+// their writes.
+type lockedWriter struct {
+	w  io.Writer
+	fd int
+}
+
+// lockingWriter wraps w in a lockedWriter if it is a file, lets a
+// RotatingWriter lock the file it currently writes to, and otherwise
+// returns w unchanged.
+func lockingWriter(w io.Writer) io.Writer {
+	if rw, ok := w.(*RotatingWriter); ok {
+		return lockedRotatingWriter{rw}
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return w
+	}
+	return &lockedWriter{w: w, fd: int(f.Fd())}
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	if err := syscall.Flock(l.fd, syscall.LOCK_EX); err != nil {
+		return 0, err
+	}
+	defer func() { _ = syscall.Flock(l.fd, syscall.LOCK_UN) }()
+	return l.w.Write(p)
+}
+
+// flockWrite writes p to f while holding an advisory exclusive lock on it.
+func flockWrite(f *os.File, p []byte) (int, error) {
+	fd := int(f.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+		return 0, err
+	}
+	defer func() { _ = syscall.Flock(fd, syscall.LOCK_UN) }()
+	return f.Write(p)
+}
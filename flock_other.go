@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package humanlog
+
+import (
+	"io"
+	"os"
+)
+
+// lockingWriter returns w unchanged, as file locking is only supported on
+// platforms with flock.
+func lockingWriter(w io.Writer) io.Writer {
+	return w
+}
+
+// flockWrite writes p to f without locking, as file locking is only
+// supported on platforms with flock.
+func flockWrite(f *os.File, p []byte) (int, error) {
+	return f.Write(p)
+}
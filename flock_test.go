@@ -0,0 +1,51 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package humanlog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandler_LockFileRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingWriter(RotateOptions{Filename: path})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+	logger := slog.New(NewHandler(w, &Options{DisableColor: true, LockFile: true}))
+
+	// Another process holding the lock delays the write until it lets go
+	other, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Close() }()
+	if err := syscall.Flock(int(other.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		logger.Info("Locked")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("record written while another process held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := syscall.Flock(int(other.Fd()), syscall.LOCK_UN); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "Locked") {
+		t.Errorf("file = %q, want the record after the lock was released", data)
+	}
+}
@@ -0,0 +1,67 @@
+package humanlog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// LineFormatter takes complete control of how a record is rendered.
+// Format appends the rendered line to buf and returns the extended buffer.
+// A trailing newline is added if the returned line lacks one.
+//
+// Implementations can use the RecordView helpers to reuse humanlog's level
+// coloring, value quoting and group prefixing, while the Handler continues to
+// manage level filtering and writing.
+type LineFormatter interface {
+	Format(buf []byte, r RecordView) []byte
+}
+
+// LineFormatterFunc adapts an ordinary function to the LineFormatter interface.
+type LineFormatterFunc func(buf []byte, r RecordView) []byte
+
+// Format calls f(buf, r).
+func (f LineFormatterFunc) Format(buf []byte, r RecordView) []byte {
+	return f(buf, r)
+}
+
+// RecordView exposes a record to a LineFormatter together with humanlog's
+// rendering of its parts.
+type RecordView struct {
+	// Record is the record being handled.
+	Record slog.Record
+
+	h *Handler
+}
+
+// Time returns the record timestamp formatted with the handler's
+// TimeFormat and time zone settings.
+func (v RecordView) Time() string {
+	return v.h.formatTime(v.Record.Time)
+}
+
+// Level returns the fixed-width level name, colored unless color is disabled.
+func (v RecordView) Level() string {
+	return formatLevel(v.Record.Level, v.h.opts.levelStyler(), v.h.opts.DisableColor)
+}
+
+// Name returns the handler's logger name, or "" if it has none.
+func (v RecordView) Name() string {
+	return v.h.name
+}
+
+// Message returns the message truncated and padded to the configured width.
+func (v RecordView) Message() string {
+	return v.h.formatMessage(v.Record.Message)
+}
+
+// Attrs returns the handler's and the record's attributes, plus the source
+// location if enabled, each rendered as "key=value" with group prefixes
+// applied and values quoted as needed.
+func (v RecordView) Attrs() []string {
+	return v.h.formatAttrs(v.Record)
+}
+
+// AttrsString returns Attrs joined by single spaces.
+func (v RecordView) AttrsString() string {
+	return strings.Join(v.Attrs(), " ")
+}
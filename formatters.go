@@ -0,0 +1,117 @@
+package humanlog
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+)
+
+// ValueFormatter renders an attribute value as the text printed after "key=".
+type ValueFormatter func(v slog.Value) string
+
+// FormatterRegistry maps attribute keys to ValueFormatters, complementing
+// the type-based formatting with key-based formatting. The zero value is an
+// empty registry ready to use. A registry must not be modified while a
+// handler using it is logging.
+type FormatterRegistry struct {
+	exact map[string]ValueFormatter
+	globs []keyFormatter
+}
+
+type keyFormatter struct {
+	pattern string
+	format  ValueFormatter
+}
+
+// NewFormatterRegistry creates an empty FormatterRegistry.
+func NewFormatterRegistry() *FormatterRegistry {
+	return &FormatterRegistry{}
+}
+
+// Register associates pattern with f and returns the registry for chaining.
+//
+// A pattern is matched against both the group-qualified key (e.g.
+// "request.bytes") and the bare key ("bytes"). Patterns may use the glob
+// syntax of path.Match, e.g. "*_ns" or "request.*". Exact patterns take
+// precedence over globs; globs are tried in registration order.
+func (r *FormatterRegistry) Register(pattern string, f ValueFormatter) *FormatterRegistry {
+	if strings.ContainsAny(pattern, "*?[\\") {
+		r.globs = append(r.globs, keyFormatter{pattern: pattern, format: f})
+		return r
+	}
+	if r.exact == nil {
+		r.exact = make(map[string]ValueFormatter)
+	}
+	r.exact[pattern] = f
+	return r
+}
+
+// lookup returns the formatter registered for the qualified key, or nil.
+func (r *FormatterRegistry) lookup(key string) ValueFormatter {
+	if r == nil {
+		return nil
+	}
+	leaf := key
+	if i := strings.LastIndexByte(key, '.'); i != -1 {
+		leaf = key[i+1:]
+	}
+
+	if f, ok := r.exact[key]; ok {
+		return f
+	}
+	if f, ok := r.exact[leaf]; ok {
+		return f
+	}
+	for _, g := range r.globs {
+		if ok, _ := path.Match(g.pattern, key); ok {
+			return g.format
+		}
+		if ok, _ := path.Match(g.pattern, leaf); ok {
+			return g.format
+		}
+	}
+	return nil
+}
+
+// FormatByteSize renders an integer value as a human-readable IEC byte
+// size, e.g. 1536 as "1.5KiB".
+func FormatByteSize(v slog.Value) string {
+	var n float64
+	switch v.Kind() {
+	case slog.KindInt64:
+		n = float64(v.Int64())
+	case slog.KindUint64:
+		n = float64(v.Uint64())
+	case slog.KindFloat64:
+		n = v.Float64()
+	default:
+		return v.String()
+	}
+
+	const unit = 1024
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for (n >= unit || n <= -unit) && i < len(units)-1 {
+		n /= unit
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%g%s", n, units[i])
+	}
+	return fmt.Sprintf("%.1f%s", n, units[i])
+}
+
+// FormatNanoseconds renders an integer number of nanoseconds as a duration,
+// e.g. 1500000 as "1.5ms".
+func FormatNanoseconds(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return time.Duration(v.Int64()).String()
+	case slog.KindUint64:
+		return time.Duration(v.Uint64()).String()
+	default:
+		return v.String()
+	}
+}
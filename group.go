@@ -0,0 +1,163 @@
+package humanlog
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupStyle controls how attribute groups are rendered.
+type GroupStyle int
+
+const (
+	// GroupDotted flattens groups into dotted keys, e.g.
+	// request.method=GET request.path=/x. This is the default.
+	GroupDotted GroupStyle = iota
+	// GroupBracketed renders groups inline with their members in braces,
+	// e.g. request{method=GET path=/x}.
+	GroupBracketed
+	// GroupNested renders the attributes below the message, one per line,
+	// with the members of each group indented under its name. It applies
+	// to the standard line layout; column layouts and LineFormatter render
+	// groups dotted.
+	GroupNested
+)
+
+// nestedIndent is the indentation per level of the nested layout.
+const nestedIndent = "    "
+
+// flattenAttrs appends a to dst, with groups flattened into dotted keys
+// qualified by prefix, for sinks that only take flat key/value pairs.
+func flattenAttrs(dst []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	v := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+	if v.Kind() == slog.KindGroup {
+		for _, ga := range v.Group() {
+			dst = flattenAttrs(dst, key, ga)
+		}
+		return dst
+	}
+	return append(dst, slog.Attr{Key: key, Value: v})
+}
+
+// flatValue renders a flattened attribute value as plain text, with times
+// in RFC 3339 and errors as their message.
+func flatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.String()
+}
+
+// wrapGroups nests attrs in the named groups, outermost first.
+func wrapGroups(attrs []slog.Attr, groups []string) []slog.Attr {
+	for i := len(groups) - 1; i >= 0 && len(attrs) > 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// attrTree returns the handler's attributes followed by the record's,
+// wrapped in the handler's open groups. Unless groups are dotted, groups
+// with the same key are merged, so attributes added with WithAttrs and
+// in the record share one group.
+func (h *Handler) attrTree(r slog.Record) []slog.Attr {
+	recAttrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		recAttrs = append(recAttrs, a)
+		return true
+	})
+
+	attrs := append(append(make([]slog.Attr, 0, len(h.attrs)+1), h.attrs...), wrapGroups(recAttrs, h.groups)...)
+	if h.opts.GroupStyle != GroupDotted {
+		attrs = mergeGroups(attrs)
+	}
+	return attrs
+}
+
+// mergeGroups merges the members of groups with the same key into the
+// first of them, recursively.
+func mergeGroups(attrs []slog.Attr) []slog.Attr {
+	merged := make([]slog.Attr, 0, len(attrs))
+	index := map[string]int{}
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup || a.Key == "" {
+			merged = append(merged, a)
+			continue
+		}
+		if i, ok := index[a.Key]; ok {
+			members := append(merged[i].Value.Group(), a.Value.Group()...)
+			merged[i].Value = slog.GroupValue(members...)
+			continue
+		}
+		index[a.Key] = len(merged)
+		merged = append(merged, a)
+	}
+	for i, a := range merged {
+		if a.Value.Kind() == slog.KindGroup {
+			merged[i].Value = slog.GroupValue(mergeGroups(a.Value.Group())...)
+		}
+	}
+	return merged
+}
+
+// appendNested appends the attributes as lines of the nested layout at
+// the given depth. Group names end with a colon and their members follow
+// one level deeper; path is the dotted key of the enclosing group, used
+// to look up formatters.
+func (h *Handler) appendNested(sb *strings.Builder, attrs []slog.Attr, path string, depth int) {
+	if h.opts.Deterministic {
+		attrs = append([]slog.Attr(nil), attrs...)
+		sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	}
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		key := a.Key
+		if path != "" {
+			key = path + "." + a.Key
+		}
+
+		if a.Value.Kind() == slog.KindGroup {
+			if a.Key == "" {
+				h.appendNested(sb, a.Value.Group(), path, depth)
+				continue
+			}
+			if len(a.Value.Group()) == 0 {
+				continue
+			}
+			sb.WriteString(strings.Repeat(nestedIndent, depth))
+			sb.WriteString(sanitize(a.Key, h.opts.ControlChars))
+			sb.WriteString(":\n")
+			h.appendNested(sb, a.Value.Group(), key, depth+1)
+			continue
+		}
+		if h.opts.TableValues {
+			if t, ok := tableOf(key, a.Value, h.maxTableRows()); ok {
+				h.appendTable(sb, t, a.Key, depth)
+				continue
+			}
+		}
+		sb.WriteString(strings.Repeat(nestedIndent, depth))
+		sb.WriteString(formatKey(a.Key, &h.opts))
+		sb.WriteString(formatValue(key, a.Value, &h.opts))
+		sb.WriteByte('\n')
+	}
+}
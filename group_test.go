@@ -0,0 +1,48 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestGroupStyles(t *testing.T) {
+	log := func(style GroupStyle) string {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &Options{
+			GroupStyle:    style,
+			Deterministic: true,
+			TimeFormat:    TimeFormatSeconds,
+			MessageWidth:  6,
+			Formatters:    NewFormatterRegistry().Register("request.bytes", func(v slog.Value) string { return v.String() + "B" }),
+		}))
+		logger.With("user", "ana").WithGroup("request").With("method", "GET").Info("Served",
+			"bytes", 512,
+			slog.Group("client", "ip", "10.0.0.1", slog.Group("geo", "country", "FI")),
+			slog.Group("empty"),
+		)
+		return buf.String()
+	}
+
+	tests := []struct {
+		style GroupStyle
+		want  string
+	}{
+		{GroupDotted, "[00:00:00] INFO  Served request.bytes=512B request.client.geo.country=FI request.client.ip=10.0.0.1 request.method=GET user=ana\n"},
+		{GroupBracketed, "[00:00:00] INFO  Served request{method=GET bytes=512B client{ip=10.0.0.1 geo{country=FI}}} user=ana\n"},
+		{GroupNested, "[00:00:00] INFO  Served\n" +
+			"    request:\n" +
+			"        bytes=512B\n" +
+			"        client:\n" +
+			"            geo:\n" +
+			"                country=FI\n" +
+			"            ip=10.0.0.1\n" +
+			"        method=GET\n" +
+			"    user=ana\n"},
+	}
+	for _, tt := range tests {
+		if got := log(tt.style); got != tt.want {
+			t.Errorf("style %d output =\n%s\nwant\n%s", tt.style, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,22 @@
 package humanlog
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+	"unicode/utf8"
 )
 
 // Constants for formatting
@@ -17,6 +24,12 @@ const (
 	messageWidth = 40 // Fixed width for message field
 )
 
+// defaultFlushInterval is how often buffered output is flushed.
+const defaultFlushInterval = time.Second
+
+// lineNumberWidth is the minimum width of the Options.LineNumbers gutter.
+const lineNumberWidth = 5
+
 // ANSI color codes
 const (
 	colorReset  = "\033[0m"
@@ -28,16 +41,349 @@ const (
 
 // Handler implements slog.Handler for human-readable logging output.
 type Handler struct {
-	h      slog.Handler
-	opts   Options
-	mu     sync.Mutex
-	attrs  []slog.Attr
-	groups []string
+	h       slog.Handler // JSON handler, nil unless UseJSON is set
+	opts    Options
+	out     *output
+	metrics *Metrics
+	start   time.Time
+	name    string // logger name, see WithName
+	attrs   []slog.Attr
+	groups  []string
+
+	// prefix is the groups joined by dots, the key prefix of record
+	// attributes in the inline layout.
+	prefix string
+
+	// times caches the formatted timestamp; it is shared by derived
+	// handlers and nil if the layout cannot be cached.
+	times *timeCache
+
+	// preformatted is attrs rendered as " key=value" pairs for the inline
+	// layout when the handler is derived, so records do not format them
+	// again. It is nil for layouts that render attrs per record.
+	preformatted []byte
+}
+
+// output is the destination shared by a handler and every handler derived
+// from it. Each output owns its own lock, so handlers writing to different
+// writers never contend with each other. Records are formatted into
+// private buffers before the lock is taken, so it only serializes the
+// writes themselves.
+type output struct {
+	mu   sync.Mutex
+	w    io.Writer
+	dst  io.Writer // w, or w locked around each write for LockFile
+	last time.Time // time of the previous record, for TimeDelta
+
+	lastTimeStr string // formatted timestamp of the previous record
+
+	bw   *bufio.Writer // non-nil while output is buffered
+	stop chan struct{} // closed to stop the background flusher
+
+	metrics  *Metrics
+	onError  func(error)
+	fallback io.Writer
+
+	maxRecord  int // Options.MaxRecordBytes, applied to JSON records
+	syncPolicy SyncPolicy
+
+	// jsonMu serializes JSON records whose length the After hook needs;
+	// jsonWritten is the length of the last one written by Write
+	jsonMu      sync.Mutex
+	jsonWritten int
+
+	seq atomic.Uint64 // last sequence number, for AddSequence
+
+	summary     *levelSummary // non-nil if summary lines are enabled
+	summaryStop chan struct{} // closed to stop the summary ticker
+
+	idleGap    time.Duration // Options.IdleSeparator
+	idleStyle  string
+	lastRecord time.Time // time of the previous record, for IdleSeparator
+
+	numbered    bool // Options.LineNumbers
+	numberStyle string
+	lineNo      uint64 // number of the previous record
+}
+
+// newOutput creates the output for w, buffering it and starting a
+// background flusher if opts.BufferSize is set.
+func newOutput(w io.Writer, opts *Options) *output {
+	o := &output{w: w, dst: w, metrics: opts.Metrics, onError: opts.OnError, fallback: opts.FallbackWriter, maxRecord: opts.MaxRecordBytes, syncPolicy: opts.Sync}
+	if opts.LockFile {
+		o.dst = lockingWriter(w)
+	}
+	if !opts.UseJSON && opts.LineNumbers {
+		o.numbered, o.numberStyle = true, opts.style(StyleDim)
+	}
+	if !opts.UseJSON && opts.IdleSeparator > 0 {
+		o.idleGap, o.idleStyle = opts.IdleSeparator, opts.style(StyleDim)
+	}
+	if !opts.UseJSON && (opts.SummaryInterval > 0 || opts.SummaryEvery > 0) {
+		o.summary = newLevelSummary(opts)
+		if opts.SummaryInterval > 0 {
+			o.summaryStop = make(chan struct{})
+			go o.summarizeEvery(opts.SummaryInterval, o.summaryStop)
+		}
+	}
+	if opts.BufferSize <= 0 {
+		return o
+	}
+
+	interval := opts.FlushInterval
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	o.bw = bufio.NewWriterSize(o.dst, opts.BufferSize)
+	o.stop = make(chan struct{})
+	go o.flushEvery(interval)
+	return o
+}
+
+// write writes line to the underlying writer as a single call. Buffered
+// outputs append line to the buffer instead.
+func (o *output) write(line []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.writeLocked(line)
+}
+
+// writeRecord writes a record line at level and time t, numbered if
+// LineNumbers is set. It is preceded by an idle marker when more than
+// IdleSeparator has passed since the previous record, and followed by a
+// summary line when SummaryEvery records have been written since the last
+// one. If head is non-nil, it renders the start of the line under the
+// lock, for columns that depend on the previous record. If finish is
+// non-nil, it gets the complete line, e.g. to apply HighlightPatterns and
+// MaxRecordBytes. It returns the length of the record line written.
+func (o *output) writeRecord(line []byte, level slog.Level, t time.Time, head func(*buffer), finish func([]byte) []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.idleGap > 0 {
+		prev := o.lastRecord
+		o.lastRecord = t
+		if !prev.IsZero() && t.Sub(prev) > o.idleGap {
+			if err := o.writeLocked(o.idleLine(t.Sub(prev))); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if head != nil {
+		buf := newBuffer()
+		defer buf.free()
+		head(buf)
+		*buf = append(*buf, line...)
+		line = *buf
+	}
+	if o.numbered {
+		o.lineNo++
+		line = o.numberLine(line)
+	}
+	if finish != nil {
+		line = finish(line)
+	}
+	err := o.writeLocked(line)
+	if o.summary != nil && o.summary.count(level) && err == nil {
+		err = o.writeLocked(o.summary.line())
+	}
+	return len(line), err
+}
+
+// numberLine returns line prefixed with the current record number,
+// right-aligned in a gutter of lineNumberWidth digits.
+func (o *output) numberLine(line []byte) []byte {
+	var digits [20]byte
+	num := strconv.AppendUint(digits[:0], o.lineNo, 10)
+
+	buf := make([]byte, 0, len(o.numberStyle)+lineNumberWidth+len(colorReset)+1+len(line))
+	buf = append(buf, o.numberStyle...)
+	for i := len(num); i < lineNumberWidth; i++ {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, num...)
+	if o.numberStyle != "" {
+		buf = append(buf, colorReset...)
+	}
+	buf = append(buf, ' ')
+	return append(buf, line...)
+}
+
+// idleLine renders the marker written before a record that follows a
+// pause of d, e.g. "— 42s idle —".
+func (o *output) idleLine(d time.Duration) []byte {
+	buf := []byte(o.idleStyle)
+	buf = append(buf, "— "...)
+	buf = append(buf, roundElapsed(d).String()...)
+	buf = append(buf, " idle —"...)
+	if o.idleStyle != "" {
+		buf = append(buf, colorReset...)
+	}
+	return append(buf, '\n')
+}
+
+// Write implements io.Writer so the JSON handler shares the output's lock
+// and buffer. Records are shortened to MaxRecordBytes.
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	record := p
+	if o.maxRecord > 0 {
+		record = limitJSON(p, o.maxRecord)
+	}
+	o.jsonWritten = 0
+	if err := o.writeLocked(record); err != nil {
+		return 0, err
+	}
+	o.jsonWritten = len(record)
+	return len(p), nil
+}
+
+// writeLocked writes p to the buffer or writer, reporting a failure to
+// OnError and retrying on the fallback writer. Records always reach the
+// writer whole, in a single Write call: the buffer is flushed before a
+// record that does not fit, and records larger than the buffer bypass
+// it. It must be called with o.mu held.
+func (o *output) writeLocked(p []byte) error {
+	var n int
+	var err error
+	if o.bw != nil {
+		if len(p) > o.bw.Available() {
+			err = o.bw.Flush()
+		}
+		if err == nil && len(p) > o.bw.Available() {
+			n, err = o.dst.Write(p)
+		} else if err == nil {
+			n, err = o.bw.Write(p)
+		}
+	} else {
+		n, err = o.dst.Write(p)
+	}
+	o.metrics.countWrite(n, err)
+	if err == nil {
+		return nil
+	}
+
+	if o.onError != nil {
+		o.onError(err)
+	}
+	if o.fallback == nil {
+		return err
+	}
+	if _, ferr := o.fallback.Write(p); ferr != nil {
+		return errors.Join(err, ferr)
+	}
+	return nil
+}
+
+// flush writes any buffered output to the underlying writer.
+func (o *output) flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.bw == nil {
+		return nil
+	}
+	return o.bw.Flush()
+}
+
+// close flushes buffered output and stops the background flusher and
+// summary lines. Unless the sync policy is SyncNever it also syncs the
+// underlying writer if it supports it, e.g. an *os.File. Records written
+// afterwards go straight to the writer.
+func (o *output) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	if o.bw != nil {
+		err = o.bw.Flush()
+		o.bw = nil
+		close(o.stop)
+	}
+	if o.summaryStop != nil {
+		close(o.summaryStop)
+		o.summaryStop = nil
+	}
+	if o.syncPolicy != SyncNever {
+		err = errors.Join(err, o.syncLocked())
+	}
+	return err
+}
+
+// sync flushes buffered output and syncs the underlying writer to stable
+// storage, for SyncErrors.
+func (o *output) sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	if o.bw != nil {
+		err = o.bw.Flush()
+	}
+	return errors.Join(err, o.syncLocked())
+}
+
+// syncLocked syncs the underlying writer if it supports it. Writers that
+// cannot be synced, such as terminals and pipes, are ignored. It must be
+// called with o.mu held.
+func (o *output) syncLocked() error {
+	s, ok := o.w.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	if err := s.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return err
+	}
+	return nil
+}
+
+// flushEvery flushes the buffer every interval until the output is closed.
+func (o *output) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := o.flush(); err != nil && o.onError != nil {
+				o.onError(err)
+			}
+		case <-o.stop:
+			return
+		}
+	}
+}
+
+// sinceLocked returns the time between t and the previous record written
+// to o, or between t and start for the first record, and remembers t. It
+// must be called with o.mu held.
+func (o *output) sinceLocked(t, start time.Time) time.Duration {
+	prev := o.last
+	if prev.IsZero() {
+		prev = start
+	}
+	o.last = t
+	return t.Sub(prev)
+}
+
+// repeatsTimeLocked reports whether timeStr equals the timestamp of the
+// previous record written to o, and remembers it. It must be called with
+// o.mu held.
+func (o *output) repeatsTimeLocked(timeStr string) bool {
+	repeated := o.lastTimeStr == timeStr
+	o.lastTimeStr = timeStr
+	return repeated
 }
 
 // Enabled reports whether the handler handles records at the given level.
+// A level set on ctx with WithMinLevel takes precedence over Options.Level.
 func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
-	return h.h.Enabled(ctx, level)
+	if min, ok := MinLevelFromContext(ctx); ok {
+		return level >= min
+	}
+	return level >= h.opts.Level
 }
 
 // Handle handles the Record.
@@ -51,166 +397,843 @@ func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
 		return nil
 	}
 
+	h.metrics.countRecord(r.Level)
+
+	if h.opts.Deterministic {
+		r.Time = DeterministicTime
+	} else if r.Time.IsZero() {
+		r.Time = h.opts.now()
+	}
+
+	if h.opts.AddSequence {
+		r = r.Clone()
+		r.AddAttrs(slog.Uint64(SequenceKey, h.out.seq.Add(1)))
+	}
+
+	if attrs := ContextAttrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+
+	if h.opts.SpanContext != nil {
+		if sc, ok := h.opts.SpanContext(ctx); ok {
+			r = r.Clone()
+			r.AddAttrs(sc.attrs()...)
+		}
+	}
+
+	r, ok := h.opts.OnRecord.before(ctx, r)
+	if !ok {
+		return nil
+	}
+
 	// If JSON mode is enabled, delegate to the underlying handler
 	if h.opts.UseJSON {
-		return h.h.Handle(ctx, r)
+		if h.name != "" {
+			r = r.Clone()
+			r.AddAttrs(slog.String(NameKey, h.name))
+		}
+		if h.opts.OnRecord.After == nil {
+			err := h.h.Handle(ctx, r)
+			if err == nil {
+				err = h.syncRecord(r.Level)
+			}
+			return err
+		}
+		n, err := h.handleJSON(ctx, r)
+		if err == nil {
+			err = h.syncRecord(r.Level)
+		}
+		return h.opts.OnRecord.after(ctx, r, n, err)
 	}
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	start := time.Now()
+	buf := newBuffer()
+	defer buf.free()
+	h.appendRecord(buf, r)
+	line := []byte(*buf)
+	h.metrics.observeFormat(time.Since(start))
+	var head func(*buffer)
+	if h.timeHeadStateful() {
+		head = func(buf *buffer) { h.appendTimeHead(buf, r.Time) }
+	}
+	var finish func([]byte) []byte
+	highlight := len(h.opts.HighlightPatterns) > 0 && !h.opts.DisableColor
+	if highlight || h.opts.MaxRecordBytes > 0 {
+		// Highlights and the limit cover the time head and line number
+		// added on write
+		attrs := len(h.attrs) + r.NumAttrs()
+		finish = func(line []byte) []byte {
+			if highlight {
+				line = []byte(highlightPatterns(string(line), h.opts.HighlightPatterns))
+			}
+			if h.opts.MaxRecordBytes > 0 {
+				line = []byte(limitLine(string(line), h.opts.MaxRecordBytes, attrs))
+			}
+			return line
+		}
+	}
+	n, err := h.out.writeRecord(line, r.Level, r.Time, head, finish)
+	if err == nil {
+		err = h.syncRecord(r.Level)
+	}
+	return h.opts.OnRecord.after(ctx, r, n, err)
+}
 
-	// Format time
-	timeStr := r.Time.Format(h.opts.TimeFormat)
+// handleJSON passes r to the JSON handler and returns the length of the
+// record written, as shortened by MaxRecordBytes. Records are serialized
+// so the length read back belongs to r.
+func (h *Handler) handleJSON(ctx context.Context, r slog.Record) (int, error) {
+	h.out.jsonMu.Lock()
+	defer h.out.jsonMu.Unlock()
 
-	// Format level
-	levelStr := formatLevel(r.Level, h.opts.DisableColor)
+	err := h.h.Handle(ctx, r)
+	h.out.mu.Lock()
+	n := h.out.jsonWritten
+	h.out.mu.Unlock()
+	return n, err
+}
+
+// syncRecord syncs the output after an error record under SyncErrors, so
+// the record survives a crash.
+func (h *Handler) syncRecord(level slog.Level) error {
+	if h.opts.Sync != SyncErrors || level < slog.LevelError {
+		return nil
+	}
+	return h.out.sync()
+}
+
+// timeHeadStateful reports whether the time and delta columns depend on
+// the previous record, so Handle leaves them to be rendered under the
+// output's lock by appendTimeHead, in the order records are written.
+func (h *Handler) timeHeadStateful() bool {
+	if h.opts.LineFormatter != nil || len(h.opts.Columns) > 0 {
+		return false
+	}
+	return h.opts.TimeDelta != DeltaNone || (h.opts.ElideRepeatedTime && h.opts.TimeDelta != DeltaReplace)
+}
+
+// appendTimeHead renders the time and delta columns for a record at t.
+// When timeHeadStateful reports true it must be called with h.out.mu held.
+func (h *Handler) appendTimeHead(buf *buffer, t time.Time) {
+	if h.opts.TimeDelta != DeltaReplace {
+		timeStyle := h.opts.style(h.opts.TimeStyle)
+		buf.writeString(timeStyle)
+		if h.opts.ElideRepeatedTime {
+			timeStr := h.formatTime(t)
+			if h.out.repeatsTimeLocked(timeStr) {
+				buf.writeString(elidedTime(timeStr, h.opts.RepeatedTimeMarker))
+			} else {
+				buf.writeByte('[')
+				buf.writeString(timeStr)
+				buf.writeString("] ")
+			}
+		} else {
+			buf.writeByte('[')
+			h.appendTime(buf, t)
+			buf.writeString("] ")
+		}
+		if timeStyle != "" {
+			// Reset before the separating space
+			*buf = (*buf)[:len(*buf)-1]
+			buf.writeString(colorReset)
+			buf.writeByte(' ')
+		}
+	}
+	if h.opts.TimeDelta != DeltaNone {
+		buf.writeString(formatDelta(h.out.sinceLocked(t, h.start)))
+		buf.writeByte(' ')
+	}
+}
+
+// appendRecord renders r into buf as a complete line, including the
+// newline. The common layout is appended piece by piece without
+// allocating; optional layouts fall back to building strings.
+func (h *Handler) appendRecord(buf *buffer, r slog.Record) {
+	// Hand complete control of the line to a custom formatter
+	if h.opts.LineFormatter != nil {
+		*buf = h.opts.LineFormatter.Format(*buf, RecordView{Record: r, h: h})
+		if len(*buf) == 0 || (*buf)[len(*buf)-1] != '\n' {
+			buf.writeByte('\n')
+		}
+		return
+	}
+
+	// Render a tabular layout if columns are configured
+	if len(h.opts.Columns) > 0 {
+		buf.writeString(h.formatColumns(r))
+		buf.writeByte('\n')
+		return
+	}
+
+	// [TIME] Δdelta LEVEL Message(fixed-width)
+	if !h.timeHeadStateful() {
+		h.appendTimeHead(buf, r.Time)
+	}
+	appendLevel(buf, r.Level, h.opts.levelStyler(), h.opts.DisableColor)
+	if h.name != "" {
+		buf.writeByte(' ')
+		buf.writeString(h.formatName())
+	}
+	buf.writeByte(' ')
+	h.appendMessage(buf, r.Message, h.messageStyle(r.Level))
+
+	// Render attributes on their own lines below the message
+	if h.opts.GroupStyle == GroupNested {
+		for len(*buf) > 0 && (*buf)[len(*buf)-1] == ' ' {
+			*buf = (*buf)[:len(*buf)-1]
+		}
+		buf.writeByte('\n')
+		var sb strings.Builder
+		h.appendNested(&sb, h.attrTree(r), "", 1)
+		if h.opts.AddSource {
+			if source := h.formatSource(r); source != "" {
+				sb.WriteString(nestedIndent)
+				sb.WriteString(formatAttr(slog.String(slog.SourceKey, source), &h.opts))
+				sb.WriteString("\n")
+			}
+		}
+		buf.writeString(sb.String())
+		return
+	}
+
+	// Layouts that need every attribute formatted up front
+	if !h.opts.inlineAttrs() {
+		// Collect and format attributes, moving tabular values below the line
+		tree := h.attrTree(r)
+		var tables []*attrTable
+		if h.opts.TableValues {
+			tree, tables = h.splitTables(tree, "")
+		}
+		attrs := h.formatTree(r, tree, h.opts.AddSource, "")
+
+		if len(attrs) > 0 && h.opts.MaxLineWidth > 0 {
+			wrapAttrs(buf, attrs, h.opts.MaxLineWidth)
+		} else if len(attrs) > 0 {
+			for _, attr := range attrs {
+				buf.writeByte(' ')
+				buf.writeString(attr)
+			}
+		}
+		buf.writeByte('\n')
+		if len(tables) > 0 {
+			var sb strings.Builder
+			for _, t := range tables {
+				h.appendTable(&sb, t, t.key, 1)
+			}
+			buf.writeString(sb.String())
+		}
+		return
+	}
+
+	*buf = append(*buf, h.preformatted...)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(buf, a, h.prefix)
+		return true
+	})
+	if h.opts.AddSource {
+		h.appendSource(buf, r)
+	}
+	buf.writeByte('\n')
+}
 
-	// Format message (truncate and pad to configured width)
-	message := r.Message
+// appendSource appends " source=file.go:line" like formatSource, without
+// building the location string unless it must be quoted or highlighted.
+func (h *Handler) appendSource(buf *buffer, r slog.Record) {
+	if r.PC == 0 {
+		return
+	}
+	fs := runtime.CallersFrames([]uintptr{r.PC})
+	f, _ := fs.Next()
+	if f.File == "" {
+		return
+	}
+	file := f.File[strings.LastIndexByte(f.File, '/')+1:]
+	buf.writeString(" " + slog.SourceKey + "=")
+	if len(h.opts.Highlights) > 0 || h.opts.Formatters != nil || !isPlainText(file) || strings.ContainsAny(file, " =\"'`[]{}") {
+		appendValue(buf, slog.SourceKey, slog.StringValue(h.formatSource(r)), &h.opts)
+		return
+	}
+	buf.writeString(file)
+	buf.writeByte(':')
+	*buf = strconv.AppendInt(*buf, int64(f.Line), 10)
+}
+
+// appendAttr appends a as " key=value", with the key qualified by prefix.
+// Groups are flattened into dotted keys.
+func (h *Handler) appendAttr(buf *buffer, a slog.Attr, prefix string) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		// Groups without a key are inlined, as in slog
+		if a.Key != "" {
+			if prefix != "" {
+				prefix += "." + a.Key
+			} else {
+				prefix = a.Key
+			}
+		}
+		for _, m := range a.Value.Group() {
+			h.appendAttr(buf, m, prefix)
+		}
+		return
+	}
+	buf.writeByte(' ')
+	appendKeyValue(buf, prefix, a, &h.opts)
+}
+
+// appendKeyValue appends a as "prefix.key=value". The qualified key is
+// only built as a string when formatters or highlights need to match it.
+func appendKeyValue(buf *buffer, prefix string, a slog.Attr, opts *Options) {
+	keyStyle := opts.style(opts.KeyStyle)
+	buf.writeString(keyStyle)
+	key := a.Key
+	if prefix != "" {
+		buf.writeString(sanitize(prefix, opts.ControlChars))
+		buf.writeByte('.')
+		if opts.Formatters != nil || len(opts.Highlights) > 0 {
+			key = prefix + "." + a.Key
+		}
+	}
+	buf.writeString(sanitize(a.Key, opts.ControlChars))
+	buf.writeByte('=')
+	if keyStyle != "" {
+		buf.writeString(colorReset)
+	}
+	appendValue(buf, key, a.Value, opts)
+}
+
+// wrapAttrs appends attrs to the line in buf, moving those that would cross
+// maxWidth onto continuation lines indented to the attribute column. An
+// attribute wider than the space left on a line of its own overflows.
+func wrapAttrs(buf *buffer, attrs []string, maxWidth int) {
+	column := visibleWidth(string(*buf)) + 1
+	width := column - 1
+	for i, attr := range attrs {
+		n := visibleWidth(attr)
+		if i > 0 && width+1+n > maxWidth {
+			buf.writeByte('\n')
+			buf.pad(column)
+			width = column + n
+		} else {
+			buf.writeByte(' ')
+			width += 1 + n
+		}
+		buf.writeString(attr)
+	}
+}
+
+// formatMessage truncates and pads message to the configured width.
+func (h *Handler) formatMessage(message string) string {
+	var buf buffer
+	h.appendMessage(&buf, message, "")
+	return string(buf)
+}
+
+// appendMessage appends message truncated and padded to the configured
+// width.
+func (h *Handler) appendMessage(buf *buffer, message, style string) {
+	message = cleanText(message, &h.opts)
 	width := h.opts.MessageWidth
 	if width <= 0 {
 		width = messageWidth // fallback to constant default
 	}
-	if len(message) > width {
+	buf.writeString(style)
+	n := visibleWidth(message)
+	if n > width {
 		// Truncate with ellipsis, ensuring space for "..."
+		cut, marker := width, ""
 		if width > 3 {
-			message = message[:width-3] + "..."
+			cut, marker = width-3, "..."
+		}
+		if strings.IndexByte(message, '\x1b') >= 0 {
+			message, _ = truncateVisible(message, cut)
+			if strings.IndexByte(message, '\x1b') >= 0 {
+				message += colorReset + style
+			}
 		} else {
-			message = message[:width]
+			message = truncateRunes(message, cut)
+		}
+		buf.writeString(message)
+		buf.writeString(marker)
+		n = cut + len(marker)
+	} else {
+		buf.writeString(message)
+	}
+	if style != "" {
+		buf.writeString(colorReset)
+	}
+	// Pad by the visible width, as embedded escape sequences take no space
+	buf.pad(width - n)
+}
+
+// messageStyle returns the level's style if TintMessage is set and color
+// is enabled, or else "".
+func (h *Handler) messageStyle(level slog.Level) string {
+	if !h.opts.TintMessage {
+		return ""
+	}
+	return h.opts.style(h.opts.levelStyler().LevelColor(level))
+}
+
+// truncateRunes returns the first n runes of s.
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
 		}
+		n--
 	}
-	// Use Sprintf with %-*s for left-alignment and padding
-	formattedMessage := fmt.Sprintf("%-*s", width, message)
+	return s
+}
 
-	// Build the log line
-	var sb strings.Builder
+// formatAttrs returns the handler's attributes followed by the record's
+// attributes and, if enabled, the source location, each as "key=value".
+func (h *Handler) formatAttrs(r slog.Record) []string {
+	return h.collectAttrs(r, h.opts.AddSource, "")
+}
 
-	// [TIME] LEVEL Message(fixed-width)
-	fmt.Fprintf(&sb, "[%s] %s %s", timeStr, levelStr, formattedMessage)
+// collectAttrs formats the handler's and the record's attributes, skipping
+// the top-level attribute named skipKey, and appends the source location if
+// withSource is set.
+func (h *Handler) collectAttrs(r slog.Record, withSource bool, skipKey string) []string {
+	return h.formatTree(r, h.attrTree(r), withSource, skipKey)
+}
 
-	// Collect and format attributes
-	var attrs []string
-	attrs = h.appendAttrs(attrs, h.attrs)
+// formatTree is collectAttrs for the attribute tree of r.
+func (h *Handler) formatTree(r slog.Record, tree []slog.Attr, withSource bool, skipKey string) []string {
+	attrs := h.appendAttrs(nil, tree, "", skipKey)
 
-	// Add attributes from the record
-	r.Attrs(func(attr slog.Attr) bool {
-		attrs = h.appendAttrs(attrs, []slog.Attr{attr})
-		return true
-	})
+	// Golden output must not depend on attribute order
+	if h.opts.Deterministic {
+		sort.Strings(attrs)
+	}
 
 	// Add source if enabled
-	if h.opts.AddSource && r.PC != 0 {
-		fs := runtime.CallersFrames([]uintptr{r.PC})
-		f, _ := fs.Next()
-		if f.File != "" {
-			shortFile := f.File
-			if i := strings.LastIndex(f.File, "/"); i != -1 {
-				shortFile = f.File[i+1:]
-			}
-			attr := slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", shortFile, f.Line))
-			attrs = append(attrs, formatAttr(attr, h.opts.DisableColor))
+	if withSource {
+		if source := h.formatSource(r); source != "" {
+			attrs = append(attrs, formatAttr(slog.String(slog.SourceKey, source), &h.opts))
 		}
 	}
+	return attrs
+}
 
-	// Add attributes if any (lazy evaluation - only format if needed)
-	if len(attrs) > 0 {
-		sb.WriteString(" ")
-		sb.WriteString(strings.Join(attrs, " "))
+// formatSource returns the record's source location as "file.go:line",
+// or "" if it is unknown. In deterministic mode the line is omitted so
+// unrelated edits do not change the output.
+func (h *Handler) formatSource(r slog.Record) string {
+	if r.PC == 0 {
+		return ""
 	}
-
-	// Add newline and write to output
-	sb.WriteString("\n")
-	_, err := io.WriteString(h.opts.Writer, sb.String())
-	return err
+	fs := runtime.CallersFrames([]uintptr{r.PC})
+	f, _ := fs.Next()
+	if f.File == "" {
+		return ""
+	}
+	shortFile := f.File
+	if i := strings.LastIndex(f.File, "/"); i != -1 {
+		shortFile = f.File[i+1:]
+	}
+	if h.opts.Deterministic {
+		return shortFile
+	}
+	return fmt.Sprintf("%s:%d", shortFile, f.Line)
 }
 
 // WithAttrs returns a new Handler whose attributes consist of h's attributes followed by attrs.
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
 	if h.opts.UseJSON {
-		return &Handler{
-			h:      h.h.WithAttrs(attrs),
-			opts:   h.opts,
-			attrs:  nil,
-			groups: nil,
-		}
+		h2.h = h.h.WithAttrs(attrs)
+		return h2
 	}
+	h2.attrs = h.qualifiedAttrs(attrs)
+	h2.preformatted = h.preformat(attrs)
+	return h2
+}
+
+// clone returns a copy of h to derive a handler from. Handlers are
+// immutable: derived handlers replace the slices they change instead of
+// appending to them in place, so siblings never share mutable state.
+// Everything mutable lives in the shared output.
+func (h *Handler) clone() *Handler {
+	h2 := *h
+	return &h2
+}
 
-	h2 := &Handler{
-		h:      h.h,
-		opts:   h.opts,
-		attrs:  append(append([]slog.Attr{}, h.attrs...), attrs...),
-		groups: h.groups,
+// preformat returns h.preformatted followed by attrs rendered in the
+// inline layout under h's groups, or nil if the layout renders attributes
+// per record. Like slog's built-in handlers, it resolves LogValuer
+// attributes once, when the handler is derived.
+func (h *Handler) preformat(attrs []slog.Attr) []byte {
+	if !h.opts.inlineAttrs() {
+		return nil
 	}
-	return h2
+	// Clip so derived handlers never share a backing array
+	buf := buffer(slices.Clip(h.preformatted))
+	for _, a := range attrs {
+		h.appendAttr(&buf, a, h.prefix)
+	}
+	return buf
+}
+
+// qualifiedAttrs returns h's attributes followed by attrs, with attrs
+// wrapped in the handler's current groups, so groups opened later do not
+// apply to them.
+func (h *Handler) qualifiedAttrs(attrs []slog.Attr) []slog.Attr {
+	all := append(make([]slog.Attr, 0, len(h.attrs)+len(attrs)), h.attrs...)
+	return append(all, wrapGroups(attrs, h.groups)...)
 }
 
 // WithGroup returns a new Handler with the given group name.
 func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
 	if h.opts.UseJSON {
-		return &Handler{
-			h:      h.h.WithGroup(name),
-			opts:   h.opts,
-			attrs:  nil,
-			groups: nil,
-		}
+		h2.h = h.h.WithGroup(name)
+		return h2
 	}
-
-	h2 := &Handler{
-		h:      h.h,
-		opts:   h.opts,
-		attrs:  h.attrs,
-		groups: append(append([]string{}, h.groups...), name),
+	h2.groups = append(slices.Clip(h.groups), name)
+	if h.prefix != "" {
+		h2.prefix = h.prefix + "." + name
+	} else {
+		h2.prefix = name
 	}
 	return h2
 }
 
-// formatLevel returns a fixed-width, uppercase level string with optional color.
-func formatLevel(level slog.Level, disableColor bool) string {
-	var levelStr string
-	var colorCode string
-
-	switch {
-	case level >= slog.LevelError:
-		levelStr = "ERROR"
-		colorCode = colorRed
-	case level >= slog.LevelWarn:
-		levelStr = "WARN "
-		colorCode = colorYellow
-	case level >= slog.LevelInfo:
-		levelStr = "INFO "
-		colorCode = colorBlue
-	default:
-		levelStr = "DEBUG"
-		colorCode = colorGray
+// WithOptions returns a handler that shares h's writer, buffer, metrics,
+// name, attributes and groups but formats records with h's options as
+// modified by fn, e.g. a variant of a logger without source locations:
+//
+//	quiet := h.WithOptions(func(o *humanlog.Options) { o.AddSource = false })
+//
+// Settings of the shared output keep their values: Writer, OnError,
+// FallbackWriter, BufferSize, FlushInterval, Sync, LockFile,
+// MaxRecordBytes, SummaryInterval, SummaryEvery, IdleSeparator,
+// LineNumbers and Metrics, as does UseJSON. JSON handlers only apply
+// a changed Level.
+func (h *Handler) WithOptions(fn func(o *Options)) *Handler {
+	opts := h.opts
+	fn(&opts)
+	opts.Writer, opts.OnError, opts.FallbackWriter = h.opts.Writer, h.opts.OnError, h.opts.FallbackWriter
+	opts.BufferSize, opts.FlushInterval = h.opts.BufferSize, h.opts.FlushInterval
+	opts.Sync, opts.LockFile, opts.MaxRecordBytes = h.opts.Sync, h.opts.LockFile, h.opts.MaxRecordBytes
+	opts.SummaryInterval, opts.SummaryEvery = h.opts.SummaryInterval, h.opts.SummaryEvery
+	opts.IdleSeparator, opts.LineNumbers = h.opts.IdleSeparator, h.opts.LineNumbers
+	opts.Metrics, opts.UseJSON = h.opts.Metrics, h.opts.UseJSON
+	if opts.Deterministic {
+		opts.DisableColor = true
+		opts.UseUTC = true
 	}
 
-	if disableColor {
-		return levelStr
+	h2 := h.clone()
+	h2.opts = opts
+	if opts.TimeFormat != h.opts.TimeFormat {
+		h2.times = newTimeCache(opts.TimeFormat)
 	}
+	// Render the attributes again, as they depend on the options; they
+	// are already wrapped in their groups
+	h2.preformatted = nil
+	if !opts.UseJSON && opts.inlineAttrs() {
+		var buf buffer
+		for _, a := range h.attrs {
+			h2.appendAttr(&buf, a, "")
+		}
+		h2.preformatted = buf
+	}
+	return h2
+}
+
+// Flush writes any output buffered because of Options.BufferSize.
+// It is a no-op for unbuffered handlers.
+func (h *Handler) Flush() error {
+	return h.out.flush()
+}
+
+// Close flushes buffered output, stops the background flusher and
+// periodic summary lines and syncs the writer if it is a file. It does
+// not close the writer itself, which remains owned by the caller. Handlers
+// derived with WithAttrs or WithGroup share the output, so closing any of
+// them closes it for all.
+func (h *Handler) Close() error {
+	return h.out.close()
+}
+
+// formatLevel returns a fixed-width level string with optional color,
+// resolved through styler.
+func formatLevel(level slog.Level, styler LevelStyler, disableColor bool) string {
+	var buf buffer
+	appendLevel(&buf, level, styler, disableColor)
+	return string(buf)
+}
 
-	return fmt.Sprintf("%s%s%s", colorCode, levelStr, colorReset)
+// appendLevel appends the fixed-width level string with optional color.
+func appendLevel(buf *buffer, level slog.Level, styler LevelStyler, disableColor bool) {
+	name := styler.LevelName(level)
+	colorCode := styler.LevelColor(level)
+	colored := !disableColor && colorCode != ""
+	if colored {
+		buf.writeString(colorCode)
+	}
+	buf.writeString(name)
+	buf.pad(levelWidth - utf8.RuneCountInString(name))
+	if colored {
+		buf.writeString(colorReset)
+	}
 }
 
-func (h *Handler) appendAttrs(attrs []string, newAttrs []slog.Attr) []string {
-	prefix := strings.Join(h.groups, ".")
+// appendAttrs formats newAttrs with keys qualified by prefix, skipping a
+// top-level attribute named skipKey. Groups are rendered as configured by
+// Options.GroupStyle.
+func (h *Handler) appendAttrs(attrs []string, newAttrs []slog.Attr, prefix, skipKey string) []string {
 	for _, attr := range newAttrs {
+		if skipKey != "" && prefix == "" && attr.Key == skipKey {
+			continue
+		}
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
 		key := attr.Key
 		if prefix != "" {
 			key = prefix + "." + key
 		}
-		attrs = append(attrs, formatAttr(slog.Attr{Key: key, Value: attr.Value}, h.opts.DisableColor))
+
+		if attr.Value.Kind() == slog.KindGroup {
+			members := attr.Value.Group()
+			switch {
+			case attr.Key == "":
+				// Groups without a key are inlined, as in slog
+				attrs = h.appendAttrs(attrs, members, prefix, "")
+			case h.opts.GroupStyle == GroupBracketed:
+				if inner := h.appendBracketed(nil, members, key); len(inner) > 0 {
+					attrs = append(attrs, sanitize(attr.Key, h.opts.ControlChars)+"{"+strings.Join(inner, " ")+"}")
+				}
+			default:
+				attrs = h.appendAttrs(attrs, members, key, "")
+			}
+			continue
+		}
+		attrs = append(attrs, formatAttr(slog.Attr{Key: key, Value: attr.Value}, &h.opts))
+	}
+	return attrs
+}
+
+// appendBracketed formats the members of the group at path with their own
+// keys, nesting subgroups in braces. Formatters still match the full path.
+func (h *Handler) appendBracketed(attrs []string, members []slog.Attr, path string) []string {
+	for _, attr := range members {
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		key := path + "." + attr.Key
+		if attr.Value.Kind() != slog.KindGroup {
+			attrs = append(attrs, formatKey(attr.Key, &h.opts)+formatValue(key, attr.Value, &h.opts))
+			continue
+		}
+		if attr.Key == "" {
+			attrs = h.appendBracketed(attrs, attr.Value.Group(), path)
+		} else if inner := h.appendBracketed(nil, attr.Value.Group(), key); len(inner) > 0 {
+			attrs = append(attrs, sanitize(attr.Key, h.opts.ControlChars)+"{"+strings.Join(inner, " ")+"}")
+		}
 	}
 	return attrs
 }
 
-// formatAttr formats a single attribute as "key=value".
-func formatAttr(attr slog.Attr, _ bool) string {
+// formatAttr formats a single attribute as "key=value" using opts.
+func formatAttr(attr slog.Attr, opts *Options) string {
 	if attr.Equal(slog.Attr{}) {
 		return ""
 	}
+	buf := newBuffer()
+	defer buf.free()
+	appendKey(buf, attr.Key, opts)
+	appendValue(buf, attr.Key, attr.Value, opts)
+	return string(*buf)
+}
+
+// appendKey appends "key=", styled by KeyStyle.
+func appendKey(buf *buffer, key string, opts *Options) {
+	keyStyle := opts.style(opts.KeyStyle)
+	buf.writeString(keyStyle)
+	buf.writeString(sanitize(key, opts.ControlChars))
+	buf.writeByte('=')
+	if keyStyle != "" {
+		buf.writeString(colorReset)
+	}
+}
+
+// formatKey returns "key=" styled by KeyStyle.
+func formatKey(key string, opts *Options) string {
+	var buf buffer
+	appendKey(&buf, key, opts)
+	return string(buf)
+}
+
+// formatValue formats the value of the attribute with the given key,
+// styled by the first matching highlight rule or, failing that, by its
+// HTTP semantics.
+func formatValue(key string, val slog.Value, opts *Options) string {
+	buf := newBuffer()
+	defer buf.free()
+	appendValue(buf, key, val, opts)
+	return string(*buf)
+}
+
+// appendValue appends the value of the attribute with the given key like
+// formatValue.
+func appendValue(buf *buffer, key string, val slog.Value, opts *Options) {
+	style := ""
+	if !opts.DisableColor {
+		style = highlightStyle(key, val, opts)
+		if style == "" && !opts.DisableHTTPColor {
+			style = semanticStyle(key, val)
+		}
+		if style == "" {
+			style = kindStyle(val, opts)
+		}
+	}
+	if style != "" {
+		buf.writeString(style)
+	}
+	appendPlainValue(buf, key, val, opts)
+	if style != "" {
+		buf.writeString(colorReset)
+	}
+}
+
+// kindStyle returns StringStyle or NumberStyle according to the kind of
+// val, or "" for other kinds.
+func kindStyle(val slog.Value, opts *Options) string {
+	switch val.Kind() {
+	case slog.KindString:
+		return opts.StringStyle
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return opts.NumberStyle
+	}
+	return ""
+}
+
+// appendPlainValue appends the unstyled value. Common kinds are appended
+// directly; everything else goes through formatPlainValue.
+func appendPlainValue(buf *buffer, key string, val slog.Value, opts *Options) {
+	if opts.Formatters == nil {
+		switch val.Kind() {
+		case slog.KindString:
+			if s := val.String(); isPlainText(s) {
+				if needsQuoting(s) {
+					*buf = strconv.AppendQuote(*buf, s)
+				} else {
+					buf.writeString(s)
+				}
+				return
+			}
+		case slog.KindInt64:
+			if opts.ThousandsSeparator == "" {
+				*buf = strconv.AppendInt(*buf, val.Int64(), 10)
+				return
+			}
+		case slog.KindUint64:
+			if opts.ThousandsSeparator == "" {
+				*buf = strconv.AppendUint(*buf, val.Uint64(), 10)
+				return
+			}
+		case slog.KindFloat64:
+			if opts.FloatPrecision <= 0 && opts.ThousandsSeparator == "" {
+				*buf = strconv.AppendFloat(*buf, val.Float64(), 'g', -1, 64)
+				return
+			}
+		case slog.KindBool:
+			*buf = strconv.AppendBool(*buf, val.Bool())
+			return
+		case slog.KindDuration:
+			*buf = appendDuration(*buf, val.Duration())
+			return
+		case slog.KindTime:
+			*buf = opts.inLocation(val.Time()).AppendFormat(*buf, opts.attrTimeFormat())
+			return
+		}
+	}
+	buf.writeString(formatPlainValue(key, val, opts))
+}
+
+// isPlainText reports whether s holds only printable ASCII, so it needs
+// no control character or escape sequence handling.
+func isPlainText(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatPlainValue formats the value of the attribute with the given key,
+// quoting it as needed and handling embedded escape sequences and control
+// characters per opts.ANSI and opts.ControlChars.
+func formatPlainValue(key string, val slog.Value, opts *Options) string {
+	var s string
+	isErr := false
+	switch val.Kind() {
+	case slog.KindString:
+		s = val.String()
+	case slog.KindAny:
+		err, ok := val.Any().(error)
+		if !ok {
+			return cleanText(formatRawValue(key, val, opts), opts)
+		}
+		s, isErr = err.Error(), true
+	default:
+		return cleanText(formatRawValue(key, val, opts), opts)
+	}
+	if opts.Formatters.lookup(key) != nil {
+		return cleanText(formatRawValue(key, val, opts), opts)
+	}
+
+	// Strip before quoting, which would escape them
+	if opts.ANSI == ANSIStrip {
+		s = stripANSI(s)
+	}
+	if opts.ControlChars == ControlStrip {
+		if opts.ANSI == ANSIPassThrough {
+			s = mapOutsideANSI(s, func(part string) string { return sanitize(part, ControlStrip) })
+		} else {
+			s = sanitize(s, ControlStrip)
+		}
+	}
+
+	// Quote around passed through sequences, judging by the visible text
+	if opts.ANSI == ANSIPassThrough && strings.IndexByte(s, '\x1b') >= 0 {
+		if isErr || needsQuoting(stripANSI(s)) {
+			return quoteANSI(s)
+		}
+		return cleanText(s, opts)
+	}
+	if isErr {
+		return strconv.Quote(s)
+	}
+	return cleanText(formatRawValue(key, slog.StringValue(s), opts), opts)
+}
 
-	key := attr.Key
-	val := attr.Value
+// formatRawValue formats a value, quoting it as needed.
+func formatRawValue(key string, val slog.Value, opts *Options) string {
+	// Key-based formatters take precedence over type-based formatting
+	if format := opts.Formatters.lookup(key); format != nil {
+		s := format(val.Resolve())
+		if needsQuoting(s) {
+			return strconv.Quote(s)
+		}
+		return s
+	}
 
 	// Handle special cases
 	switch val.Kind() {
@@ -218,30 +1241,53 @@ func formatAttr(attr slog.Attr, _ bool) string {
 		// Quote strings if they contain spaces or special characters
 		s := val.String()
 		if needsQuoting(s) {
-			return fmt.Sprintf("%s=%q", key, s)
+			return strconv.Quote(s)
 		}
-		return fmt.Sprintf("%s=%s", key, s)
+		return s
+
+	case slog.KindInt64:
+		return formatInt(val.Int64(), opts)
+
+	case slog.KindUint64:
+		return formatUint(val.Uint64(), opts)
+
+	case slog.KindFloat64:
+		return formatFloat(val.Float64(), opts)
 
 	case slog.KindTime:
 		// Format time values
-		t := val.Time()
-		return fmt.Sprintf("%s=%s", key, t.Format(time.RFC3339))
+		t := opts.inLocation(val.Time())
+		return t.Format(opts.attrTimeFormat())
 
 	case slog.KindDuration:
 		// Format duration values
 		d := val.Duration()
-		return fmt.Sprintf("%s=%s", key, d.String())
+		return d.String()
 
 	case slog.KindAny:
 		// Handle error values specially
 		if err, ok := val.Any().(error); ok {
-			return fmt.Sprintf("%s=%q", key, err.Error())
+			return strconv.Quote(err.Error())
+		}
+		// Render byte slices safely instead of dumping raw bytes
+		if b, ok := val.Any().([]byte); ok {
+			return formatBytes(b, opts)
+		}
+		// Let domain types describe themselves
+		if s, isJSON, ok := selfFormat(val.Any()); ok {
+			if !isJSON && needsQuoting(s) {
+				return strconv.Quote(s)
+			}
+			return s
+		}
+		if opts.AnyFormat != AnyDefault {
+			return formatAny(val.Any(), opts)
 		}
 		fallthrough
 
 	default:
 		// Use the default string representation for other types
-		return fmt.Sprintf("%s=%s", key, val.String())
+		return val.String()
 	}
 }
 
@@ -258,8 +1304,10 @@ func needsQuoting(s string) bool {
 	}
 
 	// Don't quote valid numbers
-	if _, err := strconv.ParseFloat(s, 64); err == nil {
-		return false
+	if looksNumeric(s) {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return false
+		}
 	}
 
 	// Check for Go keywords and literals that might cause confusion
@@ -277,3 +1325,30 @@ func needsQuoting(s string) bool {
 
 	return false
 }
+
+// looksNumeric reports whether s could parse as a float, so needsQuoting
+// can skip the parse, whose error allocates, for ordinary words and
+// version strings.
+func looksNumeric(s string) bool {
+	if s[0] == '+' || s[0] == '-' {
+		s = s[1:]
+	}
+	if strings.EqualFold(s, "inf") || strings.EqualFold(s, "infinity") || strings.EqualFold(s, "nan") {
+		return true
+	}
+	if s == "" || (s[0] != '.' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	dots := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.':
+			dots++
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		case c == 'x', c == 'X', c == 'p', c == 'P', c == '+', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return dots <= 1
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 )
@@ -128,6 +129,68 @@ func BenchmarkHandler_HandleWithAttrs(b *testing.B) {
 	}
 }
 
+// BenchmarkHandler_HandleManyStaticAttrs benchmarks Handle on a logger
+// carrying many attributes added with With, which are formatted once
+func BenchmarkHandler_HandleManyStaticAttrs(b *testing.B) {
+	h := NewHandler(io.Discard, &Options{
+		Level:        slog.LevelInfo,
+		DisableColor: true,
+		AddSource:    false,
+	})
+	attrs := make([]slog.Attr, 20)
+	for i := range attrs {
+		attrs[i] = slog.String(fmt.Sprintf("key%d", i), "some value")
+	}
+	logger := slog.New(h.WithAttrs(attrs))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("Benchmark message", slog.Int("request_id", i))
+	}
+}
+
+// BenchmarkHandler_HandleParallel benchmarks concurrent loggers sharing
+// one output, which only contend for the write itself
+func BenchmarkHandler_HandleParallel(b *testing.B) {
+	h := NewHandler(io.Discard, &Options{
+		Level:        slog.LevelInfo,
+		DisableColor: true,
+		AddSource:    false,
+	})
+	logger := slog.New(h.WithAttrs([]slog.Attr{slog.String("service", "api")}))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			logger.Info("Benchmark message", slog.Int("count", i), slog.String("status", "running"))
+			i++
+		}
+	})
+}
+
+// BenchmarkHandler_HandleTimeFormat benchmarks Handle with a timestamp,
+// which is formatted once per millisecond
+func BenchmarkHandler_HandleTimeFormat(b *testing.B) {
+	h := NewHandler(io.Discard, &Options{
+		Level:        slog.LevelInfo,
+		DisableColor: true,
+		TimeFormat:   TimeFormatMillis,
+	})
+	logger := slog.New(h)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("Benchmark message", slog.Int("count", i), slog.String("status", "running"))
+	}
+}
+
 // BenchmarkHandler_Enabled benchmarks the Enabled method
 func BenchmarkHandler_Enabled(b *testing.B) {
 	h := NewHandler(io.Discard, &Options{
@@ -168,7 +231,7 @@ func BenchmarkFormatAttr(b *testing.B) {
 			b.ReportAllocs()
 
 			for i := 0; i < b.N; i++ {
-				formatAttr(tc.attr, true)
+				formatAttr(tc.attr, &Options{DisableColor: true})
 			}
 		})
 	}
@@ -316,3 +379,122 @@ func BenchmarkLogValuer(b *testing.B) {
 		logger.Info("HTTP request processed", slog.Any("request", httpReq))
 	}
 }
+
+// slowWriter simulates a saturated file or network sink.
+type slowWriter struct {
+	delay time.Duration
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+// serializedHandler wraps a handler with a single lock, emulating a
+// logger where every destination shares one mutex.
+type serializedHandler struct {
+	mu *sync.Mutex
+	slog.Handler
+}
+
+func (h serializedHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.Handler.Handle(ctx, r)
+}
+
+// BenchmarkRouter_SaturatedRoute measures console throughput while another
+// route is kept saturated by a slow writer in the background.
+func BenchmarkRouter_SaturatedRoute(b *testing.B) {
+	newRouter := func() *Router {
+		return NewRouter(
+			Route{
+				Writer:  io.Discard,
+				Options: &Options{Level: slog.LevelInfo, DisableColor: true},
+			},
+			Route{
+				Writer:  slowWriter{delay: 100 * time.Microsecond},
+				Options: &Options{Level: slog.LevelDebug, DisableColor: true},
+				Accept:  func(level slog.Level) bool { return level < slog.LevelInfo },
+			},
+		)
+	}
+
+	run := func(b *testing.B, h slog.Handler) {
+		logger := slog.New(h)
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						logger.Debug("Saturating slow route", slog.String("sink", "slow"))
+					}
+				}
+			}()
+		}
+
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			logger.Info("Console message", slog.Int("count", i))
+		}
+
+		b.StopTimer()
+		close(stop)
+		wg.Wait()
+	}
+
+	b.Run("PerRouteLock", func(b *testing.B) {
+		run(b, newRouter())
+	})
+
+	b.Run("SharedLock", func(b *testing.B) {
+		run(b, serializedHandler{mu: new(sync.Mutex), Handler: newRouter()})
+	})
+}
+
+// BenchmarkRouter_SlowAndFastRoute measures the cost of a record that
+// matches both a fast route and a slow one, with the slow route written
+// synchronously and through Route.Async.
+func BenchmarkRouter_SlowAndFastRoute(b *testing.B) {
+	run := func(b *testing.B, async *AsyncOptions) {
+		r := NewRouter(
+			Route{
+				Writer:  io.Discard,
+				Options: &Options{DisableColor: true},
+			},
+			Route{
+				Writer:  slowWriter{delay: 100 * time.Microsecond},
+				Options: &Options{DisableColor: true},
+				Async:   async,
+			},
+		)
+		logger := slog.New(r)
+
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			logger.Info("Both routes", slog.Int("count", i))
+		}
+
+		b.StopTimer()
+		r.Close()
+	}
+
+	b.Run("Sync", func(b *testing.B) {
+		run(b, nil)
+	})
+
+	b.Run("Async", func(b *testing.B) {
+		run(b, &AsyncOptions{DropWhenFull: true})
+	})
+}
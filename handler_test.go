@@ -4,10 +4,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestHandler_Enabled(t *testing.T) {
@@ -46,6 +53,14 @@ func TestHandler_Enabled(t *testing.T) {
 			}
 		})
 	}
+
+	// Only JSON output builds an underlying slog handler
+	if h := NewHandler(new(bytes.Buffer), nil); h.h != nil {
+		t.Errorf("human-readable handler has underlying handler %T", h.h)
+	}
+	if h := NewHandler(new(bytes.Buffer), &Options{UseJSON: true}); h.h == nil {
+		t.Error("JSON handler has no underlying handler")
+	}
 }
 
 func TestHandler_Handle(t *testing.T) {
@@ -472,3 +487,1464 @@ func TestHandler_ColorOutput(t *testing.T) {
 		t.Errorf("Non-color output = %v, should not contain ANSI color code", gotNoColor)
 	}
 }
+
+func TestHandler_RelativeTimeFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		expected string
+	}{
+		{name: "Zero", elapsed: 0, expected: "+00:00.000"},
+		{name: "Milliseconds", elapsed: 3218 * time.Millisecond, expected: "+00:03.218"},
+		{name: "Minutes", elapsed: 12*time.Minute + 5*time.Second, expected: "+12:05.000"},
+		{name: "Hours", elapsed: 2*time.Hour + 3*time.Minute + 4*time.Second + 5*time.Millisecond, expected: "+2:03:04.005"},
+		{name: "Negative clamps to zero", elapsed: -time.Second, expected: "+00:00.000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			h := NewHandler(buf, &Options{
+				Level:        slog.LevelInfo,
+				TimeFormat:   TimeFormatRelative,
+				DisableColor: true,
+			})
+
+			r := slog.NewRecord(h.start.Add(tt.elapsed), slog.LevelInfo, "Relative time", 0)
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			if got := buf.String(); !strings.HasPrefix(got, "["+tt.expected+"]") {
+				t.Errorf("Relative time output = %q, should start with [%s]", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_TimeDelta(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHandler(buf, &Options{
+		Level:        slog.LevelInfo,
+		TimeDelta:    DeltaReplace,
+		DisableColor: true,
+	})
+
+	// Derived handlers share the previous-record time with their parent
+	derived := h.WithAttrs([]slog.Attr{slog.String("step", "two")})
+
+	first := slog.NewRecord(h.start.Add(5*time.Millisecond), slog.LevelInfo, "First", 0)
+	second := slog.NewRecord(h.start.Add(17*time.Millisecond), slog.LevelInfo, "Second", 0)
+	if err := h.Handle(context.Background(), first); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := derived.Handle(context.Background(), second); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "Δ5ms ") {
+		t.Errorf("first line = %q, should start with Δ5ms", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "Δ12ms ") {
+		t.Errorf("second line = %q, should start with Δ12ms", lines[1])
+	}
+	if strings.Contains(buf.String(), "[") {
+		t.Errorf("DeltaReplace output = %q, should not contain a timestamp", buf.String())
+	}
+}
+
+func TestHandler_TimeDeltaConcurrent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHandler(buf, &Options{TimeDelta: DeltaReplace, DisableColor: true})
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				ms := g*50 + i
+				rec := slog.NewRecord(h.start.Add(time.Duration(ms)*time.Millisecond), slog.LevelInfo, strconv.Itoa(ms), 0)
+				if err := h.Handle(context.Background(), rec); err != nil {
+					t.Errorf("Handle() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every delta is measured from the line written before it
+	prev := 0
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		ms, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			t.Fatalf("line = %q, want a number as the message", line)
+		}
+		if want := strings.TrimSpace(formatDelta(time.Duration(ms-prev) * time.Millisecond)); fields[0] != want {
+			t.Errorf("line = %q, want delta %s after record %d", line, want, prev)
+		}
+		prev = ms
+	}
+}
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Second, "Δ0s      "},
+		{500 * time.Nanosecond, "Δ500ns   "},
+		{999999 * time.Nanosecond, "Δ1ms     "},
+		{123456 * time.Nanosecond, "Δ123µs   "},
+		{12 * time.Millisecond, "Δ12ms    "},
+		{59999 * time.Millisecond, "Δ59.999s "},
+		{12*time.Minute + 30250*time.Millisecond, "Δ12m30s  "},
+		{time.Hour + 2*time.Minute + 3500*time.Millisecond, "Δ1h02m   "},
+		{999*time.Hour + 59*time.Minute, "Δ999h59m "},
+		{1000 * time.Hour, "Δ>999h   "},
+	}
+	for _, tt := range tests {
+		got := formatDelta(tt.d)
+		if got != tt.want {
+			t.Errorf("formatDelta(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n != 9 {
+			t.Errorf("formatDelta(%v) = %q is %d wide, want 9", tt.d, got, n)
+		}
+	}
+}
+
+// healthStyler is a LevelStyler used to test custom level resolution.
+type healthStyler struct{}
+
+func (healthStyler) LevelName(level slog.Level) string {
+	if level >= slog.LevelError {
+		return "FAIL"
+	}
+	return "OK"
+}
+
+func (healthStyler) LevelColor(level slog.Level) string {
+	if level >= slog.LevelError {
+		return colorRed
+	}
+	return ""
+}
+
+func TestHandler_LevelStyler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(NewHandler(buf, &Options{
+		Level:       slog.LevelInfo,
+		LevelStyler: healthStyler{},
+	}))
+
+	logger.Info("All good")
+	logger.Error("Broken")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "] OK    All good") {
+		t.Errorf("info line = %q, should contain uncolored, padded OK level", lines[0])
+	}
+	if !strings.Contains(lines[1], colorRed+"FAIL "+colorReset) {
+		t.Errorf("error line = %q, should contain red FAIL level", lines[1])
+	}
+
+	jsonBuf := new(bytes.Buffer)
+	slog.New(NewHandler(jsonBuf, &Options{
+		Level:       slog.LevelInfo,
+		UseJSON:     true,
+		LevelStyler: healthStyler{},
+	})).Error("Broken")
+
+	if !strings.Contains(jsonBuf.String(), `"level":"FAIL"`) {
+		t.Errorf("JSON output = %q, should contain styled level name", jsonBuf.String())
+	}
+}
+
+func TestHandler_TimeLocation(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("EET", 2*60*60))
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantTime string
+		wantAttr string
+	}{
+		{
+			name:     "Own location by default",
+			opts:     Options{TimeFormat: "15:04"},
+			wantTime: "[12:00]",
+			wantAttr: "at=2025-01-01T12:00:00+02:00",
+		},
+		{
+			name:     "UseUTC",
+			opts:     Options{TimeFormat: "15:04", UseUTC: true},
+			wantTime: "[10:00]",
+			wantAttr: "at=2025-01-01T10:00:00Z",
+		},
+		{
+			name:     "Fixed TimeLocation",
+			opts:     Options{TimeFormat: "15:04", TimeLocation: tokyo},
+			wantTime: "[19:00]",
+			wantAttr: "at=2025-01-01T19:00:00+09:00",
+		},
+		{
+			name:     "UseUTC wins over TimeLocation",
+			opts:     Options{TimeFormat: "15:04", TimeLocation: tokyo, UseUTC: true},
+			wantTime: "[10:00]",
+			wantAttr: "at=2025-01-01T10:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			h := NewHandler(buf, &tt.opts)
+
+			r := slog.NewRecord(ts, slog.LevelInfo, "Zoned", 0)
+			r.AddAttrs(slog.Time("at", ts))
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			got := buf.String()
+			if !strings.HasPrefix(got, tt.wantTime) {
+				t.Errorf("output = %q, should start with %s", got, tt.wantTime)
+			}
+			if !strings.Contains(got, tt.wantAttr) {
+				t.Errorf("output = %q, should contain %s", got, tt.wantAttr)
+			}
+		})
+	}
+}
+
+func TestHandler_SubSecondPresetsAndAttrTimeFormat(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantTime string
+		wantAttr string
+	}{
+		{
+			name:     "Millisecond preset",
+			opts:     Options{TimeFormat: TimeFormatMillis},
+			wantTime: "[12:30:45.123]",
+			wantAttr: "at=2025-01-01T12:30:45Z",
+		},
+		{
+			name:     "Microsecond preset",
+			opts:     Options{TimeFormat: TimeFormatMicros},
+			wantTime: "[12:30:45.123456]",
+			wantAttr: "at=2025-01-01T12:30:45Z",
+		},
+		{
+			name:     "Custom attr time format",
+			opts:     Options{TimeFormat: TimeFormatSeconds, AttrTimeFormat: time.RFC3339Nano},
+			wantTime: "[12:30:45]",
+			wantAttr: "at=2025-01-01T12:30:45.123456789Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			h := NewHandler(buf, &tt.opts)
+
+			r := slog.NewRecord(ts, slog.LevelInfo, "Precise", 0)
+			r.AddAttrs(slog.Time("at", ts))
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			got := buf.String()
+			if !strings.HasPrefix(got, tt.wantTime) {
+				t.Errorf("output = %q, should start with %s", got, tt.wantTime)
+			}
+			if !strings.Contains(got, tt.wantAttr) {
+				t.Errorf("output = %q, should contain %s", got, tt.wantAttr)
+			}
+		})
+	}
+}
+
+func TestHandler_ElideRepeatedTime(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		marker     string
+		wantSecond string
+	}{
+		{name: "Blank", marker: "", wantSecond: "           INFO  Second"},
+		{name: "Marker", marker: "″", wantSecond: "[″       ] INFO  Second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			h := NewHandler(buf, &Options{
+				TimeFormat:         TimeFormatSeconds,
+				ElideRepeatedTime:  true,
+				RepeatedTimeMarker: tt.marker,
+				DisableColor:       true,
+			})
+
+			for i, rec := range []slog.Record{
+				slog.NewRecord(ts, slog.LevelInfo, "First", 0),
+				slog.NewRecord(ts.Add(100*time.Millisecond), slog.LevelInfo, "Second", 0),
+				slog.NewRecord(ts.Add(time.Second), slog.LevelInfo, "Third", 0),
+			} {
+				if err := h.Handle(context.Background(), rec); err != nil {
+					t.Fatalf("Handle(%d) error = %v", i, err)
+				}
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+			}
+			if !strings.HasPrefix(lines[0], "[12:00:00] INFO  First") {
+				t.Errorf("first line = %q, should show the timestamp", lines[0])
+			}
+			if !strings.HasPrefix(lines[1], tt.wantSecond) {
+				t.Errorf("second line = %q, should start with %q", lines[1], tt.wantSecond)
+			}
+			if !strings.HasPrefix(lines[2], "[12:00:01] INFO  Third") {
+				t.Errorf("third line = %q, should show the new timestamp", lines[2])
+			}
+		})
+	}
+}
+
+func TestHandler_ElideRepeatedTimeConcurrent(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	buf := new(bytes.Buffer)
+	h := NewHandler(buf, &Options{TimeFormat: TimeFormatSeconds, ElideRepeatedTime: true, DisableColor: true})
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				sec := (g + i) % 3
+				rec := slog.NewRecord(ts.Add(time.Duration(sec)*time.Second), slog.LevelInfo, strconv.Itoa(sec), 0)
+				if err := h.Handle(context.Background(), rec); err != nil {
+					t.Errorf("Handle() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A time is elided exactly when it repeats the line written before it
+	prev := -1
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		sec, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			t.Fatalf("line = %q, want a number as the message", line)
+		}
+		elided := strings.HasPrefix(line, " ")
+		if want := sec == prev; elided != want {
+			t.Errorf("line = %q after second %d, elided = %v, want %v", line, prev, elided, want)
+		}
+		prev = sec
+	}
+}
+
+func TestHandler_NumericFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		attr     slog.Attr
+		expected string
+	}{
+		{name: "Int default", attr: slog.Int("bytes", 1234567), expected: "bytes=1234567"},
+		{name: "Int underscore", opts: Options{ThousandsSeparator: "_"}, attr: slog.Int("bytes", 1234567), expected: "bytes=1_234_567"},
+		{name: "Negative int comma", opts: Options{ThousandsSeparator: ","}, attr: slog.Int("delta", -1234567), expected: "delta=-1,234,567"},
+		{name: "Short int unchanged", opts: Options{ThousandsSeparator: ","}, attr: slog.Int("n", 999), expected: "n=999"},
+		{name: "Uint comma", opts: Options{ThousandsSeparator: ","}, attr: slog.Uint64("total", 1000), expected: "total=1,000"},
+		{name: "Float default", attr: slog.Float64("ratio", 0.1234567), expected: "ratio=0.1234567"},
+		{name: "Float precision", opts: Options{FloatPrecision: 2}, attr: slog.Float64("ratio", 0.1234567), expected: "ratio=0.12"},
+		{name: "Float precision and separator", opts: Options{FloatPrecision: 1, ThousandsSeparator: ","}, attr: slog.Float64("mean", 12345.678), expected: "mean=12,345.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			logger := slog.New(NewHandler(buf, &tt.opts))
+
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "Numbers", tt.attr)
+
+			if got := buf.String(); !strings.Contains(got, tt.expected) {
+				t.Errorf("output = %q, should contain %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_BytesFormatting(t *testing.T) {
+	payload := []byte("hello")
+	large := bytes.Repeat([]byte{0xff}, 10)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		value    []byte
+		expected string
+	}{
+		{name: "Hex default", value: payload, expected: "data=68656c6c6f"},
+		{name: "Base64", opts: Options{BytesFormat: BytesBase64}, value: payload, expected: "data=aGVsbG8="},
+		{name: "Summary", opts: Options{BytesFormat: BytesSummary}, value: large, expected: "data=<10 bytes>"},
+		{name: "Hex truncated", opts: Options{MaxBytes: 2}, value: large, expected: "data=ffff...<10 bytes>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			logger := slog.New(NewHandler(buf, &tt.opts))
+
+			logger.Info("Bytes", slog.Any("data", tt.value))
+
+			if got := buf.String(); !strings.Contains(got, tt.expected) {
+				t.Errorf("output = %q, should contain %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_AnyFormat(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type user struct {
+		Name    string   `json:"name"`
+		Tags    []string `json:"tags"`
+		Home    *address `json:"home"`
+		Secret  string   `json:"-"`
+		private int
+	}
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	loop := &node{Name: "a"}
+	loop.Next = loop
+
+	u := user{Name: "alice", Tags: []string{"admin", "ops"}, Home: &address{City: "Oulu"}, Secret: "x"}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		value    any
+		expected string
+	}{
+		{
+			name:     "JSON struct",
+			opts:     Options{AnyFormat: AnyJSON},
+			value:    u,
+			expected: `user={"name":"alice","tags":["admin","ops"],"home":{"city":"Oulu"}}`,
+		},
+		{
+			name:     "Go syntax struct",
+			opts:     Options{AnyFormat: AnyGoSyntax},
+			value:    &u,
+			expected: `user=&{Name:alice Tags:[admin ops] Home:&{City:Oulu} Secret:x}`,
+		},
+		{
+			name:     "JSON map sorted",
+			opts:     Options{AnyFormat: AnyJSON},
+			value:    map[string]int{"b": 2, "a": 1},
+			expected: `user={"a":1,"b":2}`,
+		},
+		{
+			name:     "Max elements",
+			opts:     Options{AnyFormat: AnyJSON, AnyMaxElements: 2},
+			value:    []int{1, 2, 3, 4},
+			expected: `user=[1,2,"...+2 more"]`,
+		},
+		{
+			name:     "Max depth",
+			opts:     Options{AnyFormat: AnyJSON, AnyMaxDepth: 1},
+			value:    [][]int{{1}},
+			expected: `user=["..."]`,
+		},
+		{
+			name:     "Cycle detection",
+			opts:     Options{AnyFormat: AnyGoSyntax},
+			value:    loop,
+			expected: `user=&{Name:a Next:<cycle>}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			logger := slog.New(NewHandler(buf, &tt.opts))
+
+			logger.Info("Any", slog.Any("user", tt.value))
+
+			if got := buf.String(); !strings.Contains(got, tt.expected) {
+				t.Errorf("output = %q, should contain %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_KeyFormatters(t *testing.T) {
+	formatters := NewFormatterRegistry().
+		Register("bytes", FormatByteSize).
+		Register("*_ns", FormatNanoseconds).
+		Register("request.id", func(v slog.Value) string { return "#" + v.String() })
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(NewHandler(buf, &Options{DisableColor: true, Formatters: formatters}))
+
+	logger.Info("Formatted",
+		slog.Int("bytes", 1536),
+		slog.Int("latency_ns", 1500000),
+		slog.Int("id", 7),
+	)
+
+	got := buf.String()
+	for _, want := range []string{"bytes=1.5KiB", "latency_ns=1.5ms", "id=7"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %s", got, want)
+		}
+	}
+
+	grouped := new(bytes.Buffer)
+	slog.New(NewHandler(grouped, &Options{DisableColor: true, Formatters: formatters})).
+		WithGroup("request").
+		Info("Grouped", slog.Int("id", 42), slog.Int("bytes", 3*1024*1024))
+
+	for _, want := range []string{"request.id=#42", "request.bytes=3.0MiB"} {
+		if !strings.Contains(grouped.String(), want) {
+			t.Errorf("grouped output = %q, should contain %s", grouped.String(), want)
+		}
+	}
+}
+
+// stringerAndText implements both fmt.Stringer and encoding.TextMarshaler.
+type stringerAndText struct{}
+
+func (stringerAndText) String() string               { return "from-stringer" }
+func (stringerAndText) MarshalText() ([]byte, error) { return []byte("from-text"), nil }
+func (stringerAndText) MarshalJSON() ([]byte, error) { return []byte(`"from-json"`), nil }
+
+// textAndJSON implements encoding.TextMarshaler and json.Marshaler.
+type textAndJSON struct{}
+
+func (textAndJSON) MarshalText() ([]byte, error) { return []byte("from text"), nil }
+func (textAndJSON) MarshalJSON() ([]byte, error) { return []byte(`{"from":"json"}`), nil }
+
+// namedValue has a pointer-receiver String method that panics on nil.
+type namedValue struct{ name string }
+
+func (n *namedValue) String() string { return n.name }
+
+// jsonOnly implements only json.Marshaler.
+type jsonOnly struct{}
+
+func (jsonOnly) MarshalJSON() ([]byte, error) { return []byte(`{ "from": "json" }`), nil }
+
+func TestHandler_SelfFormattingValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "Stringer first", value: stringerAndText{}, expected: "v=from-stringer"},
+		{name: "TextMarshaler before json.Marshaler", value: textAndJSON{}, expected: `v="from text"`},
+		{name: "json.Marshaler compacted", value: jsonOnly{}, expected: `v={"from":"json"}`},
+		{name: "Nil pointer ignored", value: (*namedValue)(nil), expected: "v=<nil>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := slog.New(NewHandler(buf, &Options{DisableColor: true}))
+
+			logger.Info("Self formatting", slog.Any("v", tt.value))
+
+			if got := buf.String(); !strings.Contains(got, tt.expected) {
+				t.Errorf("output = %q, should contain %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_LineFormatter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	formatter := LineFormatterFunc(func(b []byte, r RecordView) []byte {
+		b = append(b, r.Level()...)
+		b = append(b, " | "...)
+		b = append(b, r.Record.Message...)
+		b = append(b, " | "...)
+		return append(b, r.AttrsString()...)
+	})
+
+	logger := slog.New(NewHandler(buf, &Options{
+		DisableColor:  true,
+		LineFormatter: formatter,
+	})).WithGroup("req")
+
+	logger.Warn("Custom line", slog.String("path", "/a b"))
+
+	want := `WARN  | Custom line | req.path="/a b"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestHandler_Columns(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	buf := new(bytes.Buffer)
+	h := NewHandler(buf, &Options{
+		TimeFormat:   TimeFormatSeconds,
+		DisableColor: true,
+		Columns: []Column{
+			{Kind: ColumnTime},
+			{Kind: ColumnLevel, Width: 5, Align: AlignRight},
+			{Kind: ColumnName, Width: 6, Align: AlignCenter},
+			{Kind: ColumnMessage, Width: 10},
+			{Kind: ColumnAttrs, Width: 12, Truncate: TruncateStart},
+		},
+	})
+
+	logger := h.WithAttrs([]slog.Attr{slog.String(NameKey, "db")})
+
+	r := slog.NewRecord(ts, slog.LevelWarn, "Slow query detected", 0)
+	r.AddAttrs(slog.Int("rows", 12), slog.String("table", "users"))
+	if err := logger.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := "12:00:00  WARN   db   Slow qu... ...ble=users\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestColumn_Fit(t *testing.T) {
+	tests := []struct {
+		name string
+		col  Column
+		in   string
+		want string
+	}{
+		{name: "Natural width", col: Column{}, in: "abc", want: "abc"},
+		{name: "Left pad", col: Column{Width: 5}, in: "abc", want: "abc  "},
+		{name: "Right pad", col: Column{Width: 5, Align: AlignRight}, in: "abc", want: "  abc"},
+		{name: "Center pad", col: Column{Width: 6, Align: AlignCenter}, in: "ab", want: "  ab  "},
+		{name: "Truncate end", col: Column{Width: 6}, in: "abcdefgh", want: "abc..."},
+		{name: "Truncate start", col: Column{Width: 6, Truncate: TruncateStart}, in: "abcdefgh", want: "...fgh"},
+		{name: "Truncate cut", col: Column{Width: 3, Truncate: TruncateCut}, in: "abcdefgh", want: "abc"},
+		{name: "Overflow", col: Column{Width: 3, Truncate: TruncateNone}, in: "abcdefgh", want: "abcdefgh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.col.fit(tt.in); got != tt.want {
+				t.Errorf("fit(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// countingWriter counts the Write calls it receives.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestHandler_BufferedOutput(t *testing.T) {
+	for _, useJSON := range []bool{false, true} {
+		w := &countingWriter{}
+		h := NewHandler(w, &Options{DisableColor: true, UseJSON: useJSON, BufferSize: 4096, FlushInterval: time.Hour})
+		logger := slog.New(h)
+
+		for i := 0; i < 10; i++ {
+			logger.Info("Buffered", "i", i)
+		}
+		if w.writes != 0 {
+			t.Errorf("UseJSON=%v: %d writes before Flush, want 0", useJSON, w.writes)
+		}
+
+		if err := h.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+		if w.writes != 1 || strings.Count(w.String(), "Buffered") != 10 {
+			t.Errorf("UseJSON=%v: after Flush got %d writes with output %q, want 1 write of 10 records", useJSON, w.writes, w.String())
+		}
+
+		logger.Info("Pending")
+		if err := h.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if !strings.Contains(w.String(), "Pending") {
+			t.Errorf("UseJSON=%v: Close() should flush pending records", useJSON)
+		}
+
+		logger.Info("After close")
+		if !strings.Contains(w.String(), "After close") {
+			t.Errorf("UseJSON=%v: records after Close should be written directly", useJSON)
+		}
+	}
+}
+
+func TestHandler_BufferedOutputFlushesPeriodically(t *testing.T) {
+	var buf syncBuffer
+	h := NewHandler(&buf, &Options{DisableColor: true, BufferSize: 4096, FlushInterval: 10 * time.Millisecond})
+	defer func() { _ = h.Close() }()
+
+	slog.New(h).Info("Eventually written")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "Eventually written") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(buf.String(), "Eventually written") {
+		t.Error("buffered record was not flushed in the background")
+	}
+}
+
+func TestHandler_SummaryLines(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	h := NewHandler(&buf, &Options{
+		Level:        slog.LevelDebug,
+		DisableColor: true,
+		SummaryEvery: 4,
+		Now:          func() time.Time { return now },
+	})
+	logger := slog.New(h)
+
+	logger.Info("One")
+	logger.Info("Two")
+	logger.Warn("Three")
+	now = now.Add(30 * time.Second)
+	logger.Error("Four")
+	logger.Debug("Five")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("output has %d lines, want 6: %q", len(lines), buf.String())
+	}
+	if want := "— 2 info, 1 warn, 1 error in last 30s —"; lines[4] != want {
+		t.Errorf("summary = %q, want %q", lines[4], want)
+	}
+	if !strings.Contains(lines[5], "Five") {
+		t.Errorf("line after summary = %q, want the next record", lines[5])
+	}
+
+	// Interval summaries are written while idle and dimmed
+	var sbuf syncBuffer
+	h = NewHandler(&sbuf, &Options{SummaryInterval: 10 * time.Millisecond})
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(sbuf.String(), "no records in last") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	_ = h.Close()
+	if got := sbuf.String(); !strings.HasPrefix(got, StyleDim+"— no records in last ") || !strings.Contains(got, " —"+colorReset+"\n") {
+		t.Errorf("output = %q, want a dim idle summary", got)
+	}
+
+	// JSON output has no summary lines
+	buf.Reset()
+	logger = slog.New(NewHandler(&buf, &Options{UseJSON: true, SummaryEvery: 1}))
+	logger.Info("JSON")
+	if strings.Contains(buf.String(), "—") {
+		t.Errorf("JSON output = %q, want no summary line", buf.String())
+	}
+}
+
+func TestHandler_IdleSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	h := NewHandler(&buf, &Options{DisableColor: true, IdleSeparator: 10 * time.Second})
+	record := func(msg string) {
+		if err := h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, msg, 0)); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	record("First")
+	now = now.Add(5 * time.Second)
+	record("Soon after")
+	now = now.Add(42 * time.Second)
+	record("Much later")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output has %d lines, want 4: %q", len(lines), buf.String())
+	}
+	if lines[2] != "— 42s idle —" || !strings.Contains(lines[3], "Much later") {
+		t.Errorf("lines = %q, want an idle marker before the last record", lines)
+	}
+}
+
+func TestHandler_LineNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{DisableColor: true, LineNumbers: true, SummaryEvery: 2})
+	logger := slog.New(h)
+	logger.Info("First")
+	slog.New(h.WithAttrs([]slog.Attr{slog.Int("n", 2)})).Info("Second")
+	logger.Info("Third")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output has %d lines, want 4: %q", len(lines), buf.String())
+	}
+	for i, prefix := range []string{"    1 [", "    2 [", "— ", "    3 ["} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+
+	buf.Reset()
+	h = NewHandler(&buf, &Options{LineNumbers: true})
+	slog.New(h).Info("Colored")
+	if got := buf.String(); !strings.HasPrefix(got, StyleDim+"    1"+colorReset+" [") {
+		t.Errorf("output = %q, want a dim line number", got)
+	}
+}
+
+func TestHandler_OnErrorAndFallback(t *testing.T) {
+	var reported []error
+	var fallback bytes.Buffer
+
+	h := NewHandler(failingWriter{}, &Options{
+		DisableColor:   true,
+		OnError:        func(err error) { reported = append(reported, err) },
+		FallbackWriter: &fallback,
+	})
+	err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "Disk is full", 0))
+
+	if err != nil {
+		t.Errorf("Handle() error = %v, want nil when the fallback succeeds", err)
+	}
+	if len(reported) != 1 || reported[0].Error() != "disk full" {
+		t.Errorf("OnError received %v, want the primary write error", reported)
+	}
+	if !strings.Contains(fallback.String(), "Disk is full") {
+		t.Errorf("fallback = %q, should contain the record", fallback.String())
+	}
+
+	// Without a fallback the error is still returned
+	h = NewHandler(failingWriter{}, &Options{DisableColor: true, OnError: func(error) {}})
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "Lost", 0)); err == nil {
+		t.Error("Handle() error = nil, want the write error")
+	}
+}
+
+func TestHandler_InjectedClock(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	h := NewHandler(&buf, &Options{
+		DisableColor: true,
+		UseUTC:       true,
+		TimeFormat:   TimeFormatSeconds,
+		TimeDelta:    DeltaBeside,
+		Now:          func() time.Time { return now },
+	})
+
+	// Records without a time are stamped by the clock
+	now = now.Add(1500 * time.Millisecond)
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "Virtual", 0)); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "[12:00:01] Δ1.5s") {
+		t.Errorf("output = %q, want clock time and delta from handler creation", got)
+	}
+}
+
+func TestHandler_AddSequence(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{DisableColor: true, AddSequence: true})
+	logger := slog.New(h)
+
+	logger.Info("First")
+	logger.With("component", "db").Info("Second")
+	logger.Info("Third")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, line := range lines {
+		if want := fmt.Sprintf("seq=%d", i+1); !strings.Contains(line, want) {
+			t.Errorf("line %d = %q, want %s", i, line, want)
+		}
+	}
+}
+
+func TestHandler_AddProcessInfo(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		DisableColor:   true,
+		AddProcessInfo: true,
+		Service:        "billing",
+		Version:        "1.4.2",
+		Environment:    "staging",
+	}))
+	logger.WithGroup("req").Info("Started", "id", 7)
+
+	got := buf.String()
+	host, _ := os.Hostname()
+	for _, want := range []string{
+		"host=" + host,
+		fmt.Sprintf("pid=%d", os.Getpid()),
+		"service=billing",
+		"version=1.4.2",
+		"env=staging",
+		"req.id=7",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %q", got, want)
+		}
+	}
+}
+
+func TestHandler_GroupDoesNotRequalifyEarlierAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{DisableColor: true}))
+	logger.With("app", "api").WithGroup("req").With("id", 7).WithGroup("user").Info("Grouped", "name", "alice")
+
+	got := buf.String()
+	for _, want := range []string{" app=api", " req.id=7", " req.user.name=alice"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %q", got, want)
+		}
+	}
+}
+
+func TestHandler_AddBuildInfo(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(NewHandler(&buf, &Options{DisableColor: true, AddBuildInfo: true})).Info("Started")
+
+	// Test binaries carry no VCS settings, but always know their Go version
+	if want := "go=" + runtime.Version(); !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, should contain %q", buf.String(), want)
+	}
+}
+
+func TestHandler_WithMinLevel(t *testing.T) {
+	for _, useJSON := range []bool{false, true} {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelInfo, DisableColor: true, UseJSON: useJSON}))
+
+		logger.DebugContext(context.Background(), "Hidden debug")
+		ctx := WithMinLevel(context.Background(), slog.LevelDebug)
+		logger.DebugContext(ctx, "Request debug")
+		quiet := WithMinLevel(context.Background(), slog.LevelError)
+		logger.WarnContext(quiet, "Quiet warning")
+
+		out := buf.String()
+		if strings.Contains(out, "Hidden debug") || strings.Contains(out, "Quiet warning") {
+			t.Errorf("UseJSON=%v: records below the effective level were logged: %q", useJSON, out)
+		}
+		if !strings.Contains(out, "Request debug") {
+			t.Errorf("UseJSON=%v: debug record with WithMinLevel missing, got %q", useJSON, out)
+		}
+	}
+}
+
+func TestHandler_MaxLineWidth(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		Deterministic: true,
+		TimeFormat:    TimeFormatSeconds,
+		MessageWidth:  6,
+		MaxLineWidth:  40,
+	}))
+	logger.Info("Served", "method", "GET", "path", "/users", "status", 200, "body", strings.Repeat("x", 40), "ms", 3)
+
+	want := "[00:00:00] INFO  Served body=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx\n" +
+		"                        method=GET ms=3\n" +
+		"                        path=/users\n" +
+		"                        status=200\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+type countingValuer struct{ n *int }
+
+func (c countingValuer) LogValue() slog.Value {
+	*c.n++
+	return slog.IntValue(*c.n)
+}
+
+func TestHandler_PreformattedAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	base := slog.New(NewHandler(&buf, &Options{DisableColor: true}))
+	logger := base.With("app", "api", "build", countingValuer{&calls}).WithGroup("").WithGroup("req")
+	sibling := base.With("app", "worker")
+
+	logger.Info("First", "id", 1)
+	logger.Info("Second", "id", 2)
+	sibling.Info("Third")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, want := range []string{" app=api build=1 req.id=1", " app=api build=1 req.id=2", " app=worker"} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("line %d = %q, want suffix %q", i, lines[i], want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("LogValue called %d times, want once when the handler is derived", calls)
+	}
+}
+
+func TestHandler_ConcurrentRecords(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{DisableColor: true, TimeFormat: TimeFormatSeconds, MessageWidth: 6})
+
+	const workers, records = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger := slog.New(h.WithAttrs([]slog.Attr{slog.Int("worker", w)}))
+			for i := 0; i < records; i++ {
+				logger.Info("Record", "i", i, "pad", strings.Repeat("x", 64))
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != workers*records {
+		t.Fatalf("got %d lines, want %d", len(lines), workers*records)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, " INFO  Record worker=") || !strings.HasSuffix(line, " pad="+strings.Repeat("x", 64)) {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestHandler_SiblingDerivation(t *testing.T) {
+	var buf bytes.Buffer
+	parent := slog.New(NewHandler(&buf, &Options{DisableColor: true})).With("app", "api").WithGroup("req").WithGroup("http")
+
+	// Siblings derived concurrently from one parent must not see each
+	// other's attributes or groups; run with -race to check for sharing
+	var mu sync.Mutex
+	want := map[string]string{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("g%d", i)
+			logger := parent.With("sibling", i).WithGroup(name).With("n", i)
+			for j := 0; j < 50; j++ {
+				logger.Info("Derived", "j", j)
+			}
+			mu.Lock()
+			want[name] = fmt.Sprintf(" app=api req.http.sibling=%d req.http.%s.n=%d req.http.%s.j=", i, name, i, name)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		matched := ""
+		for name, attrs := range want {
+			if strings.Contains(line, attrs) {
+				matched = name
+			}
+		}
+		if matched == "" {
+			t.Fatalf("line %q has attributes of no single sibling", line)
+		}
+		counts[matched]++
+	}
+	for name := range want {
+		if counts[name] != 50 {
+			t.Errorf("sibling %s logged %d lines, want 50", name, counts[name])
+		}
+	}
+}
+
+func TestTimeCache(t *testing.T) {
+	if c := newTimeCache(TimeFormatSeconds); c == nil || c.unit != time.Second {
+		t.Errorf("seconds layout cached per %v", c)
+	}
+	if c := newTimeCache(TimeFormatMillis); c == nil || c.unit != time.Millisecond {
+		t.Errorf("millis layout cached per %v", c)
+	}
+	if c := newTimeCache(TimeFormatMicros); c != nil {
+		t.Errorf("micros layout cached per %v", c.unit)
+	}
+
+	c := newTimeCache(TimeFormatMillis)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*3600)
+	for _, tm := range []time.Time{
+		base, base.Add(500 * time.Microsecond), base.Add(time.Millisecond),
+		base.In(tokyo), base.Add(-time.Millisecond), time.Time{},
+	} {
+		if got, want := string(c.append(nil, tm)), tm.Format(TimeFormatMillis); got != want {
+			t.Errorf("append(%v) = %q, want %q", tm, got, want)
+		}
+	}
+}
+
+// chunkWriter records each Write call separately.
+type chunkWriter struct {
+	chunks []string
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func TestHandler_WritesWholeRecords(t *testing.T) {
+	w := &chunkWriter{}
+	h := NewHandler(w, &Options{DisableColor: true, BufferSize: 100, FlushInterval: time.Hour, GroupStyle: GroupNested})
+	logger := slog.New(h)
+	for i := 0; i < 10; i++ {
+		logger.Info("Record", "i", i, "pad", strings.Repeat("x", 10*i))
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := 0
+	for _, chunk := range w.chunks {
+		if !strings.HasPrefix(chunk, "[") || !strings.HasSuffix(chunk, "\n") {
+			t.Errorf("write of a partial record: %q", chunk)
+		}
+		records += strings.Count(chunk, "Record")
+	}
+	if records != 10 {
+		t.Errorf("wrote %d records, want 10", records)
+	}
+}
+
+func TestHandler_LockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	const writers, records = 4, 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		// Separate opens of the file stand in for separate processes
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+		logger := slog.New(NewHandler(f, &Options{DisableColor: true, LockFile: true, BufferSize: 512}))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < records; j++ {
+				logger.Info("Locked", "writer", i, "pad", strings.Repeat("x", 40))
+			}
+			_ = logger.Handler().(*Handler).Close()
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != writers*records {
+		t.Fatalf("got %d lines, want %d", len(lines), writers*records)
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, " pad="+strings.Repeat("x", 40)) {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+// syncWriter counts Sync calls and the bytes written before each.
+type syncWriter struct {
+	bytes.Buffer
+	syncs  int
+	synced int
+}
+
+func (w *syncWriter) Sync() error {
+	w.syncs++
+	w.synced = w.Len()
+	return nil
+}
+
+func TestHandler_SyncPolicy(t *testing.T) {
+	tests := []struct {
+		policy     SyncPolicy
+		afterError int
+		afterClose int
+	}{
+		{SyncOnClose, 0, 1},
+		{SyncErrors, 1, 2},
+		{SyncNever, 0, 0},
+	}
+	for _, tt := range tests {
+		for _, useJSON := range []bool{false, true} {
+			w := &syncWriter{}
+			h := NewHandler(w, &Options{Sync: tt.policy, UseJSON: useJSON, BufferSize: 4096, FlushInterval: time.Hour})
+			logger := slog.New(h)
+
+			logger.Info("Routine")
+			logger.Error("Failed")
+			if w.syncs != tt.afterError {
+				t.Errorf("policy %d, JSON %v: %d syncs after error, want %d", tt.policy, useJSON, w.syncs, tt.afterError)
+			}
+			if tt.afterError > 0 && w.synced != w.Len() {
+				t.Errorf("policy %d, JSON %v: synced before the error record was flushed", tt.policy, useJSON)
+			}
+
+			if err := h.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if w.syncs != tt.afterClose {
+				t.Errorf("policy %d, JSON %v: %d syncs after Close, want %d", tt.policy, useJSON, w.syncs, tt.afterClose)
+			}
+		}
+	}
+}
+
+func TestHandler_WithOptions(t *testing.T) {
+	var buf bytes.Buffer
+	var other bytes.Buffer
+	base := NewHandler(&buf, &Options{DisableColor: true, TimeFormat: TimeFormatSeconds, Level: slog.LevelDebug, BufferSize: 1024})
+	parent := base.WithAttrs([]slog.Attr{slog.String("app", "api")}).WithGroup("req").(*Handler)
+
+	quiet := parent.WithOptions(func(o *Options) {
+		o.Level = slog.LevelWarn
+		o.MessageWidth = 5
+		o.Deterministic = true
+		o.Writer = &other // ignored, the output is shared
+	})
+
+	slog.New(quiet).Info("Hidden")
+	slog.New(quiet).Warn("Shown", "id", 1)
+	slog.New(parent).Debug("Parent", "id", 2)
+	if err := base.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[00:00:00] WARN  Shown app=api req.id=1\n"
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if len(lines) != 3 || lines[0] != want {
+		t.Fatalf("output = %q, want first line %q", buf.String(), want)
+	}
+	if !strings.HasSuffix(lines[1], "Parent"+strings.Repeat(" ", 34)+" app=api req.id=2\n") {
+		t.Errorf("parent line = %q, should keep its own options", lines[1])
+	}
+	if other.Len() != 0 || quiet.out != base.out || quiet.metrics != base.metrics {
+		t.Error("WithOptions should share the output and metrics")
+	}
+}
+
+func TestHandler_OnRecord(t *testing.T) {
+	type result struct {
+		msg string
+		n   int
+		err error
+	}
+	for _, useJSON := range []bool{false, true} {
+		var buf bytes.Buffer
+		var results []result
+		h := NewHandler(&buf, &Options{
+			DisableColor:  true,
+			Deterministic: true,
+			UseJSON:       useJSON,
+			OnRecord: RecordHooks{
+				Before: func(_ context.Context, r slog.Record) (slog.Record, bool) {
+					if r.Message == "Vetoed" {
+						return r, false
+					}
+					// Drop the secret, keep the rest and add a tag
+					r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+					r.Attrs(func(a slog.Attr) bool {
+						if a.Key != "password" {
+							r2.AddAttrs(a)
+						}
+						return true
+					})
+					r2.AddAttrs(slog.String("hooked", "yes"))
+					return r2, true
+				},
+				After: func(_ context.Context, r slog.Record, n int, err error) {
+					results = append(results, result{r.Message, n, err})
+				},
+			},
+		})
+
+		logger := slog.New(h)
+		logger.Info("Login", "user", "ann", "password", "hunter2")
+		logger.Info("Vetoed")
+
+		got := buf.String()
+		if strings.Contains(got, "hunter2") || strings.Contains(got, "Vetoed") || !strings.Contains(got, "hooked") {
+			t.Errorf("JSON %v: output = %q, want the password and vetoed record dropped and the tag added", useJSON, got)
+		}
+		if len(results) != 1 || results[0].msg != "Login" || results[0].err != nil {
+			t.Fatalf("JSON %v: After calls = %v, want one for Login", useJSON, results)
+		}
+		if wantN := len(got); results[0].n != wantN {
+			t.Errorf("JSON %v: After n = %d, want %d", useJSON, results[0].n, wantN)
+		}
+	}
+}
+
+// fatalStyler shows levels above Error as FATAL on a red background.
+type fatalStyler struct{}
+
+func (fatalStyler) LevelName(level slog.Level) string {
+	if level > slog.LevelError {
+		return "FATAL"
+	}
+	return DefaultLevelStyler.LevelName(level)
+}
+
+func (fatalStyler) LevelColor(level slog.Level) string {
+	if level > slog.LevelError {
+		return StyleBold + StyleWhite + StyleBgRed
+	}
+	return DefaultLevelStyler.LevelColor(level)
+}
+
+func TestHandler_TimeAndKeyStyles(t *testing.T) {
+	opts := Options{
+		TimeFormat:  TimeFormatSeconds,
+		AddSource:   false,
+		LevelStyler: fatalStyler{},
+		TimeStyle:   StyleDim,
+		KeyStyle:    StyleDim,
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &opts)).WithGroup("req")
+	logger.Log(context.Background(), slog.LevelError+4, "Crashed", "id", 7)
+
+	got := buf.String()
+	if !strings.HasPrefix(got, StyleDim+"[") {
+		t.Errorf("output = %q, should start with a dimmed time", got)
+	}
+	for _, want := range []string{
+		"]" + colorReset + " ",
+		StyleBold + StyleWhite + StyleBgRed + "FATAL" + colorReset,
+		" " + StyleDim + "req.id=" + colorReset + "7\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %q", got, want)
+		}
+	}
+
+	// Nested and bracketed layouts style keys too
+	for _, style := range []GroupStyle{GroupNested, GroupBracketed} {
+		buf.Reset()
+		o := opts
+		o.GroupStyle = style
+		slog.New(NewHandler(&buf, &o)).Info("Grouped", slog.Group("req", slog.Int("id", 7)))
+		if !strings.Contains(buf.String(), StyleDim+"id="+colorReset+"7") {
+			t.Errorf("group style %d: output = %q, want a styled key", style, buf.String())
+		}
+	}
+
+	buf.Reset()
+	opts.DisableColor = true
+	slog.New(NewHandler(&buf, &opts)).Info("Plain", "id", 7)
+	if strings.Contains(buf.String(), "\033") {
+		t.Errorf("output = %q, want no styles with color disabled", buf.String())
+	}
+}
+
+func TestHandler_ValueAndMessageStyles(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		MessageWidth: 8,
+		KeyStyle:     StyleDim + StyleCyan,
+		StringStyle:  StyleGreen,
+		NumberStyle:  StyleMagenta,
+		TintMessage:  true,
+	})
+	slog.New(h).Warn("Disk low", "mount", "/var", "free", 0.5, "ok", false, "status", 503)
+
+	got := buf.String()
+	for _, want := range []string{
+		colorYellow + "Disk low" + colorReset + " ",
+		StyleDim + StyleCyan + "mount=" + colorReset + StyleGreen + "/var" + colorReset,
+		"free=" + colorReset + StyleMagenta + "0.5" + colorReset,
+		"ok=" + colorReset + "false",
+		// HTTP semantics win over the number style
+		"status=" + colorReset + StyleRed + "503" + colorReset,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, should contain %q", got, want)
+		}
+	}
+
+	// Tinted messages are padded outside the style and truncated inside it
+	buf.Reset()
+	slog.New(h).Info("Much too long")
+	if want := colorBlue + "Much ..." + colorReset + "\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, should contain %q", buf.String(), want)
+	}
+	buf.Reset()
+	slog.New(h).Info("Short")
+	if want := colorBlue + "Short" + colorReset + "   \n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, should contain %q", buf.String(), want)
+	}
+}
+
+func TestHandler_TraceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewContextLogger(slog.New(NewHandler(&buf, &Options{Level: LevelTrace, MessageWidth: 5})))
+	logger.Trace(context.Background(), "Enter", slog.Int("depth", 3))
+
+	want := " " + StyleDim + colorGray + "TRACE" + colorReset + " Enter depth=3"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("output = %q, should contain %q", got, want)
+	}
+
+	buf.Reset()
+	h := NewHandler(&buf, &Options{Level: LevelTrace, UseJSON: true})
+	slog.New(h).Log(context.Background(), LevelTrace, "Enter")
+	if !strings.Contains(buf.String(), `"level":"TRACE"`) {
+		t.Errorf("JSON output = %q, want level TRACE", buf.String())
+	}
+
+	// Enabled for one request while the handler stays at Info
+	buf.Reset()
+	h = NewHandler(&buf, &Options{UseJSON: true})
+	slog.New(h).Log(WithMinLevel(context.Background(), LevelTrace), LevelTrace, "Enter")
+	if !strings.Contains(buf.String(), `"level":"TRACE"`) {
+		t.Errorf("JSON output with WithMinLevel = %q, want level TRACE", buf.String())
+	}
+}
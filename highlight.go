@@ -0,0 +1,317 @@
+package humanlog
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Styles for highlight rules, level colors and the TimeStyle and KeyStyle
+// options. Any ANSI SGR sequence can be used, and styles can be
+// concatenated, e.g. StyleBold + StyleWhite + StyleBgRed.
+const (
+	StyleBold      = "\033[1m"
+	StyleDim       = "\033[2m"
+	StyleUnderline = "\033[4m"
+	StyleReverse   = "\033[7m"
+
+	StyleRed     = colorRed
+	StyleGreen   = "\033[32m"
+	StyleYellow  = colorYellow
+	StyleBlue    = colorBlue
+	StyleMagenta = "\033[35m"
+	StyleCyan    = "\033[36m"
+	StyleWhite   = "\033[97m"
+	StyleGray    = colorGray
+
+	StyleBgRed     = "\033[41m"
+	StyleBgGreen   = "\033[42m"
+	StyleBgYellow  = "\033[43m"
+	StyleBgBlue    = "\033[44m"
+	StyleBgMagenta = "\033[45m"
+	StyleBgCyan    = "\033[46m"
+	StyleBgGray    = "\033[100m"
+)
+
+// Comparison is the operator of a HighlightRule.
+type Comparison int
+
+const (
+	// CompareAlways matches every value of the key.
+	CompareAlways Comparison = iota
+	// CompareEqual matches values equal to the threshold.
+	CompareEqual
+	// CompareNotEqual matches values not equal to the threshold.
+	CompareNotEqual
+	// CompareGreater matches values above the threshold.
+	CompareGreater
+	// CompareGreaterEqual matches values at or above the threshold.
+	CompareGreaterEqual
+	// CompareLess matches values below the threshold.
+	CompareLess
+	// CompareLessEqual matches values at or below the threshold.
+	CompareLessEqual
+)
+
+// HighlightRule styles the values of matching attributes, so anomalies
+// stand out, e.g. latencies over a second in red:
+//
+//	humanlog.HighlightRule{Key: "latency", Op: humanlog.CompareGreater, Threshold: time.Second, Style: humanlog.StyleRed}
+type HighlightRule struct {
+	// Key is matched like a FormatterRegistry pattern: against the
+	// group-qualified and the bare key, optionally with path.Match globs.
+	Key string
+
+	// Op compares the value with Threshold.
+	Op Comparison
+
+	// Threshold is the value compared against. A time.Duration compares
+	// durations, also parsed from strings such as "1.5s"; numbers compare
+	// numeric values, also parsed from strings; a string compares text.
+	// Values that cannot be converted do not match.
+	Threshold any
+
+	// Style is the ANSI sequence the value is rendered with.
+	Style string
+}
+
+// matches reports whether the rule applies to the value of key.
+func (hr HighlightRule) matches(key string, v slog.Value) bool {
+	if !matchKey(hr.Key, key) {
+		return false
+	}
+	if hr.Op == CompareAlways {
+		return true
+	}
+	cmp, ok := compareValue(v, hr.Threshold)
+	if !ok {
+		return false
+	}
+	switch hr.Op {
+	case CompareEqual:
+		return cmp == 0
+	case CompareNotEqual:
+		return cmp != 0
+	case CompareGreater:
+		return cmp > 0
+	case CompareGreaterEqual:
+		return cmp >= 0
+	case CompareLess:
+		return cmp < 0
+	case CompareLessEqual:
+		return cmp <= 0
+	}
+	return false
+}
+
+// matchKey reports whether pattern matches the qualified key or its last
+// segment.
+func matchKey(pattern, key string) bool {
+	leaf := key
+	if i := strings.LastIndexByte(key, '.'); i != -1 {
+		leaf = key[i+1:]
+	}
+	if pattern == key || pattern == leaf {
+		return true
+	}
+	if ok, _ := path.Match(pattern, key); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, leaf)
+	return ok
+}
+
+// compareValue compares v with threshold, converting v to the
+// threshold's type, and reports whether they were comparable.
+func compareValue(v slog.Value, threshold any) (int, bool) {
+	v = v.Resolve()
+	switch t := threshold.(type) {
+	case time.Duration:
+		var d time.Duration
+		switch v.Kind() {
+		case slog.KindDuration:
+			d = v.Duration()
+		case slog.KindString:
+			var err error
+			if d, err = time.ParseDuration(v.String()); err != nil {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+		return compareOrdered(d, t), true
+	case string:
+		return strings.Compare(v.String(), t), true
+	}
+
+	limit, ok := toFloat(threshold)
+	if !ok {
+		return 0, false
+	}
+	var n float64
+	switch v.Kind() {
+	case slog.KindInt64:
+		n = float64(v.Int64())
+	case slog.KindUint64:
+		n = float64(v.Uint64())
+	case slog.KindFloat64:
+		n = v.Float64()
+	case slog.KindString:
+		var err error
+		if n, err = strconv.ParseFloat(v.String(), 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return compareOrdered(n, limit), true
+}
+
+// toFloat converts a numeric threshold.
+func toFloat(x any) (float64, bool) {
+	switch n := x.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// highlightStyle returns the style of the first rule in opts.Highlights
+// that matches the attribute, or "" if none does.
+func highlightStyle(key string, v slog.Value, opts *Options) string {
+	for _, rule := range opts.Highlights {
+		if rule.matches(key, v) {
+			return rule.Style
+		}
+	}
+	return ""
+}
+
+// HighlightPattern styles every match of a regular expression anywhere in
+// the rendered line, like grep --color, e.g. to follow one request ID
+// through live output.
+type HighlightPattern struct {
+	// Regexp is matched against the visible text of the line, ignoring
+	// the ANSI sequences the handler adds.
+	Regexp *regexp.Regexp
+
+	// Style is the ANSI sequence the matches are rendered with.
+	Style string
+}
+
+// highlightPatterns styles the matches of patterns in line. Matches are
+// found in the visible text, so they may span colored fields; where
+// patterns overlap the earlier one wins. After each match the styles that
+// were active in the line are restored.
+func highlightPatterns(line string, patterns []HighlightPattern) string {
+	// Map the visible text back to offsets in line
+	var visible strings.Builder
+	var offsets []int
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			if loc := ansiSequence.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+				i += loc[1]
+				continue
+			}
+		}
+		visible.WriteByte(line[i])
+		offsets = append(offsets, i)
+		i++
+	}
+	offsets = append(offsets, len(line))
+	text := visible.String()
+
+	type span struct {
+		from, to   int // in the visible text
+		start, end int // in line
+		style      string
+	}
+	var spans []span
+	for _, p := range patterns {
+		if p.Regexp == nil {
+			continue
+		}
+		for _, loc := range p.Regexp.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			overlaps := false
+			for _, sp := range spans {
+				if loc[0] < sp.to && sp.from < loc[1] {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				spans = append(spans, span{loc[0], loc[1], offsets[loc[0]], offsets[loc[1]-1] + 1, p.Style})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return line
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var sb strings.Builder
+	active := ""
+	last := 0
+	// copyStyled copies line up to end, tracking the active styles and
+	// reapplying style after any sequence that would override it
+	copyStyled := func(end int, style string) {
+		for last < end {
+			if line[last] == '\x1b' {
+				if loc := ansiSequence.FindStringIndex(line[last:]); loc != nil && loc[0] == 0 {
+					seq := line[last : last+loc[1]]
+					if seq == colorReset || seq == "\x1b[m" {
+						active = ""
+					} else if strings.HasSuffix(seq, "m") {
+						active += seq
+					}
+					sb.WriteString(seq)
+					sb.WriteString(style)
+					last += loc[1]
+					continue
+				}
+			}
+			sb.WriteByte(line[last])
+			last++
+		}
+	}
+	for _, sp := range spans {
+		copyStyled(sp.start, "")
+		sb.WriteString(sp.style)
+		copyStyled(sp.end, sp.style)
+		sb.WriteString(colorReset)
+		sb.WriteString(active)
+	}
+	copyStyled(len(line), "")
+	return sb.String()
+}
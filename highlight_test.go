@@ -0,0 +1,136 @@
+package humanlog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHighlights(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		TimeFormat:   TimeFormatSeconds,
+		MessageWidth: 4,
+		Highlights: []HighlightRule{
+			{Key: "latency", Op: CompareGreater, Threshold: time.Second, Style: StyleRed},
+			{Key: "latency", Op: CompareGreater, Threshold: 200 * time.Millisecond, Style: StyleYellow},
+			{Key: "*.rows", Op: CompareGreaterEqual, Threshold: 1000, Style: StyleMagenta},
+			{Key: "err", Style: StyleBold},
+		},
+	}))
+
+	logger.Info("Req", "latency", 2*time.Second, "err", errors.New("boom"))
+	logger.Info("Req", "latency", "300ms", slog.Group("db", "rows", 5000))
+	logger.Info("Req", "latency", 10*time.Millisecond, slog.Group("db", "rows", 5), "rows", 5000)
+
+	lines := strings.Split(buf.String(), "\n")
+	want := []string{
+		"latency=" + StyleRed + "2s" + colorReset + " err=" + StyleBold + `"boom"` + colorReset,
+		"latency=" + StyleYellow + "300ms" + colorReset + " db.rows=" + StyleMagenta + "5000" + colorReset,
+		"latency=10ms db.rows=5 rows=5000",
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(lines[i], w) {
+			t.Errorf("line %d = %q, want suffix %q", i, lines[i], w)
+		}
+	}
+}
+
+func TestHighlights_DisableColor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		DisableColor: true,
+		Highlights:   []HighlightRule{{Key: "err", Style: StyleBold}},
+	}))
+	logger.Info("Failed", "err", "boom")
+	if strings.Contains(buf.String(), "\033") {
+		t.Errorf("highlighted with color disabled: %q", buf.String())
+	}
+}
+
+func TestHighlightPatterns(t *testing.T) {
+	patterns := []HighlightPattern{
+		{Regexp: regexp.MustCompile(`req-[0-9]+`), Style: StyleReverse},
+		{Regexp: regexp.MustCompile(`[0-9]+`), Style: StyleCyan},
+	}
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "plain",
+			line: "Done id=req-42 n=7\n",
+			want: "Done id=" + StyleReverse + "req-42" + colorReset + " n=" + StyleCyan + "7" + colorReset + "\n",
+		},
+		{
+			name: "restores active style",
+			line: colorRed + "ERROR req-1 done" + colorReset + "\n",
+			want: colorRed + "ERROR " + StyleReverse + "req-1" + colorReset + colorRed + " done" + colorReset + "\n",
+		},
+		{
+			name: "spans sequences",
+			line: "req-" + colorBlue + "9" + colorReset + "\n",
+			want: StyleReverse + "req-" + colorBlue + StyleReverse + "9" + colorReset + colorBlue + colorReset + "\n",
+		},
+		{
+			name: "no match",
+			line: "Done\n",
+			want: "Done\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightPatterns(tt.line, patterns); got != tt.want {
+				t.Errorf("highlightPatterns(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_HighlightPatterns(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		DisableColor:      true,
+		HighlightPatterns: []HighlightPattern{{Regexp: regexp.MustCompile("abc"), Style: StyleBold}},
+	}))
+	logger.Info("Got abc")
+	if strings.Contains(buf.String(), "\033") {
+		t.Errorf("highlighted with color disabled: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger = slog.New(NewHandler(&buf, &Options{
+		TimeFormat:        TimeFormatSeconds,
+		HighlightPatterns: []HighlightPattern{{Regexp: regexp.MustCompile("abc"), Style: StyleBold}},
+	}))
+	logger.Info("Got abc", "id", "xabcx")
+	if n := strings.Count(buf.String(), StyleBold+"abc"+colorReset); n != 2 {
+		t.Errorf("got %d highlights in %q, want 2", n, buf.String())
+	}
+}
+
+func TestHandler_HighlightPatternsInHead(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		TimeDelta:         DeltaReplace,
+		LineNumbers:       true,
+		HighlightPatterns: []HighlightPattern{{Regexp: regexp.MustCompile(`Δ\S+`), Style: StyleBold}},
+	}))
+	logger.Info("First")
+	logger.Info("Second")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, StyleBold+"Δ") {
+			t.Errorf("line = %q, want the delta column highlighted", line)
+		}
+	}
+}
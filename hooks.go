@@ -0,0 +1,40 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RecordHooks extend a Handler without wrapping it in another
+// slog.Handler. Either hook may be nil.
+type RecordHooks struct {
+	// Before is called with every enabled record before it is formatted,
+	// after the handler has stamped it with the time, sequence number,
+	// context attributes and span. It returns the record to log, which
+	// may be r with attributes added by AddAttrs or a new record with
+	// attributes changed or dropped, and false to drop the record.
+	// Attributes added with Logger.With are not part of r.
+	Before func(ctx context.Context, r slog.Record) (slog.Record, bool)
+
+	// After is called once the record has been written, with the length
+	// of the formatted line and the error Handle returns. In JSON mode n
+	// is the length of the JSON record written, and setting After
+	// serializes the formatting of JSON records.
+	After func(ctx context.Context, r slog.Record, n int, err error)
+}
+
+// before runs the Before hook, if any.
+func (hk *RecordHooks) before(ctx context.Context, r slog.Record) (slog.Record, bool) {
+	if hk.Before == nil {
+		return r, true
+	}
+	return hk.Before(ctx, r)
+}
+
+// after runs the After hook, if any, and returns err.
+func (hk *RecordHooks) after(ctx context.Context, r slog.Record, n int, err error) error {
+	if hk.After != nil {
+		hk.After(ctx, r, n, err)
+	}
+	return err
+}
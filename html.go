@@ -0,0 +1,233 @@
+package humanlog
+
+import (
+	"context"
+	"html"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HTMLStyle is the stylesheet used by HTMLHandler documents. Pages that
+// embed fragments (HTMLOptions.Fragment) can include it to get the same
+// look.
+const HTMLStyle = `.humanlog{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:13px;line-height:1.4;background:#1e1e1e;color:#d4d4d4;padding:4px 0}
+.humanlog .rec{padding:1px 8px;border-left:4px solid transparent;white-space:pre-wrap;word-break:break-word}
+.humanlog .rec:hover{background:#2a2d2e}
+.humanlog .time{color:#808080}
+.humanlog .level{font-weight:bold;display:inline-block;min-width:5ch}
+.humanlog .msg{color:#ffffff}
+.humanlog .lvl-debug{border-color:#808080}.humanlog .lvl-debug .level{color:#808080}
+.humanlog .lvl-info{border-color:#3794ff}.humanlog .lvl-info .level{color:#3794ff}
+.humanlog .lvl-warn{border-color:#cca700;background:#2b2611}.humanlog .lvl-warn .level{color:#cca700}
+.humanlog .lvl-error{border-color:#f14c4c;background:#2d1a1a}.humanlog .lvl-error .level{color:#f14c4c}
+.humanlog details{display:inline}
+.humanlog summary{display:inline;cursor:pointer;color:#808080}
+.humanlog table{border-collapse:collapse;margin:2px 0 2px 4ch}
+.humanlog th{text-align:left;font-weight:normal;color:#9cdcfe;padding:0 2ch 0 0;vertical-align:top}
+.humanlog td{padding:0;color:#ce9178}
+`
+
+// HTMLOptions configures an HTMLHandler.
+type HTMLOptions struct {
+	// Level is the minimum level rendered. Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// Title is the document title. Default: "Log"
+	Title string
+
+	// TimeFormat is the layout of the time column. Default: "15:04:05.000"
+	TimeFormat string
+
+	// Fragment writes only the log rows wrapped in a <div class="humanlog">,
+	// without the surrounding document and stylesheet, for embedding in an
+	// existing page.
+	Fragment bool
+}
+
+// HTMLHandler is a slog.Handler that renders records as a styled HTML
+// log: one monospace row per record, colored by level, with attributes in
+// a collapsible table. The document header is written with the first
+// record and Close writes the footer, so the output is a complete page
+// suitable for dashboards or CI artifacts.
+type HTMLHandler struct {
+	out    *htmlOutput
+	opts   HTMLOptions
+	attrs  []slog.Attr
+	groups []string
+}
+
+// htmlOutput is the document shared by a handler and every handler
+// derived from it.
+type htmlOutput struct {
+	mu      sync.Mutex
+	w       io.Writer
+	started bool
+	closed  bool
+}
+
+// NewHTMLHandler creates an HTMLHandler writing to w.
+func NewHTMLHandler(w io.Writer, opts HTMLOptions) *HTMLHandler {
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.Title == "" {
+		opts.Title = "Log"
+	}
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = "15:04:05.000"
+	}
+	return &HTMLHandler{out: &htmlOutput{w: w}, opts: opts}
+}
+
+// Enabled reports whether records at the given level are rendered.
+func (h *HTMLHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle renders the record as a row of the log.
+func (h *HTMLHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = flattenAttrs(attrs, prefix, a)
+		return true
+	})
+	return h.out.write(&h.opts, formatHTMLRecord(&h.opts, r, attrs))
+}
+
+// WithAttrs returns a new handler whose rows include the given attributes.
+func (h *HTMLHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr{}, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		h2.attrs = flattenAttrs(h2.attrs, prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new handler that qualifies attribute names with name.
+func (h *HTMLHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// Close writes the end of the document. The header is written too if no
+// record was, so the result is always well-formed. Records handled after
+// Close are dropped. The writer is not closed.
+func (h *HTMLHandler) Close() error {
+	o := h.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+
+	var sb strings.Builder
+	if !o.started {
+		writeHTMLHeader(&sb, &h.opts)
+	}
+	sb.WriteString("</div>\n")
+	if !h.opts.Fragment {
+		sb.WriteString("</body>\n</html>\n")
+	}
+	_, err := io.WriteString(o.w, sb.String())
+	return err
+}
+
+// write writes row, preceded by the document header if it is the first.
+func (o *htmlOutput) write(opts *HTMLOptions, row string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	if !o.started {
+		var sb strings.Builder
+		writeHTMLHeader(&sb, opts)
+		row = sb.String() + row
+		o.started = true
+	}
+	_, err := io.WriteString(o.w, row)
+	return err
+}
+
+// writeHTMLHeader writes the document head and opens the log container.
+func writeHTMLHeader(sb *strings.Builder, opts *HTMLOptions) {
+	if !opts.Fragment {
+		title := html.EscapeString(opts.Title)
+		sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>")
+		sb.WriteString(title)
+		sb.WriteString("</title>\n<style>\n")
+		sb.WriteString(HTMLStyle)
+		sb.WriteString("</style>\n</head>\n<body>\n<h1 class=\"humanlog-title\">")
+		sb.WriteString(title)
+		sb.WriteString("</h1>\n")
+	}
+	sb.WriteString("<div class=\"humanlog\">\n")
+}
+
+// formatHTMLRecord renders one record as a row:
+//
+//	<div class="rec lvl-info"><span class="time">…</span> <span class="level">INFO</span> <span class="msg">…</span> <details>…</details></div>
+func formatHTMLRecord(opts *HTMLOptions, r slog.Record, attrs []slog.Attr) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<div class="rec `)
+	sb.WriteString(htmlLevelClass(r.Level))
+	sb.WriteString(`">`)
+	if !r.Time.IsZero() {
+		sb.WriteString(`<span class="time">`)
+		sb.WriteString(html.EscapeString(r.Time.Format(opts.TimeFormat)))
+		sb.WriteString(`</span> `)
+	}
+	sb.WriteString(`<span class="level">`)
+	sb.WriteString(html.EscapeString(r.Level.String()))
+	sb.WriteString(`</span> <span class="msg">`)
+	sb.WriteString(html.EscapeString(r.Message))
+	sb.WriteString(`</span>`)
+
+	if len(attrs) > 0 {
+		sb.WriteString(` <details><summary>`)
+		sb.WriteString(strconv.Itoa(len(attrs)))
+		if len(attrs) == 1 {
+			sb.WriteString(" attr")
+		} else {
+			sb.WriteString(" attrs")
+		}
+		sb.WriteString(`</summary><table>`)
+		for _, a := range attrs {
+			sb.WriteString(`<tr><th>`)
+			sb.WriteString(html.EscapeString(a.Key))
+			sb.WriteString(`</th><td>`)
+			sb.WriteString(html.EscapeString(flatValue(a.Value)))
+			sb.WriteString(`</td></tr>`)
+		}
+		sb.WriteString(`</table></details>`)
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// htmlLevelClass returns the CSS class coloring rows of the given level.
+func htmlLevelClass(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "lvl-error"
+	case level >= slog.LevelWarn:
+		return "lvl-warn"
+	case level >= slog.LevelInfo:
+		return "lvl-info"
+	default:
+		return "lvl-debug"
+	}
+}
@@ -0,0 +1,77 @@
+package humanlog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHTMLHandler_Document(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHTMLHandler(buf, HTMLOptions{Title: "CI <run>"})
+
+	logger := slog.New(h).With("job", "build").WithGroup("req")
+	logger.Warn("Slow <query>", slog.String("sql", `a & "b"`), slog.Any("err", errors.New("timeout")))
+	logger.Debug("Hidden")
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>CI &lt;run&gt;</title>",
+		`<div class="rec lvl-warn">`,
+		`<span class="level">WARN</span> <span class="msg">Slow &lt;query&gt;</span>`,
+		"<summary>3 attrs</summary>",
+		"<tr><th>job</th><td>build</td></tr>",
+		"<tr><th>req.sql</th><td>a &amp; &#34;b&#34;</td></tr>",
+		"<tr><th>req.err</th><td>timeout</td></tr>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Hidden") {
+		t.Errorf("output contains a record below the level:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "</div>\n</body>\n</html>\n") {
+		t.Errorf("output should end the document, got:\n%s", got)
+	}
+}
+
+func TestHTMLHandler_Fragment(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHTMLHandler(buf, HTMLOptions{Fragment: true, Level: slog.LevelDebug})
+
+	slog.New(h).Debug("Started")
+	_ = h.Close()
+	_ = h.Close()
+	slog.New(h).Info("After close")
+
+	want := `<div class="humanlog">` + "\n" +
+		`<div class="rec lvl-debug"><span class="time">`
+	got := buf.String()
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, should start with %q", got, want)
+	}
+	if strings.Contains(got, "<html>") || strings.Contains(got, "After close") {
+		t.Errorf("fragment output = %q, want only rows", got)
+	}
+	if strings.Count(got, "</div>\n") != 2 {
+		t.Errorf("output = %q, want one row and one closing container", got)
+	}
+}
+
+func TestHTMLHandler_CloseWithoutRecords(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHTMLHandler(buf, HTMLOptions{})
+	_ = h.Close()
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "<!DOCTYPE html>") || !strings.HasSuffix(got, "</html>\n") {
+		t.Errorf("output = %q, want an empty but complete document", got)
+	}
+}
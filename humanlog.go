@@ -1,12 +1,58 @@
 package humanlog
 
 import (
+	"errors"
 	"io"
 	"log/slog"
+	"reflect"
 )
 
+// NewHandlerE is like NewHandler but validates w and opts first, returning
+// an error describing every problem found, such as a nil writer, negative
+// widths, unknown enum values or a TimeFormat that is not a time layout.
+func NewHandlerE(w io.Writer, opts *Options) (*Handler, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	var errs []error
+	if w == nil {
+		errs = append(errs, errors.New("humanlog: nil writer"))
+	} else if opts.Writer != nil && !sameWriter(opts.Writer, w) {
+		errs = append(errs, errors.New("humanlog: Options.Writer conflicts with the writer passed to NewHandlerE"))
+	}
+	if w != nil && opts.FallbackWriter != nil && sameWriter(opts.FallbackWriter, w) {
+		errs = append(errs, errors.New("humanlog: FallbackWriter is the writer it falls back from"))
+	}
+	if w != nil && opts.LockFile && !lockable(w) {
+		errs = append(errs, errors.New("humanlog: LockFile needs a file or RotatingWriter to lock"))
+	}
+	if err := errors.Join(append(errs, opts.validate())...); err != nil {
+		return nil, err
+	}
+	return NewHandler(w, opts), nil
+}
+
+// lockable reports whether LockFile can lock w.
+func lockable(w io.Writer) bool {
+	if _, ok := w.(*RotatingWriter); ok {
+		return true
+	}
+	_, ok := w.(interface{ Fd() uintptr })
+	return ok
+}
+
+// sameWriter reports whether a and b are the same writer, without
+// panicking on writers of uncomparable types.
+func sameWriter(a, b io.Writer) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
 // NewHandler creates a new human-readable slog.Handler with the given options.
-// If opts is nil, default options will be used.
+// If opts is nil, default options will be used. It panics if w is nil and
+// accepts inconsistent options; use NewHandlerE to have them reported.
 func NewHandler(w io.Writer, opts *Options) *Handler {
 	if opts == nil {
 		opts = DefaultOptions()
@@ -20,26 +66,53 @@ func NewHandler(w io.Writer, opts *Options) *Handler {
 	// Set the writer in the options
 	options := *opts
 	options.Writer = w
+	if options.Deterministic {
+		options.DisableColor = true
+		options.UseUTC = true
+	}
+
+	if options.Metrics == nil {
+		options.Metrics = NewMetrics()
+	}
+	out := newOutput(w, &options)
+
+	start := options.now()
+	if options.Deterministic {
+		start = DeterministicTime
+	}
 
-	// Create the underlying handler based on UseJSON option
-	var underlyingHandler slog.Handler
+	// JSON output is delegated to slog's JSON handler; human-readable
+	// output is rendered directly and needs no underlying handler
+	var jsonHandler slog.Handler
 	if opts.UseJSON {
-		underlyingHandler = slog.NewJSONHandler(w, &slog.HandlerOptions{
-			AddSource: opts.AddSource,
-			Level:     opts.Level,
-		})
-	} else {
-		underlyingHandler = slog.NewTextHandler(w, &slog.HandlerOptions{
+		jsonHandler = slog.NewJSONHandler(out, &slog.HandlerOptions{
 			AddSource:   opts.AddSource,
 			Level:       opts.Level,
-			ReplaceAttr: nil, // We do our own attribute handling
+			ReplaceAttr: jsonReplaceAttr(&options),
 		})
+		static := append(jsonStaticAttrs(&options), processInfoAttrs(&options)...)
+		static = append(static, buildInfoAttrs(&options)...)
+		if len(static) > 0 {
+			jsonHandler = jsonHandler.WithAttrs(static)
+		}
+	}
+
+	var attrs []slog.Attr
+	if !opts.UseJSON {
+		attrs = append(processInfoAttrs(&options), buildInfoAttrs(&options)...)
 	}
 
-	return &Handler{
-		h:      underlyingHandler,
-		opts:   options,
-		attrs:  nil,
-		groups: nil,
+	h := &Handler{
+		h:       jsonHandler,
+		opts:    options,
+		out:     out,
+		metrics: options.Metrics,
+		start:   start,
+		name:    options.Prefix,
+		attrs:   attrs,
+		groups:  nil,
+		times:   newTimeCache(options.TimeFormat),
 	}
+	h.preformatted = h.preformat(attrs)
+	return h
 }
@@ -0,0 +1,91 @@
+package humanlog
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewHandlerE(t *testing.T) {
+	var buf bytes.Buffer
+	tests := []struct {
+		name string
+		w    io.Writer
+		opts *Options
+		want []string // substrings of the error, none for a valid setup
+	}{
+		{name: "defaults", w: &buf},
+		{name: "valid", w: &buf, opts: &Options{Writer: &buf, TimeFormat: TimeFormatMillis, MessageWidth: 20, GroupStyle: GroupNested}},
+		{name: "relative time", w: &buf, opts: &Options{TimeFormat: TimeFormatRelative}},
+		{name: "nil writer", opts: &Options{}, want: []string{"nil writer"}},
+		{
+			name: "conflicting writers",
+			w:    &buf,
+			opts: &Options{Writer: io.Discard, FallbackWriter: &buf},
+			want: []string{"Options.Writer conflicts", "FallbackWriter is the writer"},
+		},
+		{
+			name: "lock without a file",
+			w:    &buf,
+			opts: &Options{LockFile: true},
+			want: []string{"LockFile needs a file or RotatingWriter"},
+		},
+		{
+			name: "negative widths",
+			w:    &buf,
+			opts: &Options{MessageWidth: -1, MaxLineWidth: -80},
+			want: []string{"MessageWidth is negative: -1", "MaxLineWidth is negative: -80"},
+		},
+		{
+			name: "unknown enums",
+			w:    &buf,
+			opts: &Options{GroupStyle: 7, Sync: -1},
+			want: []string{"unknown GroupStyle value 7", "unknown Sync value -1"},
+		},
+		{
+			name: "misspelled time format",
+			w:    &buf,
+			opts: &Options{TimeFormat: "millis"},
+			want: []string{`TimeFormat "millis" is not a time layout`},
+		},
+		{
+			name: "layouts for JSON",
+			w:    &buf,
+			opts: &Options{UseJSON: true, Columns: DefaultColumns()},
+			want: []string{"do not apply to JSON output"},
+		},
+		{
+			name: "incomplete highlights",
+			w:    &buf,
+			opts: &Options{
+				Highlights:        []HighlightRule{{Key: "latency", Op: CompareGreater}},
+				HighlightPatterns: []HighlightPattern{{Regexp: regexp.MustCompile("x")}, {}},
+			},
+			want: []string{`Highlights[0] for key "latency" has no threshold`, "HighlightPatterns[1] has no Regexp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHandlerE(tt.w, tt.opts)
+			if len(tt.want) == 0 {
+				if err != nil || h == nil {
+					t.Fatalf("NewHandlerE() = %v, %v, want a handler", h, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("NewHandlerE() succeeded, want error containing %q", tt.want)
+			}
+			if h != nil {
+				t.Errorf("NewHandlerE() returned a handler along with error %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package humanlogtest provides utilities for testing code that logs
+// through log/slog, such as a handler that captures records for
+// assertions.
+package humanlogtest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a captured record. Attributes include those added with
+// Logger.With, and attributes inside groups are flattened into dotted keys,
+// e.g. "request.id".
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// AttrValue returns the value of the attribute named key.
+func (e Entry) AttrValue(key string) (slog.Value, bool) {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// CaptureHandler is a slog.Handler that keeps records in memory so tests
+// can check what was logged without parsing formatted output. It is safe
+// for concurrent use.
+//
+// Example:
+//
+//	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug)
+//	svc := NewService(slog.New(capture))
+//	svc.Run()
+//	if !capture.HasRecord(slog.LevelError, "connection lost") {
+//		t.Error("expected the connection loss to be logged")
+//	}
+type CaptureHandler struct {
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string
+	store  *captureStore
+}
+
+// captureStore holds the entries of a CaptureHandler and every handler
+// derived from it.
+type captureStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewCaptureHandler creates a CaptureHandler capturing records at or above
+// level. If level is nil, every record is captured.
+func NewCaptureHandler(level slog.Leveler) *CaptureHandler {
+	if level == nil {
+		level = slog.Level(-1 << 31)
+	}
+	return &CaptureHandler{level: level, store: &captureStore{}}
+}
+
+// Enabled reports whether records at the given level are captured.
+func (c *CaptureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= c.level.Level()
+}
+
+// Handle captures the record.
+func (c *CaptureHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := append(make([]slog.Attr, 0, len(c.attrs)+r.NumAttrs()), c.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = flatten(attrs, c.prefix, a)
+		return true
+	})
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.entries = append(c.store.entries, Entry{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: attrs})
+	return nil
+}
+
+// WithAttrs returns a new CaptureHandler sharing the captured entries,
+// whose records include the given attributes.
+func (c *CaptureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c2 := *c
+	c2.attrs = append([]slog.Attr{}, c.attrs...)
+	for _, a := range attrs {
+		c2.attrs = flatten(c2.attrs, c.prefix, a)
+	}
+	return &c2
+}
+
+// WithGroup returns a new CaptureHandler sharing the captured entries,
+// which qualifies attribute keys with name.
+func (c *CaptureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return c
+	}
+	c2 := *c
+	c2.prefix = c.prefix + name + "."
+	return &c2
+}
+
+// Entries returns a copy of the captured entries in the order they were
+// logged.
+func (c *CaptureHandler) Entries() []Entry {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	return append([]Entry(nil), c.store.entries...)
+}
+
+// HasRecord reports whether a record with the given level and message was
+// captured.
+func (c *CaptureHandler) HasRecord(level slog.Level, msg string) bool {
+	for _, e := range c.Entries() {
+		if e.Level == level && e.Message == msg {
+			return true
+		}
+	}
+	return false
+}
+
+// AttrValue returns the value of the attribute named key in the most
+// recent entry that has it.
+func (c *CaptureHandler) AttrValue(key string) (slog.Value, bool) {
+	entries := c.Entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if v, ok := entries[i].AttrValue(key); ok {
+			return v, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// Reset discards the captured entries.
+func (c *CaptureHandler) Reset() {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.entries = nil
+}
+
+// flatten appends a to dst, resolving its value and expanding groups into
+// keys qualified by prefix.
+func flatten(dst []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	v := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if v.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			dst = flatten(dst, groupPrefix, ga)
+		}
+		return dst
+	}
+	return append(dst, slog.Attr{Key: strings.TrimSuffix(prefix+a.Key, "."), Value: v})
+}
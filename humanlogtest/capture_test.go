@@ -0,0 +1,40 @@
+package humanlogtest
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	capture := NewCaptureHandler(slog.LevelInfo)
+	logger := slog.New(capture).With("service", "billing")
+
+	logger.Debug("Not captured")
+	logger.Info("Invoice sent", "invoice", 42)
+	logger.WithGroup("req").Error("Payment failed", slog.Group("card", "last4", "4242"))
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("captured %d entries, want 2", len(entries))
+	}
+	if !capture.HasRecord(slog.LevelError, "Payment failed") {
+		t.Error("HasRecord() = false for the error")
+	}
+	if capture.HasRecord(slog.LevelDebug, "Not captured") {
+		t.Error("HasRecord() = true for a record below the level")
+	}
+	if v, ok := capture.AttrValue("invoice"); !ok || v.Int64() != 42 {
+		t.Errorf("AttrValue(invoice) = %v, %v", v, ok)
+	}
+	if v, ok := entries[1].AttrValue("req.card.last4"); !ok || v.String() != "4242" {
+		t.Errorf("AttrValue(req.card.last4) = %v, %v", v, ok)
+	}
+	if v, ok := entries[1].AttrValue("service"); !ok || v.String() != "billing" {
+		t.Errorf("AttrValue(service) = %v, %v", v, ok)
+	}
+
+	capture.Reset()
+	if n := len(capture.Entries()); n != 0 {
+		t.Errorf("captured %d entries after Reset, want 0", n)
+	}
+}
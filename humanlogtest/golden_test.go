@@ -0,0 +1,34 @@
+package humanlogtest
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/lepinkainen/humanlog"
+)
+
+func TestDiff(t *testing.T) {
+	if got := Diff("a\nb\n", "a\nb\n"); got != "" {
+		t.Errorf("Diff() of equal input = %q, want empty", got)
+	}
+	want := "  a\n- b\n+ B\n  c\n"
+	if got := Diff("a\nb\nc", "a\nB\nc"); got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
+
+func TestAssertGolden_DeterministicOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(humanlog.NewHandler(&buf, &humanlog.Options{
+		Deterministic: true,
+		AddSource:     true,
+		TimeFormat:    humanlog.TimeFormatSeconds,
+		MessageWidth:  20,
+	}))
+	logger.Info("Server started", "port", 8080, "addr", "localhost")
+	logger.With("user", "alice").Warn("Quota low", "remaining", 3)
+
+	AssertGolden(t, filepath.Join("testdata", "deterministic.golden"), buf.Bytes())
+}
@@ -0,0 +1,184 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// defaultJournalSocket is the systemd-journald native protocol socket.
+const defaultJournalSocket = "/run/systemd/journal/socket"
+
+// JournaldOptions configures a JournaldHandler.
+type JournaldOptions struct {
+	// SocketPath is the journald socket.
+	// Default: "/run/systemd/journal/socket"
+	SocketPath string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER. Default: the program name.
+	Identifier string
+
+	// Level is the minimum level sent. Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// AddSource adds CODE_FILE, CODE_LINE and CODE_FUNC fields.
+	AddSource bool
+}
+
+// JournaldHandler is a slog.Handler that sends records to systemd-journald
+// using its native protocol. The level is mapped to PRIORITY, and attribute
+// keys are uppercased into journal field names, e.g. "request.id" becomes
+// REQUEST_ID.
+type JournaldHandler struct {
+	conn   *net.UnixConn
+	opts   JournaldOptions
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewJournaldHandler creates a JournaldHandler. It fails if the journald
+// socket cannot be opened, e.g. on systems without systemd.
+func NewJournaldHandler(opts JournaldOptions) (*JournaldHandler, error) {
+	if opts.SocketPath == "" {
+		opts.SocketPath = defaultJournalSocket
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: opts.SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("humanlog: connect to journald: %w", err)
+	}
+	return &JournaldHandler{conn: conn, opts: opts}, nil
+}
+
+// Enabled reports whether records at the given level are sent.
+func (h *JournaldHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle sends the record as a journal entry.
+func (h *JournaldHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	appendJournalField(&buf, "MESSAGE", r.Message)
+	appendJournalField(&buf, "PRIORITY", strconv.Itoa(syslogSeverity(r.Level)))
+	appendJournalField(&buf, "SYSLOG_IDENTIFIER", h.opts.Identifier)
+
+	if h.opts.AddSource && r.PC != 0 {
+		fs := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := fs.Next()
+		if f.File != "" {
+			appendJournalField(&buf, "CODE_FILE", f.File)
+			appendJournalField(&buf, "CODE_LINE", strconv.Itoa(f.Line))
+			appendJournalField(&buf, "CODE_FUNC", f.Function)
+		}
+	}
+
+	for _, a := range h.attrs {
+		appendJournalField(&buf, journalFieldName(a.Key), flatValue(a.Value))
+	}
+	prefix := strings.Join(h.groups, ".")
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = flattenAttrs(attrs, prefix, a)
+		return true
+	})
+	for _, a := range attrs {
+		appendJournalField(&buf, journalFieldName(a.Key), flatValue(a.Value))
+	}
+
+	return h.send(buf.Bytes())
+}
+
+// WithAttrs returns a new handler whose entries include the given attributes.
+func (h *JournaldHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr{}, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		h2.attrs = flattenAttrs(h2.attrs, prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new handler that qualifies field names with name.
+func (h *JournaldHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// Close closes the journald socket.
+func (h *JournaldHandler) Close() error {
+	return h.conn.Close()
+}
+
+// send writes one entry, falling back to passing a file descriptor when
+// the entry is too large for a single datagram.
+func (h *JournaldHandler) send(entry []byte) error {
+	_, err := h.conn.Write(entry)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFD(h.conn, entry)
+	}
+	return err
+}
+
+// appendJournalField encodes a field in the native protocol. Values
+// containing newlines use the binary, length-prefixed encoding.
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts an attribute key into a valid journal field
+// name: uppercase letters, digits and underscores, not starting with an
+// underscore or digit.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "X" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
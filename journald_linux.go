@@ -0,0 +1,32 @@
+package humanlog
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// sendJournalFD passes an oversized entry to journald through a file
+// descriptor, as described by the native protocol.
+func sendJournalFD(conn *net.UnixConn, entry []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "humanlog-journal-")
+	if err != nil {
+		f, err = os.CreateTemp("", "humanlog-journal-")
+		if err != nil {
+			return err
+		}
+	}
+	defer func() { _ = f.Close() }()
+
+	// The file only needs to live until journald has read it
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(f.Fd()))
+	_, _, err = conn.WriteMsgUnix(nil, rights, nil)
+	return err
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package humanlog
+
+import (
+	"errors"
+	"net"
+)
+
+// sendJournalFD is only supported on Linux, where journald runs.
+func sendJournalFD(_ *net.UnixConn, _ []byte) error {
+	return errors.New("humanlog: journal entry too large for a datagram")
+}
@@ -0,0 +1,77 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"request.id": "REQUEST_ID",
+		"userID":     "USERID",
+		"_private":   "PRIVATE",
+		"2fa":        "X2FA",
+		"":           "X",
+	}
+	for key, want := range tests {
+		if got := journalFieldName(key); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestAppendJournalField_Multiline(t *testing.T) {
+	var buf bytes.Buffer
+	appendJournalField(&buf, "MESSAGE", "a\nb")
+
+	want := new(bytes.Buffer)
+	want.WriteString("MESSAGE\n")
+	_ = binary.Write(want, binary.LittleEndian, uint64(3))
+	want.WriteString("a\nb\n")
+
+	if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+		t.Errorf("encoded field = %q, want %q", buf.Bytes(), want.Bytes())
+	}
+}
+
+func TestJournaldHandler_SendsEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("cannot listen on unixgram socket: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	h, err := NewJournaldHandler(JournaldOptions{SocketPath: path, Identifier: "app", AddSource: true})
+	if err != nil {
+		t.Fatalf("NewJournaldHandler() error = %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	slog.New(h).With("component", "db").Warn("Slow query", slog.Int("rows", 3))
+
+	packet := make([]byte, 4096)
+	n, err := conn.Read(packet)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	got := string(packet[:n])
+	for _, want := range []string{
+		"MESSAGE=Slow query\n",
+		"PRIORITY=4\n",
+		"SYSLOG_IDENTIFIER=app\n",
+		"COMPONENT=db\n",
+		"ROWS=3\n",
+		"CODE_FILE=",
+		"CODE_LINE=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("entry = %q, should contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package humanlog
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strconv"
+)
+
+// JSONFormat selects the field layout used when UseJSON is enabled.
+type JSONFormat int
+
+const (
+	// JSONStandard uses slog.JSONHandler's field names. This is the default.
+	JSONStandard JSONFormat = iota
+	// JSONGoogleCloud uses the structured logging fields understood by
+	// Google Cloud Logging, so Cloud Run and GKE logs render correctly in
+	// the Logs Explorer.
+	JSONGoogleCloud
+	// JSONDatadog uses Datadog's reserved attributes, including trace and
+	// span IDs and unified service tags, so logs correlate with APM traces.
+	JSONDatadog
+)
+
+// Google Cloud Logging special field names.
+const (
+	gcpSeverityKey       = "severity"
+	gcpMessageKey        = "message"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+)
+
+// Datadog reserved attribute names.
+const (
+	ddStatusKey  = "status"
+	ddMessageKey = "message"
+	ddTraceIDKey = "dd.trace_id"
+	ddSpanIDKey  = "dd.span_id"
+)
+
+// replaceAttrFunc is the signature of slog.HandlerOptions.ReplaceAttr.
+type replaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
+
+// jsonReplaceAttr returns the ReplaceAttr function used by the JSON handler
+// to apply humanlog options to slog's built-in attributes, or nil if the
+// defaults need no adjustment.
+func jsonReplaceAttr(opts *Options) replaceAttrFunc {
+	var chain []replaceAttrFunc
+
+	if opts.Deterministic {
+		chain = append(chain, deterministicReplaceAttr)
+	}
+
+	// Profile mappings run first so their field names take precedence
+	if opts.JSONFormat == JSONGoogleCloud {
+		chain = append(chain, googleCloudReplaceAttr(opts.GCPProjectID))
+	}
+	if opts.JSONFormat == JSONDatadog {
+		chain = append(chain, datadogReplaceAttr)
+	}
+	// Trace records may be enabled per context or by WithOptions even
+	// when Level is higher
+	if opts.LevelStyler != nil {
+		chain = append(chain, replaceLevelAttr(opts.LevelStyler))
+	} else {
+		chain = append(chain, traceLevelAttr)
+	}
+	if loc := opts.location(); loc != nil {
+		chain = append(chain, func(_ []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindTime {
+				a.Value = slog.TimeValue(a.Value.Time().In(loc))
+			}
+			return a
+		})
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, replace := range chain {
+			a = replace(groups, a)
+		}
+		return a
+	}
+}
+
+// traceLevelAttr names levels below Debug relative to LevelTrace, e.g.
+// "TRACE" where slog would write "DEBUG-4".
+func traceLevelAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok && level < slog.LevelDebug {
+			a.Value = slog.StringValue(levelString(level))
+		}
+	}
+	return a
+}
+
+// deterministicReplaceAttr reduces source locations to the file name,
+// matching the human-readable deterministic mode.
+func deterministicReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.SourceKey {
+		return a
+	}
+	if src, ok := a.Value.Any().(*slog.Source); ok {
+		a.Value = slog.AnyValue(&slog.Source{File: filepath.Base(src.File)})
+	}
+	return a
+}
+
+// googleCloudReplaceAttr maps slog's built-in keys and correlation IDs to
+// Google Cloud Logging's special fields.
+func googleCloudReplaceAttr(projectID string) replaceAttrFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+
+		switch a.Key {
+		case slog.LevelKey:
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String(gcpSeverityKey, googleCloudSeverity(level))
+			}
+		case slog.MessageKey:
+			return slog.Attr{Key: gcpMessageKey, Value: a.Value}
+		case slog.SourceKey:
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				return slog.Group(gcpSourceLocationKey,
+					slog.String("file", src.File),
+					slog.String("line", strconv.Itoa(src.Line)),
+					slog.String("function", src.Function),
+				)
+			}
+		case string(TraceIDKey):
+			trace := a.Value.String()
+			if projectID != "" {
+				trace = "projects/" + projectID + "/traces/" + trace
+			}
+			return slog.String(gcpTraceKey, trace)
+		case string(SpanIDKey):
+			return slog.Attr{Key: gcpSpanIDKey, Value: a.Value}
+		case TraceSampledKey:
+			return slog.Attr{Key: gcpTraceSampledKey, Value: a.Value}
+		}
+		return a
+	}
+}
+
+// googleCloudSeverity maps a slog level to a Google Cloud Logging severity.
+func googleCloudSeverity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError+8:
+		return "EMERGENCY"
+	case level >= slog.LevelError+4:
+		return "CRITICAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo+2:
+		return "NOTICE"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// jsonStaticAttrs returns attributes the JSON format adds to every record.
+func jsonStaticAttrs(opts *Options) []slog.Attr {
+	if opts.JSONFormat != JSONDatadog {
+		return nil
+	}
+
+	// Unified service tagging
+	var attrs []slog.Attr
+	if opts.Service != "" {
+		attrs = append(attrs, slog.String("service", opts.Service), slog.String("dd.service", opts.Service))
+	}
+	if opts.Environment != "" {
+		attrs = append(attrs, slog.String("dd.env", opts.Environment))
+	}
+	if opts.Version != "" {
+		attrs = append(attrs, slog.String("dd.version", opts.Version))
+	}
+	return attrs
+}
+
+// datadogReplaceAttr maps slog's built-in keys and correlation IDs to
+// Datadog's reserved attributes.
+func datadogReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+
+	switch a.Key {
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(ddStatusKey, datadogStatus(level))
+		}
+	case slog.MessageKey:
+		return slog.Attr{Key: ddMessageKey, Value: a.Value}
+	case string(TraceIDKey):
+		return slog.Attr{Key: ddTraceIDKey, Value: datadogID(a.Value)}
+	case string(SpanIDKey):
+		return slog.Attr{Key: ddSpanIDKey, Value: datadogID(a.Value)}
+	}
+	return a
+}
+
+// datadogID converts a hex-encoded W3C trace or span ID to the decimal
+// form Datadog correlates on: the low 64 bits of a 32-digit trace ID, or
+// all of a 16-digit span ID. Other values are returned unchanged.
+func datadogID(v slog.Value) slog.Value {
+	if v.Kind() != slog.KindString {
+		return v
+	}
+	s := v.String()
+	if len(s) != 16 && len(s) != 32 {
+		return v
+	}
+	id, err := strconv.ParseUint(s[len(s)-16:], 16, 64)
+	if err != nil {
+		return v
+	}
+	return slog.StringValue(strconv.FormatUint(id, 10))
+}
+
+// datadogStatus maps a slog level to a Datadog log status.
+func datadogStatus(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError+8:
+		return "emergency"
+	case level >= slog.LevelError+4:
+		return "critical"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo+2:
+		return "notice"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
@@ -0,0 +1,127 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func decodeJSONLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestJSON_GoogleCloudFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(NewHandler(buf, &Options{
+		Level:        slog.LevelInfo,
+		UseJSON:      true,
+		AddSource:    true,
+		JSONFormat:   JSONGoogleCloud,
+		GCPProjectID: "my-project",
+	}))
+
+	ctx := WithTraceID(context.Background(), "abc123")
+	NewContextLogger(logger).Warn(ctx, "Disk almost full", slog.String("span_id", "def456"))
+
+	m := decodeJSONLine(t, buf)
+
+	if m["severity"] != "WARNING" {
+		t.Errorf("severity = %v, want WARNING", m["severity"])
+	}
+	if m["message"] != "Disk almost full" {
+		t.Errorf("message = %v, want Disk almost full", m["message"])
+	}
+	if m["logging.googleapis.com/trace"] != "projects/my-project/traces/abc123" {
+		t.Errorf("trace = %v, want projects/my-project/traces/abc123", m["logging.googleapis.com/trace"])
+	}
+	if m["logging.googleapis.com/spanId"] != "def456" {
+		t.Errorf("spanId = %v, want def456", m["logging.googleapis.com/spanId"])
+	}
+	src, ok := m["logging.googleapis.com/sourceLocation"].(map[string]any)
+	if !ok || src["file"] == "" || src["line"] == "" {
+		t.Errorf("sourceLocation = %v, want file and line", m["logging.googleapis.com/sourceLocation"])
+	}
+	for _, key := range []string{"level", "msg", "source"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("output should not contain standard key %q: %v", key, m)
+		}
+	}
+}
+
+func TestJSON_GoogleCloudSeverity(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelInfo + 2, "NOTICE"},
+		{slog.LevelWarn, "WARNING"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 4, "CRITICAL"},
+		{slog.LevelError + 8, "EMERGENCY"},
+	}
+
+	for _, tt := range tests {
+		if got := googleCloudSeverity(tt.level); got != tt.want {
+			t.Errorf("googleCloudSeverity(%v) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestJSON_DatadogFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(NewHandler(buf, &Options{
+		Level:       slog.LevelInfo,
+		UseJSON:     true,
+		JSONFormat:  JSONDatadog,
+		Service:     "checkout",
+		Environment: "prod",
+		Version:     "1.2.3",
+	}))
+
+	ctx := WithTraceID(context.Background(), "1234567890")
+	NewContextLogger(logger).Error(ctx, "Payment failed", slog.String("span_id", "987"))
+
+	m := decodeJSONLine(t, buf)
+
+	want := map[string]string{
+		"status":      "error",
+		"message":     "Payment failed",
+		"dd.trace_id": "1234567890",
+		"dd.span_id":  "987",
+		"service":     "checkout",
+		"dd.service":  "checkout",
+		"dd.env":      "prod",
+		"dd.version":  "1.2.3",
+	}
+	for key, value := range want {
+		if m[key] != value {
+			t.Errorf("%s = %v, want %s", key, m[key], value)
+		}
+	}
+	if _, ok := m["level"]; ok {
+		t.Errorf("output should not contain level key: %v", m)
+	}
+}
+
+func TestJSON_Deterministic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{UseJSON: true, Deterministic: true, AddSource: true}))
+	logger.Info("Fixed")
+
+	m := decodeJSONLine(t, &buf)
+	if m["time"] != "2000-01-01T00:00:00Z" {
+		t.Errorf("time = %v, want the deterministic time", m["time"])
+	}
+	if src, _ := m["source"].(map[string]any); src["file"] != "json_test.go" {
+		t.Errorf("source = %v, want the file name only", m["source"])
+	}
+}
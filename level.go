@@ -0,0 +1,87 @@
+package humanlog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// levelWidth is the fixed width of the level column.
+const levelWidth = 5
+
+// LevelTrace is the level for ultra-verbose tracing below Debug. The
+// default LevelStyler names it TRACE and renders it dimmed gray, and
+// LevelFlag parses "trace".
+const LevelTrace = slog.LevelDebug - 4
+
+// LevelStyler resolves how a level is displayed. Set Options.LevelStyler to
+// implement dynamic styling, such as custom level names or colors that
+// depend on the level value, without modifying the handler.
+type LevelStyler interface {
+	// LevelName returns the name printed for level, e.g. "INFO".
+	// It is used for both human-readable and JSON output.
+	LevelName(level slog.Level) string
+
+	// LevelColor returns the ANSI escape sequence used to style level,
+	// or "" to leave it unstyled. Sequences can combine attributes and
+	// backgrounds, e.g. StyleBold + StyleWhite + StyleBgRed.
+	LevelColor(level slog.Level) string
+}
+
+// DefaultLevelStyler is the LevelStyler used when Options.LevelStyler is nil.
+var DefaultLevelStyler LevelStyler = defaultLevelStyler{}
+
+type defaultLevelStyler struct{}
+
+// LevelName returns ERROR, WARN, INFO, DEBUG or, below Debug, TRACE.
+func (defaultLevelStyler) LevelName(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARN"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	case level >= slog.LevelDebug:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
+
+// LevelColor returns red, yellow, blue or gray depending on severity, and
+// dimmed gray below Debug.
+func (defaultLevelStyler) LevelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorBlue
+	case level >= slog.LevelDebug:
+		return colorGray
+	default:
+		return StyleDim + colorGray
+	}
+}
+
+// levelStyler returns the configured LevelStyler or the default one.
+func (o *Options) levelStyler() LevelStyler {
+	if o.LevelStyler != nil {
+		return o.LevelStyler
+	}
+	return DefaultLevelStyler
+}
+
+// replaceLevelAttr returns a slog ReplaceAttr function that renders the
+// top-level level attribute through styler.
+func replaceLevelAttr(styler LevelStyler) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.LevelKey {
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				a.Value = slog.StringValue(strings.TrimSpace(styler.LevelName(level)))
+			}
+		}
+		return a
+	}
+}
@@ -0,0 +1,160 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncatedKey is the JSON member added to records shortened by
+// Options.MaxRecordBytes.
+const TruncatedKey = "_truncated"
+
+// limitLine shortens a formatted line, including its newline, to at most
+// max bytes, ending it with a marker that tells how many bytes were cut
+// from a record with attrs attributes.
+func limitLine(line string, max, attrs int) string {
+	if len(line) <= max {
+		return line
+	}
+	format := " ...[truncated %d bytes, %d attrs]\n"
+	// The whole line length bounds the digits of the count
+	cut := max - len(fmt.Sprintf(format, len(line), attrs)) - len(colorReset)
+	if cut <= 0 {
+		return fmt.Sprintf(format[1:], len(line), attrs)
+	}
+
+	// Do not split a character or an escape sequence
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	kept := line[:cut]
+	if esc := strings.LastIndexByte(kept, '\x1b'); esc >= 0 {
+		if loc := ansiSequence.FindStringIndex(line[esc:]); loc == nil || esc+loc[1] > cut {
+			kept = kept[:esc]
+		}
+	}
+	marker := fmt.Sprintf(format, len(line)-len(kept), attrs)
+	if strings.IndexByte(kept, '\x1b') >= 0 {
+		kept += colorReset
+	}
+	return kept + marker
+}
+
+// jsonRecordKeys are the members of a JSON record that limitJSON always
+// keeps: slog's built-in keys and their Google Cloud and Datadog names.
+var jsonRecordKeys = map[string]bool{
+	slog.TimeKey:         true,
+	slog.LevelKey:        true,
+	slog.MessageKey:      true,
+	slog.SourceKey:       true,
+	gcpSeverityKey:       true,
+	gcpMessageKey:        true,
+	gcpSourceLocationKey: true,
+	ddStatusKey:          true,
+}
+
+// jsonMessageKeys are the record members holding the message, which
+// limitJSON shortens if the record does not fit otherwise.
+var jsonMessageKeys = map[string]bool{slog.MessageKey: true, gcpMessageKey: true}
+
+// limitJSON shortens a JSON object record, including its newline, to
+// about max bytes while keeping it valid. The time, level, source and
+// message are always kept, the message shortened with a "..." marker if
+// it alone is too long; attributes are kept in order while they fit, and
+// the rest are dropped and counted under TruncatedKey. Records that are
+// not JSON objects are returned unchanged.
+func limitJSON(p []byte, max int) []byte {
+	if len(p) <= max {
+		return p
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(p))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return p
+	}
+	type member struct {
+		key    []byte
+		value  json.RawMessage
+		record bool
+		msg    bool
+	}
+	var members []member
+	required := 0 // bytes of the record members, with their separators
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return p
+		}
+		name := tok.(string)
+		key, _ := json.Marshal(name)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return p
+		}
+		m := member{key: key, value: value, record: jsonRecordKeys[name], msg: jsonMessageKeys[name]}
+		if m.record {
+			required += len(m.key) + len(m.value) + 2
+		}
+		members = append(members, m)
+	}
+
+	const reserve = 80 // room for the TruncatedKey member
+	if over := 1 + required + reserve - max; over > 0 {
+		for i := range members {
+			if members[i].msg {
+				shortened := shortenJSONString(members[i].value, over)
+				required -= len(members[i].value) - len(shortened)
+				members[i].value = shortened
+				break
+			}
+		}
+	}
+
+	out := []byte{'{'}
+	dropped := 0
+	for _, m := range members {
+		size := len(m.key) + len(m.value) + 2
+		if m.record {
+			required -= size
+		} else if len(out)+size+required+reserve > max {
+			dropped++
+			continue
+		}
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		out = append(append(append(out, m.key...), ':'), m.value...)
+	}
+	if len(out) > 1 {
+		out = append(out, ',')
+	}
+	out = fmt.Appendf(out, `%q:{"bytes":%d,"dropped_attrs":%d}}`+"\n", TruncatedKey, len(p), dropped)
+	return out
+}
+
+// shortenJSONString shortens the JSON string value by at least over bytes,
+// ending it with "...". Values that are not strings are returned unchanged.
+func shortenJSONString(value json.RawMessage, over int) json.RawMessage {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return value
+	}
+	const marker = "..."
+	target := len(value) - over
+	cut := len(s)
+	for {
+		shortened, _ := json.Marshal(s[:cut] + marker)
+		if len(shortened) <= target || cut == 0 {
+			return shortened
+		}
+		// Escaping makes the encoding at least as long as the text
+		cut = max(cut-(len(shortened)-target), 0)
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler_MaxRecordBytes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Deterministic: true, TimeFormat: TimeFormatSeconds, MessageWidth: 6, MaxRecordBytes: 80}))
+	logger.Info("Upload", "name", "a.bin", "body", strings.Repeat("é", 100))
+	logger.Info("Small")
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if len(lines[0]) > 80 || !strings.HasSuffix(lines[0], " ...[truncated 200 bytes, 2 attrs]\n") {
+		t.Errorf("truncated line = %q (%d bytes)", lines[0], len(lines[0]))
+	}
+	if !strings.HasPrefix(lines[0], "[00:00:00] INFO  Upload body=") {
+		t.Errorf("truncated line lost its start: %q", lines[0])
+	}
+	if lines[1] != "[00:00:00] INFO  Small \n" {
+		t.Errorf("short line = %q, want it unchanged", lines[1])
+	}
+}
+
+func TestHandler_MaxRecordBytesWithHead(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		Deterministic:  true,
+		TimeFormat:     TimeFormatSeconds,
+		TimeDelta:      DeltaBeside,
+		LineNumbers:    true,
+		MaxRecordBytes: 80,
+	}))
+	logger.Info("Upload", "body", strings.Repeat("x", 200))
+	logger.Info("Upload", "body", strings.Repeat("x", 60))
+
+	// The time, delta and line number columns count towards the limit
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if len(line) > 80 || !strings.Contains(line, "...[truncated") {
+			t.Errorf("line = %q (%d bytes), want it truncated to 80", line, len(line))
+		}
+	}
+}
+
+func TestLimitLine_Escapes(t *testing.T) {
+	line := "\x1b[31mERROR\x1b[0m " + strings.Repeat("x", 20) + "\x1b[33mwarn" + strings.Repeat("y", 30) + "\n"
+	got := limitLine(line, 70, 1)
+	if len(got) > 70 || !strings.HasSuffix(got, "x"+colorReset+" ...[truncated 43 bytes, 1 attrs]\n") {
+		t.Errorf("limitLine() = %q", got)
+	}
+}
+
+func TestHandler_MaxRecordBytesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{UseJSON: true, MaxRecordBytes: 200}))
+	logger.Info("Upload", "name", "a.bin", "body", strings.Repeat("x", 500), "size", 500)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if buf.Len() > 200 || got["msg"] != "Upload" || got["name"] != "a.bin" || got["body"] != nil {
+		t.Errorf("truncated record = %s", buf.String())
+	}
+	truncated, _ := got[TruncatedKey].(map[string]any)
+	if truncated["dropped_attrs"] != float64(1) {
+		t.Errorf("%s = %v, want 1 dropped attr", TruncatedKey, got[TruncatedKey])
+	}
+}
+
+func TestHandler_MaxRecordBytesJSONLongMessage(t *testing.T) {
+	for _, format := range []JSONFormat{JSONStandard, JSONGoogleCloud, JSONDatadog} {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &Options{UseJSON: true, JSONFormat: format, MaxRecordBytes: 200}))
+		logger.Info(strings.Repeat("é", 300), "a", 1, "b", 2)
+
+		var got map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("format %d: invalid JSON %q: %v", format, buf.String(), err)
+		}
+		msg, _ := got["msg"].(string)
+		if msg == "" {
+			msg, _ = got["message"].(string)
+		}
+		if !strings.HasPrefix(msg, "éé") || !strings.HasSuffix(msg, "...") {
+			t.Errorf("format %d: message = %q, want it shortened with a marker", format, msg)
+		}
+		if got["time"] == nil || got["level"] == nil && got["severity"] == nil && got["status"] == nil {
+			t.Errorf("format %d: record = %s, want time and level kept", format, buf.String())
+		}
+		if buf.Len() > 200 {
+			t.Errorf("format %d: record is %d bytes, want at most 200", format, buf.Len())
+		}
+		truncated, _ := got[TruncatedKey].(map[string]any)
+		if dropped := truncated["dropped_attrs"].(float64); dropped > 2 {
+			t.Errorf("format %d: dropped_attrs = %v, want only user attributes counted", format, dropped)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package humanlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MarkdownStyle selects how a MarkdownHandler lays out records.
+type MarkdownStyle int
+
+const (
+	// MarkdownTable renders records as rows of a table with Time, Level,
+	// Message and Attributes columns.
+	MarkdownTable MarkdownStyle = iota
+
+	// MarkdownBlocks renders each record as a line with the level, time and
+	// message, followed by its attributes in a fenced code block.
+	MarkdownBlocks
+)
+
+// MarkdownOptions configures a MarkdownHandler.
+type MarkdownOptions struct {
+	// Level is the minimum level rendered. Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// Style selects the layout. Default: MarkdownTable
+	Style MarkdownStyle
+
+	// TimeFormat is the layout of record times. Default: "15:04:05.000"
+	TimeFormat string
+}
+
+// MarkdownHandler is a slog.Handler that renders records as GitHub
+// flavored Markdown, so log excerpts can be pasted into issues and
+// incident documents with their formatting intact.
+type MarkdownHandler struct {
+	out    *markdownOutput
+	opts   MarkdownOptions
+	attrs  []slog.Attr
+	groups []string
+}
+
+// markdownOutput is the writer shared by a handler and every handler
+// derived from it.
+type markdownOutput struct {
+	mu      sync.Mutex
+	w       io.Writer
+	started bool
+}
+
+// NewMarkdownHandler creates a MarkdownHandler writing to w.
+func NewMarkdownHandler(w io.Writer, opts MarkdownOptions) *MarkdownHandler {
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = "15:04:05.000"
+	}
+	return &MarkdownHandler{out: &markdownOutput{w: w}, opts: opts}
+}
+
+// Enabled reports whether records at the given level are rendered.
+func (h *MarkdownHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle renders the record. The table header is written before the
+// first row.
+func (h *MarkdownHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = flattenAttrs(attrs, prefix, a)
+		return true
+	})
+
+	var sb strings.Builder
+	if h.opts.Style == MarkdownBlocks {
+		formatMarkdownBlock(&sb, &h.opts, r, attrs)
+	} else {
+		formatMarkdownRow(&sb, &h.opts, r, attrs)
+	}
+
+	o := h.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msg := sb.String()
+	if !o.started {
+		o.started = true
+		if h.opts.Style == MarkdownTable {
+			msg = "| Time | Level | Message | Attributes |\n| --- | --- | --- | --- |\n" + msg
+		}
+	}
+	_, err := io.WriteString(o.w, msg)
+	return err
+}
+
+// WithAttrs returns a new handler whose records include the given attributes.
+func (h *MarkdownHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr{}, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		h2.attrs = flattenAttrs(h2.attrs, prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new handler that qualifies attribute names with name.
+func (h *MarkdownHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// formatMarkdownRow renders a record as a table row:
+//
+//	| 12:00:00.000 | **WARN** | Slow query | `db=main` `ms=1200` |
+func formatMarkdownRow(sb *strings.Builder, opts *MarkdownOptions, r slog.Record, attrs []slog.Attr) {
+	sb.WriteString("| ")
+	if !r.Time.IsZero() {
+		sb.WriteString(r.Time.Format(opts.TimeFormat))
+	}
+	sb.WriteString(" | ")
+	sb.WriteString(markdownLevel(r.Level))
+	sb.WriteString(" | ")
+	sb.WriteString(markdownCell(markdownEscape(r.Message)))
+	sb.WriteString(" |")
+	for _, a := range attrs {
+		sb.WriteString(" ")
+		sb.WriteString(markdownCell(markdownCode(markdownAttr(a))))
+	}
+	sb.WriteString(" |\n")
+}
+
+// formatMarkdownBlock renders a record as a line and a fenced block:
+//
+//	**WARN** `12:00:00.000` Slow query
+//
+//	```
+//	db=main
+//	ms=1200
+//	```
+func formatMarkdownBlock(sb *strings.Builder, opts *MarkdownOptions, r slog.Record, attrs []slog.Attr) {
+	sb.WriteString(markdownLevel(r.Level))
+	if !r.Time.IsZero() {
+		sb.WriteString(" ")
+		sb.WriteString(markdownCode(r.Time.Format(opts.TimeFormat)))
+	}
+	if r.Message != "" {
+		sb.WriteString(" ")
+		sb.WriteString(strings.ReplaceAll(markdownEscape(r.Message), "\n", "  \n"))
+	}
+	sb.WriteString("\n\n")
+	if len(attrs) == 0 {
+		return
+	}
+
+	lines := make([]string, len(attrs))
+	for i, a := range attrs {
+		lines[i] = markdownAttr(a)
+	}
+	body := strings.Join(lines, "\n")
+	fence := strings.Repeat("`", max(3, longestRun(body, '`')+1))
+	sb.WriteString(fence)
+	sb.WriteString("\n")
+	sb.WriteString(body)
+	sb.WriteString("\n")
+	sb.WriteString(fence)
+	sb.WriteString("\n\n")
+}
+
+// markdownLevel returns the level name in bold.
+func markdownLevel(level slog.Level) string {
+	return "**" + level.String() + "**"
+}
+
+// markdownAttr renders a as "key=value", quoting string values like the
+// text handler does.
+func markdownAttr(a slog.Attr) string {
+	s := flatValue(a.Value)
+	if a.Value.Kind() == slog.KindString && needsQuoting(s) {
+		s = strconv.Quote(s)
+	}
+	return a.Key + "=" + s
+}
+
+// markdownEscape backslash-escapes the characters that would otherwise
+// start inline formatting or markup.
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `~`, `\~`, `|`, `\|`,
+)
+
+// markdownCell makes s safe inside a table cell, where pipes end the cell
+// even in code spans and rows cannot span lines. Tables unescape "\|"
+// before inline parsing, so already escaped pipes are escaped again.
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
+
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// markdownCode wraps s in a code span delimited by more backticks than
+// it contains in a row.
+func markdownCode(s string) string {
+	ticks := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return ticks + " " + s + " " + ticks
+	}
+	return ticks + s + ticks
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, n := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			n++
+			longest = max(longest, n)
+		} else {
+			n = 0
+		}
+	}
+	return longest
+}
@@ -0,0 +1,70 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownHandler_Table(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewMarkdownHandler(buf, MarkdownOptions{})
+
+	logger := slog.New(h).WithGroup("req")
+	logger.Warn("Slow *query* | retrying", slog.String("sql", "a|b"), slog.Int("ms", 1200))
+	logger.Info("Done")
+
+	want := "| Time | Level | Message | Attributes |\n" +
+		"| --- | --- | --- | --- |\n"
+	got := buf.String()
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("output = %q, should start with header %q", got, want)
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output = %q, want header, separator and two rows", got)
+	}
+	wantRow := ` | **WARN** | Slow \*query\* \\| retrying | ` + "`req.sql=a\\|b` `req.ms=1200` |"
+	if !strings.HasSuffix(lines[2], wantRow) {
+		t.Errorf("row = %q, should end with %q", lines[2], wantRow)
+	}
+	if !strings.HasSuffix(lines[3], " | **INFO** | Done | |") {
+		t.Errorf("row = %q, want an empty attributes cell", lines[3])
+	}
+}
+
+func TestMarkdownHandler_Blocks(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewMarkdownHandler(buf, MarkdownOptions{Style: MarkdownBlocks, TimeFormat: time.DateOnly})
+
+	r := slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelError, "Upload failed", 0)
+	r.AddAttrs(slog.String("file", "a ```b```"), slog.Int("code", 3))
+	if err := h.Handle(t.Context(), r); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := "**ERROR** `2024-01-02` Upload failed\n\n" +
+		"````\n" +
+		`file="a ` + "```b```" + `"` + "\ncode=3\n" +
+		"````\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "`plain`"},
+		{"a`b", "``a`b``"},
+		{"`x", "`` `x ``"},
+	}
+	for _, tt := range tests {
+		if got := markdownCode(tt.in); got != tt.want {
+			t.Errorf("markdownCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package humanlog
+
+import (
+	"expvar"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// formatBuckets are the upper bounds of the formatting duration histogram.
+var formatBuckets = []time.Duration{
+	time.Microsecond,
+	5 * time.Microsecond,
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+}
+
+// Metrics counts records and writes for one or more handlers. All methods
+// are safe for concurrent use, and a nil *Metrics discards everything.
+type Metrics struct {
+	records      [4]atomic.Uint64 // debug, info, warn, error
+	dropped      atomic.Uint64
+	bytesWritten atomic.Uint64
+	writeErrors  atomic.Uint64
+	formatCounts [8]atomic.Uint64 // one per formatBuckets entry, then +Inf
+	formatNanos  atomic.Uint64    // sum of all formatting durations
+}
+
+// Stats is a snapshot of Metrics.
+type Stats struct {
+	// Records is the number of records handled, keyed by "debug", "info",
+	// "warn" and "error". Custom levels count towards the next lower one.
+	Records map[string]uint64 `json:"records"`
+
+	// Dropped is the number of records discarded by a RateLimitHandler, a
+	// full AsyncHandler queue, a NetworkWriter or an OTLPExporter.
+	Dropped uint64 `json:"dropped"`
+
+	// BytesWritten is the number of bytes written to the output.
+	BytesWritten uint64 `json:"bytes_written"`
+
+	// WriteErrors is the number of failed writes.
+	WriteErrors uint64 `json:"write_errors"`
+
+	// FormatDuration is a histogram of the time spent formatting records.
+	FormatDuration []HistogramBucket `json:"format_duration"`
+
+	// FormatDurationSum is the total time spent formatting records.
+	FormatDurationSum time.Duration `json:"format_duration_sum"`
+}
+
+// HistogramBucket counts observations up to and including UpperBound,
+// excluding those counted by earlier buckets. The last bucket has a zero
+// UpperBound and counts everything slower.
+type HistogramBucket struct {
+	UpperBound time.Duration `json:"le"`
+	Count      uint64        `json:"count"`
+}
+
+// NewMetrics creates an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Stats returns the current counters.
+func (m *Metrics) Stats() Stats {
+	if m == nil {
+		return Stats{}
+	}
+	s := Stats{
+		Records: map[string]uint64{
+			"debug": m.records[0].Load(),
+			"info":  m.records[1].Load(),
+			"warn":  m.records[2].Load(),
+			"error": m.records[3].Load(),
+		},
+		Dropped:           m.dropped.Load(),
+		BytesWritten:      m.bytesWritten.Load(),
+		WriteErrors:       m.writeErrors.Load(),
+		FormatDuration:    make([]HistogramBucket, len(m.formatCounts)),
+		FormatDurationSum: time.Duration(m.formatNanos.Load()),
+	}
+	for i := range m.formatCounts {
+		if i < len(formatBuckets) {
+			s.FormatDuration[i].UpperBound = formatBuckets[i]
+		}
+		s.FormatDuration[i].Count = m.formatCounts[i].Load()
+	}
+	return s
+}
+
+// Publish exposes the counters through expvar under name, so they are
+// served at /debug/vars. Like expvar.Publish, it panics if name is
+// already in use.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return m.Stats() }))
+}
+
+func (m *Metrics) countRecord(level slog.Level) {
+	if m == nil {
+		return
+	}
+	switch {
+	case level >= slog.LevelError:
+		m.records[3].Add(1)
+	case level >= slog.LevelWarn:
+		m.records[2].Add(1)
+	case level >= slog.LevelInfo:
+		m.records[1].Add(1)
+	default:
+		m.records[0].Add(1)
+	}
+}
+
+func (m *Metrics) countDropped() {
+	if m == nil {
+		return
+	}
+	m.dropped.Add(1)
+}
+
+func (m *Metrics) countWrite(n int, err error) {
+	if m == nil {
+		return
+	}
+	m.bytesWritten.Add(uint64(n))
+	if err != nil {
+		m.writeErrors.Add(1)
+	}
+}
+
+func (m *Metrics) observeFormat(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.formatNanos.Add(uint64(d))
+	for i, upper := range formatBuckets {
+		if d <= upper {
+			m.formatCounts[i].Add(1)
+			return
+		}
+	}
+	m.formatCounts[len(formatBuckets)].Add(1)
+}
+
+// Stats returns the handler's counters. Handlers derived with WithAttrs
+// or WithGroup share them.
+func (h *Handler) Stats() Stats {
+	return h.metrics.Stats()
+}
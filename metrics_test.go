@@ -0,0 +1,100 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestHandler_Stats(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Level: slog.LevelDebug, DisableColor: true})
+	logger := slog.New(h).With("component", "worker")
+
+	logger.Debug("One")
+	logger.Info("Two")
+	logger.Info("Three")
+	logger.Warn("Four")
+	logger.Error("Five")
+
+	stats := h.Stats()
+	want := map[string]uint64{"debug": 1, "info": 2, "warn": 1, "error": 1}
+	for level, n := range want {
+		if stats.Records[level] != n {
+			t.Errorf("Records[%q] = %d, want %d", level, stats.Records[level], n)
+		}
+	}
+	if stats.BytesWritten != uint64(buf.Len()) {
+		t.Errorf("BytesWritten = %d, want %d", stats.BytesWritten, buf.Len())
+	}
+	var formatted uint64
+	for _, b := range stats.FormatDuration {
+		formatted += b.Count
+	}
+	if formatted != 5 {
+		t.Errorf("FormatDuration counts %d records, want 5", formatted)
+	}
+
+	failing := NewHandler(failingWriter{}, &Options{DisableColor: true})
+	slog.New(failing).Info("Lost")
+	if got := failing.Stats().WriteErrors; got != 1 {
+		t.Errorf("WriteErrors = %d, want 1", got)
+	}
+}
+
+func TestMetrics_SharedAndPublished(t *testing.T) {
+	metrics := NewMetrics()
+	var buf bytes.Buffer
+	rl := NewRateLimitHandler(
+		NewHandler(&buf, &Options{DisableColor: true, Metrics: metrics}),
+		RateLimitOptions{Rate: 1, Metrics: metrics},
+	)
+	logger := slog.New(rl)
+	logger.Info("Allowed")
+	logger.Info("Dropped")
+
+	metrics.Publish("humanlog_test_metrics")
+	var stats Stats
+	if err := json.Unmarshal([]byte(expvar.Get("humanlog_test_metrics").String()), &stats); err != nil {
+		t.Fatalf("expvar value is not JSON: %v", err)
+	}
+	if stats.Records["info"] != 1 || stats.Dropped != 1 {
+		t.Errorf("published stats = %+v, want 1 info record and 1 dropped", stats)
+	}
+}
+
+func TestMetrics_WritePrometheus(t *testing.T) {
+	metrics := NewMetrics()
+	h := NewHandler(failingWriter{}, &Options{DisableColor: true, Metrics: metrics})
+	logger := slog.New(h)
+	logger.Error("Lost")
+	logger.Error("Lost again")
+
+	rec := httptest.NewRecorder()
+	metrics.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`log_records_total{level="error"} 2`,
+		`log_records_total{level="info"} 0`,
+		"log_write_errors_total 2",
+		"log_dropped_total 0",
+		`log_format_duration_seconds_bucket{le="+Inf"} 2`,
+		"log_format_duration_seconds_count 2",
+		"# TYPE log_format_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("exposition missing %q:\n%s", want, body)
+		}
+	}
+}
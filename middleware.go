@@ -13,6 +13,8 @@ const (
 	RequestIDKey ContextKey = "request_id"
 	// TraceIDKey is the context key for trace IDs
 	TraceIDKey ContextKey = "trace_id"
+	// SpanIDKey is the context key for span IDs
+	SpanIDKey ContextKey = "span_id"
 	// UserIDKey is the context key for user IDs
 	UserIDKey ContextKey = "user_id"
 )
@@ -29,12 +31,64 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, TraceIDKey, traceID)
 }
 
+// WithSpanID adds a span ID to the context that will be automatically
+// included in all log entries when using the contextual logging functions.
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, SpanIDKey, spanID)
+}
+
 // WithUserID adds a user ID to the context that will be automatically
 // included in all log entries when using the contextual logging functions.
 func WithUserID(ctx context.Context, userID string) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
 
+// minLevelKey is the context key for the level set by WithMinLevel.
+type minLevelKey struct{}
+
+// WithMinLevel returns a context that overrides the minimum level of
+// Handler and ComponentHandler for records logged with it, so a single
+// request can emit debug logs while the global level stays at Info.
+// Records must be logged with the context, e.g. through Logger.DebugContext
+// or ContextLogger, for the override to apply.
+func WithMinLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, minLevelKey{}, level)
+}
+
+// MinLevelFromContext returns the level set by WithMinLevel, if any.
+func MinLevelFromContext(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(minLevelKey{}).(slog.Leveler)
+	if !ok {
+		return 0, false
+	}
+	return level.Level(), true
+}
+
+// contextAttrsKey is the context key for attributes added with WithContextAttrs.
+type contextAttrsKey struct{}
+
+// WithContextAttrs adds attributes to the context that Handler includes in
+// every record logged with it.
+func WithContextAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev := ContextAttrs(ctx)
+	all := make([]slog.Attr, 0, len(prev)+len(attrs))
+	all = append(all, prev...)
+	all = append(all, attrs...)
+	return context.WithValue(ctx, contextAttrsKey{}, all)
+}
+
+// ContextAttrs returns the attributes added with WithContextAttrs.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
 // ContextLogger wraps an slog.Logger to automatically extract and include
 // correlation IDs from context in log entries.
 type ContextLogger struct {
@@ -59,6 +113,10 @@ func (cl *ContextLogger) extractContextAttrs(ctx context.Context) []slog.Attr {
 		attrs = append(attrs, slog.String("trace_id", traceID))
 	}
 
+	if spanID, ok := ctx.Value(SpanIDKey).(string); ok && spanID != "" {
+		attrs = append(attrs, slog.String("span_id", spanID))
+	}
+
 	if userID, ok := ctx.Value(UserIDKey).(string); ok && userID != "" {
 		attrs = append(attrs, slog.String("user_id", userID))
 	}
@@ -95,6 +153,11 @@ func (cl *ContextLogger) Debug(ctx context.Context, msg string, attrs ...slog.At
 	cl.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
 }
 
+// Trace logs a trace message, below debug, with context attributes
+func (cl *ContextLogger) Trace(ctx context.Context, msg string, attrs ...slog.Attr) {
+	cl.LogAttrs(ctx, LevelTrace, msg, attrs...)
+}
+
 // With returns a new ContextLogger with additional attributes
 func (cl *ContextLogger) With(attrs ...slog.Attr) *ContextLogger {
 	// Convert slog.Attr to []any for compatibility with logger.With
@@ -0,0 +1,196 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// MultiHandler is TeeHandler under the name used when no child is a
+// human-readable Handler. Every child decides for itself whether a record
+// is enabled, and a child that fails does not prevent delivery to the
+// others. Slow children can be wrapped with NewAsyncHandler so they do not
+// hold up the caller.
+type MultiHandler = TeeHandler
+
+// NewMultiHandler creates a MultiHandler dispatching to handlers.
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &TeeHandler{branches: append([]slog.Handler{}, handlers...)}
+}
+
+// anyEnabled reports whether any of handlers is enabled for level.
+func anyEnabled(ctx context.Context, handlers []slog.Handler, level slog.Level) bool {
+	for _, h := range handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleAll passes a copy of r to every handler enabled for its level and
+// joins the errors.
+func handleAll(ctx context.Context, handlers []slog.Handler, r slog.Record) error {
+	var errs []error
+	for _, h := range handlers {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deriveAll applies derive to each of handlers.
+func deriveAll(handlers []slog.Handler, derive func(slog.Handler) slog.Handler) []slog.Handler {
+	derived := make([]slog.Handler, len(handlers))
+	for i, h := range handlers {
+		derived[i] = derive(h)
+	}
+	return derived
+}
+
+// defaultAsyncBufferSize is the default queue length of an AsyncHandler.
+const defaultAsyncBufferSize = 1024
+
+// AsyncOptions configures an AsyncHandler.
+type AsyncOptions struct {
+	// BufferSize is the number of records queued before Handle blocks,
+	// or drops records if DropWhenFull is set.
+	// Default: 1024
+	BufferSize int
+
+	// DropWhenFull drops records instead of blocking when the queue is full.
+	DropWhenFull bool
+
+	// OnError is called from the background goroutine with errors returned
+	// by the wrapped handler. Default: errors are discarded
+	OnError func(error)
+
+	// Metrics, if set, counts records dropped because the queue was full.
+	Metrics *Metrics
+}
+
+// AsyncHandler wraps a slow slog.Handler, such as a network sink, and
+// handles its records on a background goroutine. Handle only queues the
+// record, so errors from the wrapped handler are reported through
+// AsyncOptions.OnError rather than returned. Call Close to drain the queue.
+type AsyncHandler struct {
+	h     slog.Handler
+	queue *asyncQueue
+}
+
+// asyncQueue is the queue and worker shared by an AsyncHandler and every
+// handler derived from it.
+type asyncQueue struct {
+	opts    AsyncOptions
+	mu      sync.RWMutex // guards closed against concurrent sends
+	closed  bool
+	ch      chan asyncRecord
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+type asyncRecord struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// NewAsyncHandler starts a background goroutine handling records for h.
+func NewAsyncHandler(h slog.Handler, opts *AsyncOptions) *AsyncHandler {
+	var options AsyncOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultAsyncBufferSize
+	}
+
+	q := &asyncQueue{
+		opts: options,
+		ch:   make(chan asyncRecord, options.BufferSize),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return &AsyncHandler{h: h, queue: q}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (a *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return a.h.Enabled(ctx, level)
+}
+
+// Handle queues the record for the background goroutine. It only returns
+// an error once Close has been called.
+func (a *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	return a.queue.send(asyncRecord{ctx: context.WithoutCancel(ctx), h: a.h, r: r.Clone()})
+}
+
+// WithAttrs returns a new AsyncHandler sharing the queue, whose records
+// include the given attributes.
+func (a *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{h: a.h.WithAttrs(attrs), queue: a.queue}
+}
+
+// WithGroup returns a new AsyncHandler sharing the queue, whose records use
+// the given group.
+func (a *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{h: a.h.WithGroup(name), queue: a.queue}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (a *AsyncHandler) Dropped() uint64 {
+	return a.queue.dropped.Load()
+}
+
+// Close stops accepting records and waits until every queued record has
+// been handled. It is safe to call more than once.
+func (a *AsyncHandler) Close() error {
+	q := a.queue
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+// errAsyncClosed is returned by Handle after Close.
+var errAsyncClosed = errors.New("humanlog: async handler is closed")
+
+func (q *asyncQueue) send(rec asyncRecord) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return errAsyncClosed
+	}
+	if q.opts.DropWhenFull {
+		select {
+		case q.ch <- rec:
+		default:
+			q.dropped.Add(1)
+			q.opts.Metrics.countDropped()
+		}
+		return nil
+	}
+	q.ch <- rec
+	return nil
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for rec := range q.ch {
+		if err := rec.h.Handle(rec.ctx, rec.r); err != nil && q.opts.OnError != nil {
+			q.opts.OnError(err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingHandler accepts every record and always fails.
+type failingHandler struct{ err error }
+
+func (f failingHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (f failingHandler) Handle(context.Context, slog.Record) error { return f.err }
+func (f failingHandler) WithAttrs([]slog.Attr) slog.Handler        { return f }
+func (f failingHandler) WithGroup(string) slog.Handler             { return f }
+
+func TestMultiHandler_PerChildLevelsAndErrors(t *testing.T) {
+	debug := new(bytes.Buffer)
+	errs := new(bytes.Buffer)
+	boom := errors.New("boom")
+
+	m := NewMultiHandler(
+		failingHandler{err: boom},
+		NewHandler(debug, &Options{Level: slog.LevelDebug, DisableColor: true}),
+		NewHandler(errs, &Options{Level: slog.LevelError, DisableColor: true}),
+	)
+
+	ctx := context.Background()
+	err := m.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelDebug, "Debug detail", 0))
+	if !errors.Is(err, boom) {
+		t.Errorf("Handle() error = %v, want %v", err, boom)
+	}
+	_ = m.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelError, "Failure", 0))
+
+	if got := debug.String(); !strings.Contains(got, "Debug detail") || !strings.Contains(got, "Failure") {
+		t.Errorf("debug child output = %q, want both records despite failing sibling", got)
+	}
+	if got := errs.String(); strings.Contains(got, "Debug detail") || !strings.Contains(got, "Failure") {
+		t.Errorf("error child output = %q, want only the error", got)
+	}
+}
+
+func TestAsyncHandler(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	buf := new(bytes.Buffer)
+
+	async := NewAsyncHandler(NewHandler(buf, &Options{DisableColor: true}), &AsyncOptions{BufferSize: 4})
+	failing := NewAsyncHandler(failingHandler{err: errors.New("sink down")}, &AsyncOptions{
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+	m := NewMultiHandler(async, failing)
+	logger := slog.New(m).With(slog.String("component", "db"))
+
+	for range 10 {
+		logger.Info("Queued")
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := strings.Count(buf.String(), "component=db"); got != 10 {
+		t.Errorf("async child wrote %d records, want 10", got)
+	}
+	if len(reported) != 10 {
+		t.Errorf("OnError called %d times, want 10", len(reported))
+	}
+	if err := async.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)); err == nil {
+		t.Error("Handle() after Close should fail")
+	}
+}
@@ -0,0 +1,56 @@
+package humanlog
+
+import (
+	"hash/fnv"
+	"log/slog"
+)
+
+// defaultPrefixWidth is the default width of the logger name column.
+const defaultPrefixWidth = 16
+
+// nameColors are the colors logger names are hashed to. Red and yellow are
+// left out so names are not mistaken for error and warning levels.
+var nameColors = []string{
+	"\033[36m", "\033[35m", "\033[32m", "\033[34m",
+	"\033[96m", "\033[95m", "\033[92m", "\033[94m",
+}
+
+// WithName returns a new Handler whose logger name is h's name with name
+// appended as a dotted segment, so WithName("db") followed by
+// WithName("pool") names records "db.pool". The name is rendered in its own
+// column after the level, and as the NameKey attribute in JSON output.
+func (h *Handler) WithName(name string) slog.Handler {
+	if h.name != "" {
+		name = h.name + "." + name
+	}
+	h2 := h.clone()
+	h2.name = name
+	return h2
+}
+
+// Name returns the handler's logger name, or "" if it has none.
+func (h *Handler) Name() string {
+	return h.name
+}
+
+// formatName renders the logger name column: the name padded to
+// PrefixWidth, or truncated from the start so the most specific segment
+// stays visible, and colored by a hash of the name.
+func (h *Handler) formatName() string {
+	width := h.opts.PrefixWidth
+	if width <= 0 {
+		width = defaultPrefixWidth
+	}
+	s := Column{Width: width, Truncate: TruncateStart}.fit(h.name)
+	if h.opts.DisableColor {
+		return s
+	}
+	return nameColor(h.name) + s + colorReset
+}
+
+// nameColor returns the color of a logger name, stable across runs.
+func nameColor(name string) string {
+	f := fnv.New32a()
+	_, _ = f.Write([]byte(name))
+	return nameColors[f.Sum32()%uint32(len(nameColors))]
+}
@@ -0,0 +1,59 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler_WithName(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Prefix: "app", PrefixWidth: 10, DisableColor: true, MessageWidth: 10, Deterministic: true, TimeFormat: TimeFormatSeconds})
+	root := slog.New(h)
+	db := Named(root, "db")
+	pool := Named(db.With("shard", 2).WithGroup("q"), "connections")
+
+	root.Info("Started")
+	db.Info("Connected")
+	pool.Warn("Exhausted", "size", 8)
+
+	want := "[00:00:00] INFO  app        Started   \n" +
+		"[00:00:00] INFO  app.db     Connected \n" +
+		"[00:00:00] WARN  ...ections Exhausted  q.size=8 shard=2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%q\nwant\n%q", got, want)
+	}
+	if got := pool.Handler().(*Handler).Name(); got != "app.db.connections" {
+		t.Errorf("Name() = %q, want app.db.connections", got)
+	}
+}
+
+func TestHandler_WithNameUnnamed(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(NewHandler(&buf, &Options{DisableColor: true, MessageWidth: 5})).Info("Plain")
+	if got := buf.String(); !strings.Contains(got, "INFO  Plain") {
+		t.Errorf("unnamed handler output = %q, want no name column", got)
+	}
+
+	buf.Reset()
+	slog.New(NewHandler(&buf, &Options{Prefix: "api"})).Info("Colored")
+	if got := buf.String(); !strings.Contains(got, nameColor("api")+"api ") {
+		t.Errorf("named handler output = %q, want colored name", got)
+	}
+}
+
+func TestHandler_WithNameJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Named(slog.New(NewHandler(&buf, &Options{UseJSON: true, Prefix: "app"})), "db")
+	logger.Info("Connected")
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if got[NameKey] != "app.db" {
+		t.Errorf("%s = %v, want app.db", NameKey, got[NameKey])
+	}
+}
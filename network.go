@@ -0,0 +1,256 @@
+package humanlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Framing selects how a NetworkWriter delimits records on the wire.
+type Framing int
+
+const (
+	// FramingNewline terminates each record with a newline, as expected by
+	// collectors reading newline-delimited JSON. This is the default.
+	FramingNewline Framing = iota
+	// FramingLengthPrefix precedes each record with its length as a 4-byte
+	// big-endian integer.
+	FramingLengthPrefix
+)
+
+// Default NetworkWriter settings.
+const (
+	defaultNetworkBuffer       = 1000
+	defaultNetworkMinBackoff   = 100 * time.Millisecond
+	defaultNetworkMaxBackoff   = 30 * time.Second
+	defaultNetworkDialTimeout  = 5 * time.Second
+	defaultNetworkWriteTimeout = 5 * time.Second
+)
+
+// NetworkOptions configures a NetworkWriter.
+type NetworkOptions struct {
+	// Network is "tcp", "udp" or any other network accepted by net.Dial.
+	Network string
+
+	// Address is the collector's address, e.g. "logs.example.com:5170".
+	Address string
+
+	// Framing selects how records are delimited. Default: FramingNewline
+	Framing Framing
+
+	// BufferSize is the number of records queued while the collector is
+	// slow or unreachable. Further records are dropped and counted.
+	// Default: 1000
+	BufferSize int
+
+	// MinBackoff is the delay before the first reconnection attempt. It
+	// doubles after each failure up to MaxBackoff.
+	// Default: 100ms
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between reconnection attempts.
+	// Default: 30s
+	MaxBackoff time.Duration
+
+	// DialTimeout limits each connection attempt. Default: 5s
+	DialTimeout time.Duration
+
+	// WriteTimeout limits sending each record, so a stalled collector is
+	// treated as a lost connection. Default: 5s
+	WriteTimeout time.Duration
+
+	// Metrics, if set, counts dropped records, see Stats.Dropped.
+	Metrics *Metrics
+}
+
+// NetworkWriter is an io.WriteCloser that sends records to a remote
+// collector. Each Write is one record, so it can be passed directly to
+// NewHandler, typically with UseJSON. Writes are queued and sent in the
+// background: a lost connection is re-established with exponential
+// backoff, and records are buffered meanwhile up to BufferSize.
+type NetworkWriter struct {
+	opts NetworkOptions
+
+	mu      sync.RWMutex // guards closed against concurrent sends
+	closed  bool
+	queue   chan []byte
+	stop    chan struct{}
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	conn net.Conn // owned by the run goroutine
+}
+
+// NewNetworkWriter creates a NetworkWriter and starts connecting to the
+// collector in the background.
+func NewNetworkWriter(opts NetworkOptions) (*NetworkWriter, error) {
+	if opts.Network == "" || opts.Address == "" {
+		return nil, errors.New("humanlog: network writer requires a network and address")
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultNetworkBuffer
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultNetworkMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultNetworkMaxBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultNetworkDialTimeout
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultNetworkWriteTimeout
+	}
+
+	w := &NetworkWriter{
+		opts:  opts,
+		queue: make(chan []byte, opts.BufferSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write queues p as one record. It never blocks on the network; if the
+// queue is full the record is dropped.
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return 0, errNetworkClosed
+	}
+	select {
+	case w.queue <- frame(p, w.opts.Framing):
+	default:
+		w.drop()
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of records dropped because the queue was full
+// or the collector was unreachable when the writer was closed.
+func (w *NetworkWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops accepting records, sends those still queued if the collector
+// is reachable, and closes the connection.
+func (w *NetworkWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+// errNetworkClosed is returned by Write after Close.
+var errNetworkClosed = errors.New("humanlog: network writer is closed")
+
+// frame returns a copy of p delimited according to framing.
+func frame(p []byte, framing Framing) []byte {
+	if framing == FramingLengthPrefix {
+		b := make([]byte, 4, 4+len(p))
+		binary.BigEndian.PutUint32(b, uint32(len(p)))
+		return append(b, p...)
+	}
+	b := make([]byte, len(p), len(p)+1)
+	copy(b, p)
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// drop counts a dropped record.
+func (w *NetworkWriter) drop() {
+	w.dropped.Add(1)
+	w.opts.Metrics.countDropped()
+}
+
+// run sends queued frames, reconnecting as needed, until the queue is
+// closed and drained. Failed dials and writes alike are retried with
+// backoff, which is only reset once a record has been sent, so a peer
+// that accepts connections and then resets them is not hammered.
+func (w *NetworkWriter) run() {
+	defer close(w.done)
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+
+	backoff := w.opts.MinBackoff
+	for f := range w.queue {
+		for {
+			if w.conn == nil {
+				conn, err := net.DialTimeout(w.opts.Network, w.opts.Address, w.opts.DialTimeout)
+				if err != nil {
+					if w.stopping() {
+						w.dropRemaining()
+						return
+					}
+					w.sleep(backoff)
+					backoff = min(backoff*2, w.opts.MaxBackoff)
+					continue
+				}
+				w.conn = conn
+			}
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteTimeout))
+			if _, err := w.conn.Write(f); err != nil {
+				_ = w.conn.Close()
+				w.conn = nil
+				if w.stopping() {
+					w.dropRemaining()
+					return
+				}
+				w.sleep(backoff)
+				backoff = min(backoff*2, w.opts.MaxBackoff)
+				continue
+			}
+			backoff = w.opts.MinBackoff
+			break
+		}
+	}
+}
+
+// stopping reports whether Close has been called.
+func (w *NetworkWriter) stopping() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for d, returning early if the writer is closed.
+func (w *NetworkWriter) sleep(d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-w.stop:
+	}
+}
+
+// dropRemaining discards the current frame and everything still queued.
+func (w *NetworkWriter) dropRemaining() {
+	w.drop()
+	for range w.queue {
+		w.drop()
+	}
+}
@@ -0,0 +1,167 @@
+package humanlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNetworkWriter_NewlineJSON(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	w, err := NewNetworkWriter(NetworkOptions{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatalf("NewNetworkWriter() error = %v", err)
+	}
+	logger := slog.New(NewHandler(w, &Options{UseJSON: true}))
+	logger.Info("First")
+	logger.Info("Second")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"First", "Second"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() error = %v", err)
+		}
+		if !strings.Contains(line, `"msg":"`+want+`"`) {
+			t.Errorf("line = %q, want %s", line, want)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestNetworkWriter_BuffersUntilCollectorIsUp(t *testing.T) {
+	// Reserve an address, then release it so the first dials fail
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	w, err := NewNetworkWriter(NetworkOptions{
+		Network:    "tcp",
+		Address:    addr,
+		Framing:    FramingLengthPrefix,
+		BufferSize: 2,
+		MinBackoff: 5 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewNetworkWriter() error = %v", err)
+	}
+	for _, rec := range []string{"one", "two", "three", "four"} {
+		_, _ = w.Write([]byte(rec))
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not listen on %s again: %v", addr, err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Depending on timing one record may already be in flight, so two or
+	// three arrive and the rest is dropped
+	var got []string
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		var n uint32
+		if err := binary.Read(conn, binary.BigEndian, &n); err != nil {
+			break
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(conn, b); err != nil {
+			t.Fatalf("read record error = %v", err)
+		}
+		got = append(got, string(b))
+	}
+	if len(got) < 2 || !strings.HasPrefix("one,two,three,four", strings.Join(got, ",")) {
+		t.Errorf("received %v, want the oldest records in order", got)
+	}
+	if d := w.Dropped(); int(d)+len(got) != 4 {
+		t.Errorf("Dropped() = %d with %d received, want 4 in total", d, len(got))
+	}
+	_ = w.Close()
+}
+
+func TestNetworkWriter_BacksOffWhenPeerResets(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	var accepts atomic.Int64
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepts.Add(1)
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			_ = conn.Close()
+		}
+	}()
+
+	metrics := NewMetrics()
+	w, err := NewNetworkWriter(NetworkOptions{
+		Network:    "tcp",
+		Address:    ln.Addr().String(),
+		MinBackoff: 50 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+		BufferSize: 10,
+		Metrics:    metrics,
+	})
+	if err != nil {
+		t.Fatalf("NewNetworkWriter() error = %v", err)
+	}
+	for range 100 {
+		_, _ = w.Write([]byte("record"))
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		_ = w.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not return while the peer kept resetting")
+	}
+
+	if n := accepts.Load(); n > 30 {
+		t.Errorf("peer accepted %d connections in 300ms, want backoff between attempts", n)
+	}
+	if w.Dropped() == 0 || metrics.Stats().Dropped != w.Dropped() {
+		t.Errorf("Dropped() = %d, Metrics dropped = %d, want the same non-zero count", w.Dropped(), metrics.Stats().Dropped)
+	}
+}
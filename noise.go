@@ -0,0 +1,236 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NoiseRule matches records that are expected and uninteresting, such as
+// health checks and keepalives.
+type NoiseRule struct {
+	// Name identifies the rule in summaries. Default: "rule N", counting
+	// from 1 in NoiseOptions.Rules
+	Name string
+
+	// Match reports whether a record is noise, e.g.
+	// MessageMatches(regexp.MustCompile(`^health check`)) or
+	// AttrEquals("path", "/healthz"). The record also carries the
+	// attributes added with Logger.With.
+	Match FilterFunc
+
+	// Level, if set, demotes matching records to this level, typically
+	// slog.LevelDebug, instead of dropping them. Records are never
+	// promoted: a rule does nothing to records already below Level.
+	Level slog.Leveler
+}
+
+// NoiseOptions configures a NoiseHandler.
+type NoiseOptions struct {
+	// Rules are tried in order; the first one matching a record applies.
+	Rules []NoiseRule
+
+	// SummaryInterval, if positive, logs a record at Info per rule that
+	// matched since the previous summary, e.g. every hour, counting the
+	// records it suppressed or demoted. Call Close to stop the summaries.
+	SummaryInterval time.Duration
+}
+
+// NoiseHandler is a slog.Handler that suppresses or demotes known-noisy
+// records by rule, so periodic chatter can be silenced without changing
+// the code that logs it. Other records pass through unchanged.
+type NoiseHandler struct {
+	h      slog.Handler
+	attrs  []slog.Attr
+	groups []string
+	state  *noiseState
+}
+
+// noiseState is shared by a NoiseHandler and every handler derived from
+// it.
+type noiseState struct {
+	rules    []NoiseRule
+	interval time.Duration
+	h        slog.Handler // the root handler summaries are logged through
+
+	mu     sync.Mutex
+	counts []noiseCount
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// noiseCount is the number of records a rule matched since the last
+// summary.
+type noiseCount struct {
+	suppressed uint64
+	demoted    uint64
+}
+
+// NewNoiseHandler returns a handler that passes records to h, applying
+// the noise rules of opts.
+//
+// Example:
+//
+//	h := humanlog.NewNoiseHandler(base, humanlog.NoiseOptions{
+//		Rules: []humanlog.NoiseRule{
+//			{Name: "healthz", Match: humanlog.AttrEquals("path", "/healthz")},
+//			{Name: "keepalive", Match: humanlog.MessageMatches(regexp.MustCompile(`^keepalive`)), Level: slog.LevelDebug},
+//		},
+//		SummaryInterval: time.Hour,
+//	})
+func NewNoiseHandler(h slog.Handler, opts NoiseOptions) *NoiseHandler {
+	rules := append([]NoiseRule{}, opts.Rules...)
+	for i := range rules {
+		if rules[i].Name == "" {
+			rules[i].Name = "rule " + strconv.Itoa(i+1)
+		}
+	}
+	s := &noiseState{rules: rules, interval: opts.SummaryInterval, h: h, counts: make([]noiseCount, len(rules))}
+	if opts.SummaryInterval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.summarizeEvery(opts.SummaryInterval)
+	}
+	return &NoiseHandler{h: h, state: s}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (n *NoiseHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return n.h.Enabled(ctx, level)
+}
+
+// Handle drops or demotes the record if a rule matches it, and otherwise
+// passes it to the wrapped handler.
+func (n *NoiseHandler) Handle(ctx context.Context, r slog.Record) error {
+	view := r
+	if len(n.attrs) > 0 {
+		view = slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		view.AddAttrs(n.attrs...)
+		r.Attrs(func(a slog.Attr) bool {
+			view.AddAttrs(groupAttr(n.groups, a))
+			return true
+		})
+	}
+
+	s := n.state
+	for i, rule := range s.rules {
+		if rule.Match == nil || !rule.Match(ctx, view) {
+			continue
+		}
+		if rule.Level == nil {
+			s.count(i, false)
+			return nil
+		}
+		if level := rule.Level.Level(); r.Level > level {
+			s.count(i, true)
+			r.Level = level
+			if !n.h.Enabled(ctx, level) {
+				return nil
+			}
+		}
+		break
+	}
+	return n.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new NoiseHandler sharing the counts, whose records
+// include the given attributes.
+func (n *NoiseHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	n2 := *n
+	n2.h = n.h.WithAttrs(attrs)
+	n2.attrs = append([]slog.Attr{}, n.attrs...)
+	for _, a := range attrs {
+		n2.attrs = append(n2.attrs, groupAttr(n.groups, a))
+	}
+	return &n2
+}
+
+// WithGroup returns a new NoiseHandler sharing the counts, which
+// qualifies attributes with name.
+func (n *NoiseHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return n
+	}
+	n2 := *n
+	n2.h = n.h.WithGroup(name)
+	n2.groups = append(append([]string{}, n.groups...), name)
+	return &n2
+}
+
+// Close stops the background summaries and logs a final one.
+func (n *NoiseHandler) Close() error {
+	s := n.state
+	if s.stop == nil {
+		return nil
+	}
+	s.mu.Lock()
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	<-s.done
+	return nil
+}
+
+func (s *noiseState) count(rule int, demoted bool) {
+	if s.interval <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if demoted {
+		s.counts[rule].demoted++
+	} else {
+		s.counts[rule].suppressed++
+	}
+}
+
+func (s *noiseState) summarizeEvery(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize()
+		case <-s.stop:
+			s.summarize()
+			return
+		}
+	}
+}
+
+// summarize logs one record per rule that matched since the last summary.
+func (s *noiseState) summarize() {
+	s.mu.Lock()
+	var records []slog.Record
+	now := time.Now()
+	for i, c := range s.counts {
+		if c == (noiseCount{}) {
+			continue
+		}
+		r := slog.NewRecord(now, slog.LevelInfo, "Noise summary", 0)
+		r.AddAttrs(slog.String("rule", s.rules[i].Name))
+		if c.suppressed > 0 {
+			r.AddAttrs(slog.Uint64("suppressed", c.suppressed))
+		}
+		if c.demoted > 0 {
+			r.AddAttrs(slog.Uint64("demoted", c.demoted))
+		}
+		records = append(records, r)
+		s.counts[i] = noiseCount{}
+	}
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	for _, r := range records {
+		if s.h.Enabled(ctx, r.Level) {
+			_ = s.h.Handle(ctx, r)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNoiseHandler(t *testing.T) {
+	var buf bytes.Buffer
+	nh := NewNoiseHandler(NewHandler(&buf, &Options{Level: slog.LevelDebug, DisableColor: true}), NoiseOptions{
+		Rules: []NoiseRule{
+			{Name: "healthz", Match: AttrEquals("req.path", "/healthz")},
+			{Match: MessageMatches(regexp.MustCompile(`^keepalive`)), Level: slog.LevelDebug},
+		},
+		SummaryInterval: time.Hour,
+	})
+	logger := slog.New(nh)
+
+	for range 3 {
+		logger.With("req", slog.GroupValue(slog.String("path", "/healthz"))).Info("Request served")
+	}
+	logger.Info("Request served", "req", slog.GroupValue(slog.String("path", "/orders")))
+	logger.Info("keepalive sent")
+	logger.Warn("keepalive late")
+	logger.Debug("keepalive queued") // already at the rule's level
+
+	out := buf.String()
+	if strings.Contains(out, "/healthz") {
+		t.Errorf("output = %q, want health checks suppressed", out)
+	}
+	if !strings.Contains(out, "req.path=/orders") {
+		t.Errorf("output = %q, want other records passed through", out)
+	}
+	for _, msg := range []string{"keepalive sent", "keepalive late", "keepalive queued"} {
+		if !strings.Contains(out, "DEBUG "+msg) {
+			t.Errorf("output = %q, want %q at DEBUG", out, msg)
+		}
+	}
+
+	buf.Reset()
+	if err := nh.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{"rule=healthz suppressed=3", `rule="rule 2" demoted=2`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestNoiseHandler_DemotedBelowLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewNoiseHandler(NewHandler(&buf, &Options{DisableColor: true}), NoiseOptions{
+		Rules: []NoiseRule{{Match: MessageMatches(regexp.MustCompile(`^ping`)), Level: slog.LevelDebug}},
+	}))
+
+	logger.Info("ping")
+	logger.Info("pong")
+	if out := buf.String(); strings.Contains(out, "ping") || !strings.Contains(out, "pong") {
+		t.Errorf("output = %q, want the demoted record dropped by the handler level", out)
+	}
+}
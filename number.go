@@ -0,0 +1,67 @@
+package humanlog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatInt formats an integer attribute value, grouping digits with the
+// configured thousands separator.
+func formatInt(n int64, opts *Options) string {
+	return groupDigits(strconv.FormatInt(n, 10), opts.ThousandsSeparator)
+}
+
+// formatUint formats an unsigned integer attribute value, grouping digits
+// with the configured thousands separator.
+func formatUint(n uint64, opts *Options) string {
+	return groupDigits(strconv.FormatUint(n, 10), opts.ThousandsSeparator)
+}
+
+// formatFloat formats a float attribute value with the configured precision,
+// grouping the integer part with the configured thousands separator.
+func formatFloat(f float64, opts *Options) string {
+	if opts.FloatPrecision <= 0 && opts.ThousandsSeparator == "" {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	prec := -1
+	if opts.FloatPrecision > 0 {
+		prec = opts.FloatPrecision
+	}
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	intPart = groupDigits(intPart, opts.ThousandsSeparator)
+	if hasFrac {
+		return intPart + "." + frac
+	}
+	return intPart
+}
+
+// groupDigits inserts sep between every group of three digits in the
+// decimal string s, preserving a leading sign.
+func groupDigits(s, sep string) string {
+	if sep == "" {
+		return s
+	}
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	first := len(s) % 3
+	if first == 0 {
+		first = 3
+	}
+	sb.WriteString(s[:first])
+	for i := first; i < len(s); i += 3 {
+		sb.WriteString(sep)
+		sb.WriteString(s[i : i+3])
+	}
+	return sb.String()
+}
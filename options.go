@@ -1,8 +1,57 @@
 package humanlog
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"time"
+)
+
+// Preset TimeFormat values.
+const (
+	// TimeFormatRelative is a special TimeFormat value that renders timestamps
+	// as the elapsed time since the handler was created.
+	TimeFormatRelative = "relative"
+
+	// TimeFormatSeconds shows hours, minutes and seconds. This is the default.
+	TimeFormatSeconds = "15:04:05"
+	// TimeFormatMillis adds millisecond precision, e.g. "15:04:05.123".
+	TimeFormatMillis = "15:04:05.000"
+	// TimeFormatMicros adds microsecond precision, e.g. "15:04:05.123456".
+	TimeFormatMicros = "15:04:05.000000"
+)
+
+// SequenceKey is the attribute key used by Options.AddSequence.
+const SequenceKey = "seq"
+
+// DeterministicTime is the timestamp of every record in deterministic mode.
+var DeterministicTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DeltaMode controls whether the time since the previous record is shown.
+type DeltaMode int
+
+const (
+	// DeltaNone shows only the timestamp.
+	DeltaNone DeltaMode = iota
+	// DeltaBeside shows the delta column after the timestamp.
+	DeltaBeside
+	// DeltaReplace shows the delta column instead of the timestamp.
+	DeltaReplace
+)
+
+// SyncPolicy controls when output is synced to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncOnClose syncs when the handler is closed. This is the default.
+	SyncOnClose SyncPolicy = iota
+	// SyncErrors also flushes and syncs after every record at LevelError
+	// or above, so error records survive a crash at the cost of a sync
+	// per error.
+	SyncErrors
+	// SyncNever leaves syncing to the operating system.
+	SyncNever
 )
 
 // Options configures the human-readable slog.Handler.
@@ -13,14 +62,90 @@ type Options struct {
 	// Writer is where the logs are written to.
 	Writer io.Writer
 
+	// OnError, if set, is called with every error writing to Writer, which
+	// would otherwise vanish inside slog, e.g. a full disk or broken pipe.
+	// It is called while the output is locked and must not log through
+	// the same handler.
+	OnError func(error)
+
+	// FallbackWriter, if set, receives records that could not be written
+	// to Writer, e.g. os.Stderr. Handle only reports an error if writing
+	// to the fallback fails as well.
+	FallbackWriter io.Writer
+
+	// BufferSize, if positive, buffers output in memory and writes it in
+	// chunks of up to this many bytes instead of one write per record.
+	// Call Handler.Flush or Handler.Close to make sure buffered records
+	// reach the writer, e.g. before the program exits.
+	BufferSize int
+
+	// Sync controls when output is synced to stable storage with the
+	// writer's Sync method, e.g. fsync for an *os.File.
+	// Default: SyncOnClose
+	Sync SyncPolicy
+
+	// LockFile takes an advisory exclusive lock (flock) on the output
+	// file around every write, so several processes appending to the same
+	// file never interleave their lines. It applies to writers with an Fd
+	// method, such as *os.File, and to a RotatingWriter, which locks the
+	// file it currently writes to, on platforms that support flock.
+	LockFile bool
+
+	// FlushInterval is how often buffered output is flushed in the
+	// background. It only applies when BufferSize is set.
+	// Default: 1s
+	FlushInterval time.Duration
+
 	// TimeFormat is the format used for timestamps.
+	// Set it to TimeFormatRelative to print the time elapsed since the
+	// handler was created (e.g. "+00:03.218") instead of the wall clock.
+	// Use TimeFormatMillis or TimeFormatMicros to keep bursts of records
+	// within the same second distinguishable.
 	// Default: "15:04:05" (hour:minute:second)
 	TimeFormat string
 
+	// AttrTimeFormat is the format used for time-valued attributes.
+	// Default: time.RFC3339
+	AttrTimeFormat string
+
+	// ElideRepeatedTime blanks the time column when a record has the same
+	// formatted timestamp as the line written before it, visually grouping
+	// bursts.
+	ElideRepeatedTime bool
+
+	// RepeatedTimeMarker is printed in place of an elided timestamp,
+	// e.g. "″". If empty, the time column is left blank.
+	RepeatedTimeMarker string
+
+	// TimeLocation is the time zone used to render timestamps and
+	// time-valued attributes, regardless of the host's local zone.
+	// If nil, each time is rendered in its own location.
+	TimeLocation *time.Location
+
+	// UseUTC renders all times in UTC. It takes precedence over TimeLocation.
+	UseUTC bool
+
+	// TimeDelta adds a column with the time elapsed since the previous
+	// record emitted to the same writer (e.g. "Δ12ms"), making slow steps
+	// easy to spot without explicit duration attributes.
+	// Default: DeltaNone
+	TimeDelta DeltaMode
+
+	// LevelStyler customizes level names and colors in both human-readable
+	// and JSON output. If nil, DefaultLevelStyler is used for human-readable
+	// output and JSON output keeps slog's standard level names.
+	LevelStyler LevelStyler
+
 	// DisableColor disables colored output for log levels.
 	// When true, no ANSI color codes will be used.
 	DisableColor bool
 
+	// DisableHTTPColor disables the automatic coloring of HTTP attributes:
+	// status and status_code values by class (2xx green, 3xx cyan, 4xx
+	// yellow, 5xx red) and method values by verb. Highlights take
+	// precedence over these colors.
+	DisableHTTPColor bool
+
 	// AddSource causes the handler to compute the source code position
 	// of the log statement and add a "source" attribute to the output.
 	AddSource bool
@@ -31,20 +156,340 @@ type Options struct {
 	// Default: 40 characters
 	MessageWidth int
 
+	// ControlChars selects how control characters in messages, keys and
+	// unquoted values are rendered, protecting the output against log
+	// injection. Quoted values always escape them.
+	// Default: ControlEscape
+	ControlChars ControlMode
+
+	// ANSI selects how escape sequences embedded in messages and values
+	// are rendered: visibly escaped, stripped or passed through to the
+	// terminal.
+	// Default: ANSIEscape
+	ANSI ANSIMode
+
+	// GroupStyle selects how groups are rendered: flattened into dotted
+	// keys, inline in braces, or nested on indented lines below the
+	// message.
+	// Default: GroupDotted
+	GroupStyle GroupStyle
+
+	// Prefix is the logger name of the handler, e.g. the application or
+	// module name. Handler.WithName and Named append dotted segments to it.
+	// A named handler renders the name in a colored column after the level.
+	Prefix string
+
+	// PrefixWidth is the width of the logger name column. Longer names are
+	// truncated from the start with "...".
+	// Default: 16
+	PrefixWidth int
+
+	// MaxLineWidth, if positive, wraps attributes that would extend a line
+	// beyond this many characters onto continuation lines indented to the
+	// attribute column, instead of leaving the terminal to soft-wrap them.
+	// It applies to the standard line layout.
+	MaxLineWidth int
+
+	// MaxRecordBytes, if positive, limits the size of a formatted record.
+	// Longer lines are cut and end with a marker giving the bytes cut and
+	// the attribute count; JSON records keep the members that fit and
+	// report the rest under TruncatedKey, so they remain valid JSON.
+	MaxRecordBytes int
+
+	// FloatPrecision is the number of digits printed after the decimal point
+	// for float attributes. Zero keeps the shortest exact representation.
+	FloatPrecision int
+
+	// ThousandsSeparator groups the digits of numeric attributes,
+	// e.g. "_" renders bytes=1_234_567 and "," renders bytes=1,234,567.
+	// Default: "" (no grouping)
+	ThousandsSeparator string
+
+	// BytesFormat controls how []byte attributes are rendered:
+	// hexadecimal, base64 or a "<N bytes>" summary.
+	// Default: BytesHex
+	BytesFormat BytesFormat
+
+	// MaxBytes limits how many bytes of a []byte attribute are rendered
+	// before it is truncated with "...<N bytes>".
+	// Default: 64
+	MaxBytes int
+
+	// AnyFormat renders struct, map and slice attribute values as compact
+	// JSON or Go-like syntax instead of fmt's %v output.
+	// Default: AnyDefault
+	AnyFormat AnyFormat
+
+	// AnyMaxDepth limits how deeply nested values are rendered when
+	// AnyFormat is set. Deeper values are shown as "...".
+	// Default: 5
+	AnyMaxDepth int
+
+	// AnyMaxElements limits how many fields, map entries or slice elements
+	// are rendered per value when AnyFormat is set.
+	// Default: 20
+	AnyMaxElements int
+
+	// TableValues renders attribute values that are slices of structs or
+	// maps, such as batch results, as aligned tables on indented lines
+	// below the record instead of inline. At most AnyMaxElements rows are
+	// shown.
+	TableValues bool
+
+	// Formatters registers key-based value formatters, e.g. to render
+	// "latency_ns" as a duration or "bytes" as KiB/MiB. They take
+	// precedence over all type-based formatting options.
+	Formatters *FormatterRegistry
+
+	// Highlights styles attribute values matching declarative rules, e.g.
+	// latencies over 200ms in yellow and over 1s in red. The first matching
+	// rule applies, so list the most specific rules first. Highlights are
+	// not shown when color is disabled.
+	Highlights []HighlightRule
+
+	// TimeStyle styles the timestamp, e.g. StyleDim so it recedes behind
+	// the message. It is not applied when color is disabled.
+	TimeStyle string
+
+	// KeyStyle styles attribute keys, including their group prefix and
+	// the "=", e.g. StyleDim to set values off. It is not applied when
+	// color is disabled.
+	KeyStyle string
+
+	// StringStyle and NumberStyle style string and numeric attribute
+	// values, e.g. StyleGreen and StyleCyan, so the two stand apart.
+	// Highlight rules and HTTP semantics take precedence. They are not
+	// applied when color is disabled.
+	StringStyle string
+	NumberStyle string
+
+	// TintMessage styles the message like its level, as returned by
+	// LevelStyler.LevelColor. It is not applied when color is disabled.
+	TintMessage bool
+
+	// HighlightPatterns styles every match of the patterns anywhere in
+	// the rendered text line, including the time and delta columns and the
+	// line number, e.g. a request ID or keyword being watched. Patterns are
+	// not applied in JSON mode or when color is disabled.
+	HighlightPatterns []HighlightPattern
+
+	// Now returns the current time. It stamps records that have no time
+	// and is the reference for TimeFormatRelative and TimeDelta, so tests
+	// and simulations can run on virtual time.
+	// Default: time.Now
+	Now func() time.Time
+
+	// AddSequence stamps every record with a sequence number under
+	// SequenceKey, counting from 1 per output, which reveals dropped or
+	// reordered lines after logs are shipped over lossy transports.
+	// Numbers are assigned when a record is handled, so records logged
+	// concurrently may be written slightly out of order.
+	AddSequence bool
+
+	// SummaryInterval, if positive, writes a dim line counting the records
+	// of each level since the previous one every interval, e.g.
+	// "— 120 info, 3 warn, 1 error in last 30s —", as a heartbeat when
+	// watching a live console. Call Handler.Close to stop it. Summary
+	// lines are not written in JSON mode.
+	SummaryInterval time.Duration
+
+	// SummaryEvery, if positive, writes the summary line after every
+	// SummaryEvery records, in addition to any SummaryInterval.
+	SummaryEvery int
+
+	// IdleSeparator, if positive, writes a dim marker such as
+	// "— 42s idle —" before a record that follows a pause longer than
+	// IdleSeparator, segmenting the sessions of interactive debugging.
+	// Markers are not written in JSON mode.
+	IdleSeparator time.Duration
+
+	// LineNumbers prefixes every record with its number, counting from 1
+	// per output in the order records are written, so a line of a shared
+	// console capture can be referred to as "see line 3121". Unlike
+	// AddSequence it is part of the layout rather than an attribute, and
+	// does not apply to JSON output.
+	LineNumbers bool
+
+	// Deterministic makes output reproducible for golden-file tests: every
+	// record is stamped with DeterministicTime, attributes are sorted by
+	// key, color is disabled, times are rendered in UTC and source
+	// locations are reduced to the file name. JSON output gets the fixed
+	// time and file-only source, but keeps attribute order.
+	Deterministic bool
+
+	// Metrics collects counters about handled records and writes. Share
+	// one Metrics between handlers, AsyncHandler and RateLimitHandler to
+	// aggregate them. Default: a new Metrics per handler, see Handler.Stats
+	Metrics *Metrics
+
+	// Columns, if set, renders records as a table of ordered columns,
+	// each with its own width, alignment and truncation policy.
+	// MessageWidth, TimeDelta and ElideRepeatedTime do not apply to
+	// column layouts. See DefaultColumns for a starting point.
+	Columns []Column
+
+	// LineFormatter, if set, renders each record instead of the built-in
+	// layout. It is ignored when UseJSON is enabled.
+	LineFormatter LineFormatter
+
 	// UseJSON enables JSON output format instead of human-readable format.
 	// When true, the handler will delegate to slog.JSONHandler for structured output.
 	// Useful for production environments where log aggregation systems expect JSON.
 	UseJSON bool
+
+	// JSONFormat selects the JSON field layout, e.g. JSONGoogleCloud for
+	// Google Cloud Logging. It only applies when UseJSON is enabled.
+	// Default: JSONStandard
+	JSONFormat JSONFormat
+
+	// GCPProjectID is the Google Cloud project used to qualify trace IDs as
+	// "projects/<id>/traces/<trace_id>" in the JSONGoogleCloud format.
+	GCPProjectID string
+
+	// SpanContext, if set, extracts the active trace span from the context
+	// passed to Handle and adds its trace ID, span ID and sampled flag to
+	// the record. Use ContextSpanContext for IDs stored with WithTraceID,
+	// or bridge OpenTelemetry as shown on SpanContextFunc.
+	SpanContext SpanContextFunc
+
+	// OnRecord hooks into the handling of every enabled record: to
+	// change, enrich or veto it before formatting, and to observe the
+	// outcome after writing.
+	OnRecord RecordHooks
+
+	// AddProcessInfo attaches the hostname and process ID to every record,
+	// along with Service, Version and Environment if they are set.
+	AddProcessInfo bool
+
+	// AddBuildInfo attaches the short VCS revision, commit time and Go
+	// version of the binary to every record, so each log stream identifies
+	// the build that produced it.
+	AddBuildInfo bool
+
+	// Service is the name of the service producing the logs.
+	Service string
+
+	// Environment is the deployment environment, e.g. "production".
+	Environment string
+
+	// Version is the version of the service producing the logs.
+	Version string
 }
 
 // DefaultOptions returns a new Options with default values.
 func DefaultOptions() *Options {
 	return &Options{
 		Level:        slog.LevelInfo,
-		TimeFormat:   "15:04:05",
+		TimeFormat:   TimeFormatSeconds,
 		DisableColor: false,
 		AddSource:    true,
 		MessageWidth: 40,
 		UseJSON:      false,
 	}
 }
+
+// style returns s, or "" if color is disabled.
+func (o *Options) style(s string) string {
+	if o.DisableColor {
+		return ""
+	}
+	return s
+}
+
+// inlineAttrs reports whether attributes are appended to the line one by
+// one as they are formatted, which is the common layout. Otherwise every
+// attribute is formatted up front to sort, wrap, bracket or tabulate them.
+func (o *Options) inlineAttrs() bool {
+	return !o.Deterministic && o.MaxLineWidth <= 0 && !o.TableValues && o.GroupStyle == GroupDotted
+}
+
+// validate returns an error describing every invalid or conflicting
+// setting in o.
+func (o *Options) validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("humanlog: "+format, args...))
+	}
+
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"MessageWidth", o.MessageWidth},
+		{"PrefixWidth", o.PrefixWidth},
+		{"MaxLineWidth", o.MaxLineWidth},
+		{"MaxRecordBytes", o.MaxRecordBytes},
+		{"BufferSize", o.BufferSize},
+		{"SummaryEvery", o.SummaryEvery},
+		{"FloatPrecision", o.FloatPrecision},
+		{"AnyMaxDepth", o.AnyMaxDepth},
+		{"AnyMaxElements", o.AnyMaxElements},
+	} {
+		if n.value < 0 {
+			invalid("%s is negative: %d", n.name, n.value)
+		}
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"FlushInterval", o.FlushInterval},
+		{"SummaryInterval", o.SummaryInterval},
+		{"IdleSeparator", o.IdleSeparator},
+	} {
+		if d.value < 0 {
+			invalid("%s is negative: %v", d.name, d.value)
+		}
+	}
+
+	for _, e := range []struct {
+		name  string
+		value int
+		max   int
+	}{
+		{"TimeDelta", int(o.TimeDelta), int(DeltaReplace)},
+		{"ControlChars", int(o.ControlChars), int(ControlKeep)},
+		{"ANSI", int(o.ANSI), int(ANSIPassThrough)},
+		{"GroupStyle", int(o.GroupStyle), int(GroupNested)},
+		{"AnyFormat", int(o.AnyFormat), int(AnyGoSyntax)},
+		{"Sync", int(o.Sync), int(SyncNever)},
+	} {
+		if e.value < 0 || e.value > e.max {
+			invalid("unknown %s value %d", e.name, e.value)
+		}
+	}
+
+	// A layout without any time elements renders as itself, e.g. a
+	// misspelled preset name
+	for _, f := range []struct{ name, layout string }{
+		{"TimeFormat", o.TimeFormat},
+		{"AttrTimeFormat", o.AttrTimeFormat},
+	} {
+		if f.layout != "" && f.layout != TimeFormatRelative && DeterministicTime.Format(f.layout) == f.layout {
+			invalid("%s %q is not a time layout", f.name, f.layout)
+		}
+	}
+	if o.AttrTimeFormat == TimeFormatRelative {
+		invalid("AttrTimeFormat cannot be %q", TimeFormatRelative)
+	}
+
+	if o.LineFormatter != nil && len(o.Columns) > 0 {
+		invalid("LineFormatter and Columns are both set; Columns would be ignored")
+	}
+	if o.UseJSON && (o.LineFormatter != nil || len(o.Columns) > 0) {
+		invalid("LineFormatter and Columns do not apply to JSON output")
+	}
+	for i, p := range o.HighlightPatterns {
+		if p.Regexp == nil {
+			invalid("HighlightPatterns[%d] has no Regexp", i)
+		}
+	}
+	for i, rule := range o.Highlights {
+		if rule.Op < CompareAlways || rule.Op > CompareLessEqual {
+			invalid("Highlights[%d] has unknown comparison %d", i, rule.Op)
+		} else if rule.Op != CompareAlways && rule.Threshold == nil {
+			invalid("Highlights[%d] for key %q has no threshold", i, rule.Key)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,434 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for OTLPOptions.
+const (
+	defaultOTLPEndpoint      = "http://localhost:4318/v1/logs"
+	defaultOTLPBatchSize     = 100
+	defaultOTLPMaxQueue      = 10000
+	defaultOTLPFlushInterval = 5 * time.Second
+	defaultOTLPScopeName     = "github.com/lepinkainen/humanlog"
+)
+
+// OTLPOptions configures an OTLPExporter.
+type OTLPOptions struct {
+	// Endpoint is the OTLP/HTTP logs endpoint.
+	// Default: "http://localhost:4318/v1/logs"
+	Endpoint string
+
+	// Headers are added to every export request, e.g. for authentication.
+	Headers map[string]string
+
+	// Resource describes the entity producing the logs,
+	// e.g. slog.String("service.name", "checkout").
+	Resource []slog.Attr
+
+	// ScopeName is the instrumentation scope reported with every record.
+	// Default: "github.com/lepinkainen/humanlog"
+	ScopeName string
+
+	// Level is the minimum level exported.
+	Level slog.Leveler
+
+	// BatchSize is the number of records that triggers an export.
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is the maximum time a record waits before export.
+	// Default: 5s
+	FlushInterval time.Duration
+
+	// MaxQueue is the number of records queued while an export is slow or
+	// the collector is unreachable. Further records are dropped and
+	// counted.
+	// Default: 10000
+	MaxQueue int
+
+	// Metrics, if set, counts dropped records, see Stats.Dropped.
+	Metrics *Metrics
+
+	// SpanContext extracts the trace and span IDs attached to records,
+	// like Options.SpanContext.
+	// Default: ContextSpanContext, the IDs stored with WithTraceID and
+	// WithSpanID
+	SpanContext SpanContextFunc
+
+	// Client is the HTTP client used for export requests.
+	// Default: http.DefaultClient
+	Client *http.Client
+
+	// OnError is called with export errors, which would otherwise be lost
+	// because exports happen in the background.
+	OnError func(error)
+}
+
+// OTLPExporter is a slog.Handler that converts records into OpenTelemetry
+// log records and exports them in batches over OTLP/HTTP using the JSON
+// encoding. The trace and span IDs of the context, see
+// OTLPOptions.SpanContext, are attached to each record.
+//
+// Use it next to a Handler, e.g. with a Router or Tee, so humanlog serves as
+// both the pretty console handler and the structured export path. Call Close
+// before the program exits to export any buffered records.
+type OTLPExporter struct {
+	core   *otlpCore
+	attrs  []otlpKeyValue
+	groups []string
+}
+
+// otlpCore is the batching state shared by an exporter and every exporter
+// derived from it.
+type otlpCore struct {
+	opts     OTLPOptions
+	resource []otlpKeyValue
+
+	mu      sync.Mutex
+	pending []otlpLogRecord
+
+	flushCh chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewOTLPExporter creates an exporter and starts its background flusher.
+func NewOTLPExporter(opts OTLPOptions) *OTLPExporter {
+	if opts.Endpoint == "" {
+		opts.Endpoint = defaultOTLPEndpoint
+	}
+	if opts.ScopeName == "" {
+		opts.ScopeName = defaultOTLPScopeName
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultOTLPBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultOTLPFlushInterval
+	}
+	if opts.MaxQueue <= 0 {
+		opts.MaxQueue = defaultOTLPMaxQueue
+	}
+	if opts.SpanContext == nil {
+		opts.SpanContext = ContextSpanContext
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	core := &otlpCore{
+		opts:     opts,
+		resource: otlpAttrs(nil, "", opts.Resource),
+		flushCh:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go core.run()
+
+	return &OTLPExporter{core: core}
+}
+
+// Enabled reports whether records at the given level are exported.
+func (e *OTLPExporter) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= e.core.opts.Level.Level()
+}
+
+// Handle converts the record and queues it for export.
+func (e *OTLPExporter) Handle(ctx context.Context, r slog.Record) error {
+	prefix := strings.Join(e.groups, ".")
+
+	rec := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(r.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverityNumber(r.Level),
+		SeverityText:         r.Level.String(),
+		Body:                 otlpAnyValue{StringValue: &r.Message},
+		Attributes:           append([]otlpKeyValue(nil), e.attrs...),
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attributes = otlpAttrs(rec.Attributes, prefix, []slog.Attr{a})
+		return true
+	})
+
+	if sc, ok := e.core.opts.SpanContext(ctx); ok {
+		if isHexID(sc.TraceID, 16) {
+			rec.TraceID = strings.ToLower(sc.TraceID)
+		}
+		if isHexID(sc.SpanID, 8) {
+			rec.SpanID = strings.ToLower(sc.SpanID)
+		}
+	}
+
+	return e.core.enqueue(rec)
+}
+
+// WithAttrs returns a new exporter whose records include the given attributes.
+func (e *OTLPExporter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &OTLPExporter{
+		core:   e.core,
+		attrs:  otlpAttrs(append([]otlpKeyValue(nil), e.attrs...), strings.Join(e.groups, "."), attrs),
+		groups: e.groups,
+	}
+}
+
+// WithGroup returns a new exporter that qualifies attribute keys with name.
+func (e *OTLPExporter) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return e
+	}
+	return &OTLPExporter{
+		core:   e.core,
+		attrs:  e.attrs,
+		groups: append(append([]string{}, e.groups...), name),
+	}
+}
+
+// Flush exports all queued records immediately.
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	return e.core.flush(ctx)
+}
+
+// Close stops the background flusher and exports any queued records.
+// Records handled after Close are dropped.
+func (e *OTLPExporter) Close() error {
+	var err error
+	e.core.once.Do(func() {
+		close(e.core.done)
+		<-e.core.stopped
+		err = e.core.flush(context.Background())
+	})
+	return err
+}
+
+var errOTLPClosed = errors.New("humanlog: OTLP exporter is closed")
+
+func (c *otlpCore) enqueue(rec otlpLogRecord) error {
+	select {
+	case <-c.done:
+		return errOTLPClosed
+	default:
+	}
+
+	c.mu.Lock()
+	if len(c.pending) >= c.opts.MaxQueue {
+		c.mu.Unlock()
+		c.opts.Metrics.countDropped()
+		return nil
+	}
+	c.pending = append(c.pending, rec)
+	full := len(c.pending) >= c.opts.BatchSize
+	c.mu.Unlock()
+
+	if full {
+		select {
+		case c.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run exports batches whenever the batch fills up or the interval elapses.
+func (c *otlpCore) run() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		case <-c.flushCh:
+		}
+		if err := c.flush(context.Background()); err != nil && c.opts.OnError != nil {
+			c.opts.OnError(err)
+		}
+	}
+}
+
+func (c *otlpCore) flush(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return c.export(ctx, batch)
+}
+
+// export sends one OTLP ExportLogsServiceRequest.
+func (c *otlpCore) export(ctx context.Context, batch []otlpLogRecord) error {
+	body, err := json.Marshal(otlpExportRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: c.resource},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: c.opts.ScopeName},
+				LogRecords: batch,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("humanlog: encode OTLP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("humanlog: create OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("humanlog: export %d OTLP records: %w", len(batch), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("humanlog: export %d OTLP records: unexpected status %s", len(batch), resp.Status)
+	}
+	return nil
+}
+
+// otlpSeverityNumber maps a slog level onto the OpenTelemetry severity
+// range, where slog's Info (0) corresponds to INFO (9).
+func otlpSeverityNumber(level slog.Level) int {
+	n := int(level) + 9
+	if n < 1 {
+		return 1
+	}
+	if n > 24 {
+		return 24
+	}
+	return n
+}
+
+// isHexID reports whether s is the hex encoding of an n-byte, non-zero ID.
+func isHexID(s string, n int) bool {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != n {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// otlpAttrs converts slog attributes to OTLP key-values, flattening groups
+// into dotted keys qualified by prefix.
+func otlpAttrs(dst []otlpKeyValue, prefix string, attrs []slog.Attr) []otlpKeyValue {
+	var flat []slog.Attr
+	for _, a := range attrs {
+		flat = flattenAttrs(flat, prefix, a)
+	}
+	for _, a := range flat {
+		dst = append(dst, otlpKeyValue{Key: a.Key, Value: otlpValue(a.Value)})
+	}
+	return dst
+}
+
+// otlpValue converts a resolved slog value to an OTLP AnyValue.
+func otlpValue(v slog.Value) otlpAnyValue {
+	switch v.Kind() {
+	case slog.KindBool:
+		b := v.Bool()
+		return otlpAnyValue{BoolValue: &b}
+	case slog.KindInt64:
+		s := strconv.FormatInt(v.Int64(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindUint64:
+		// OTLP integers are signed; larger values would wrap
+		s := strconv.FormatUint(v.Uint64(), 10)
+		if v.Uint64() > math.MaxInt64 {
+			return otlpAnyValue{StringValue: &s}
+		}
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindFloat64:
+		// JSON cannot represent NaN and infinities, and one would fail
+		// the whole batch
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			return otlpAnyValue{StringValue: &s}
+		}
+		return otlpAnyValue{DoubleValue: &f}
+	case slog.KindTime:
+		s := v.Time().Format(time.RFC3339Nano)
+		return otlpAnyValue{StringValue: &s}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			s := err.Error()
+			return otlpAnyValue{StringValue: &s}
+		}
+	}
+	s := v.String()
+	return otlpAnyValue{StringValue: &s}
+}
+
+// OTLP/JSON wire types, following the protobuf JSON mapping of
+// opentelemetry.proto.collector.logs.v1.ExportLogsServiceRequest.
+type (
+	otlpExportRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano         string         `json:"timeUnixNano"`
+		ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+		SeverityNumber       int            `json:"severityNumber"`
+		SeverityText         string         `json:"severityText"`
+		Body                 otlpAnyValue   `json:"body"`
+		Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+		TraceID              string         `json:"traceId,omitempty"`
+		SpanID               string         `json:"spanId,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
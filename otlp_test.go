@@ -0,0 +1,167 @@
+package humanlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter_ExportsRecords(t *testing.T) {
+	requests := make(chan otlpExportRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization header = %q, want Bearer token", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req otlpExportRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid OTLP JSON %s: %v", body, err)
+		}
+		requests <- req
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(OTLPOptions{
+		Endpoint: srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Resource: []slog.Attr{slog.String("service.name", "checkout")},
+	})
+	logger := slog.New(exporter).WithGroup("order")
+
+	ctx := WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = WithSpanID(ctx, "00f067aa0ba902b7")
+	logger.WarnContext(ctx, "Payment retried", slog.Int("attempt", 2))
+
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	req := <-requests
+	if len(req.ResourceLogs) != 1 || len(req.ResourceLogs[0].ScopeLogs) != 1 {
+		t.Fatalf("unexpected request shape: %+v", req)
+	}
+	if attrs := req.ResourceLogs[0].Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "service.name" {
+		t.Errorf("resource attributes = %+v, want service.name", attrs)
+	}
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	rec := records[0]
+	if rec.SeverityNumber != 13 || rec.SeverityText != "WARN" {
+		t.Errorf("severity = %d %s, want 13 WARN", rec.SeverityNumber, rec.SeverityText)
+	}
+	if rec.Body.StringValue == nil || *rec.Body.StringValue != "Payment retried" {
+		t.Errorf("body = %+v, want Payment retried", rec.Body)
+	}
+	if rec.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rec.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("trace context = %s/%s, want IDs from context", rec.TraceID, rec.SpanID)
+	}
+	if len(rec.Attributes) != 1 || rec.Attributes[0].Key != "order.attempt" || *rec.Attributes[0].Value.IntValue != "2" {
+		t.Errorf("attributes = %+v, want order.attempt=2", rec.Attributes)
+	}
+}
+
+func TestOTLPExporter_DropsAfterClose(t *testing.T) {
+	exporter := NewOTLPExporter(OTLPOptions{Endpoint: "http://127.0.0.1:0"})
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)
+	if err := exporter.Handle(context.Background(), r); err == nil {
+		t.Error("Handle() after Close should return an error")
+	}
+}
+
+func TestOTLPExporter_MaxQueue(t *testing.T) {
+	metrics := NewMetrics()
+	exporter := NewOTLPExporter(OTLPOptions{
+		Endpoint:      "http://127.0.0.1:0",
+		FlushInterval: time.Hour,
+		MaxQueue:      5,
+		Metrics:       metrics,
+	})
+	defer func() { _ = exporter.Close() }()
+
+	logger := slog.New(exporter)
+	for range 8 {
+		logger.Info("Queued")
+	}
+	if got := metrics.Stats().Dropped; got != 3 {
+		t.Errorf("Dropped = %d, want the 3 records beyond MaxQueue", got)
+	}
+}
+
+func TestOTLPExporter_SpanContext(t *testing.T) {
+	requests := make(chan otlpExportRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpExportRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests <- req
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(OTLPOptions{
+		Endpoint: srv.URL,
+		SpanContext: func(ctx context.Context) (SpanContext, bool) {
+			return SpanContext{TraceID: "4BF92F3577B34DA6A3CE929D0E0E4736", SpanID: "00f067aa0ba902b7"}, true
+		},
+	})
+	slog.New(exporter).Info("Traced")
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rec := (<-requests).ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if rec.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rec.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("trace context = %s/%s, want the IDs from SpanContext", rec.TraceID, rec.SpanID)
+	}
+}
+
+func TestOTLPExporter_NonFiniteAndLargeValues(t *testing.T) {
+	requests := make(chan otlpExportRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req otlpExportRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid OTLP JSON %s: %v", body, err)
+		}
+		requests <- req
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(OTLPOptions{Endpoint: srv.URL})
+	logger := slog.New(exporter)
+	logger.Info("Ratio", "value", math.NaN(), "limit", math.Inf(1))
+	logger.Info("Counter", "n", uint64(math.MaxUint64))
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var req otlpExportRequest
+	select {
+	case req = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no export request; the batch was dropped")
+	}
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want both", len(records))
+	}
+	want := map[string]string{"value": "NaN", "limit": "+Inf", "n": "18446744073709551615"}
+	for _, rec := range records {
+		for _, kv := range rec.Attributes {
+			if kv.Value.StringValue == nil || *kv.Value.StringValue != want[kv.Key] {
+				t.Errorf("attribute %s = %+v, want stringValue %q", kv.Key, kv.Value, want[kv.Key])
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package humanlog
+
+import (
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// Process metadata attribute keys used by Options.AddProcessInfo.
+const (
+	HostKey        = "host"
+	PIDKey         = "pid"
+	ServiceKey     = "service"
+	VersionKey     = "version"
+	EnvironmentKey = "env"
+)
+
+// processInfoAttrs returns the attributes Options.AddProcessInfo attaches
+// to every record. They are computed once, when the handler is created.
+func processInfoAttrs(opts *Options) []slog.Attr {
+	if !opts.AddProcessInfo {
+		return nil
+	}
+
+	var attrs []slog.Attr
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String(HostKey, host))
+	}
+	attrs = append(attrs, slog.Int(PIDKey, os.Getpid()))
+
+	// The Datadog profile already tags records with these
+	if opts.UseJSON && opts.JSONFormat == JSONDatadog {
+		return attrs
+	}
+	if opts.Service != "" {
+		attrs = append(attrs, slog.String(ServiceKey, opts.Service))
+	}
+	if opts.Version != "" {
+		attrs = append(attrs, slog.String(VersionKey, opts.Version))
+	}
+	if opts.Environment != "" {
+		attrs = append(attrs, slog.String(EnvironmentKey, opts.Environment))
+	}
+	return attrs
+}
+
+// Build info attribute keys used by Options.AddBuildInfo.
+const (
+	RevisionKey  = "vcs.revision"
+	VCSTimeKey   = "vcs.time"
+	GoVersionKey = "go"
+)
+
+// shortRevisionLen is the number of revision characters kept.
+const shortRevisionLen = 12
+
+// buildInfo reads the binary's build information once.
+var buildInfo = sync.OnceValue(func() []slog.Attr {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	var attrs []slog.Attr
+	var revision, vcsTime string
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision != "" {
+		if len(revision) > shortRevisionLen {
+			revision = revision[:shortRevisionLen]
+		}
+		if modified {
+			revision += "-dirty"
+		}
+		attrs = append(attrs, slog.String(RevisionKey, revision))
+	}
+	if vcsTime != "" {
+		attrs = append(attrs, slog.String(VCSTimeKey, vcsTime))
+	}
+	return append(attrs, slog.String(GoVersionKey, info.GoVersion))
+})
+
+// buildInfoAttrs returns the attributes Options.AddBuildInfo attaches to
+// every record. The revision is only known for binaries built from a
+// version control checkout.
+func buildInfoAttrs(opts *Options) []slog.Attr {
+	if !opts.AddBuildInfo {
+		return nil
+	}
+	return buildInfo()
+}
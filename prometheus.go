@@ -0,0 +1,85 @@
+package humanlog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// prometheusLevels is the order in which level counters are exposed.
+var prometheusLevels = []string{"debug", "info", "warn", "error"}
+
+// WritePrometheus writes the counters in the Prometheus text exposition
+// format:
+//
+//	log_records_total{level="error"} 3
+//	log_write_errors_total 0
+//	log_dropped_total 12
+//	log_bytes_written_total 4096
+//	log_format_duration_seconds_bucket{le="1e-06"} 10
+//
+// The module has no dependencies, so rather than a prometheus.Collector it
+// provides this writer and PrometheusHandler, which Prometheus can scrape
+// directly alongside the client library's own endpoint.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	s := m.Stats()
+	ew := &errWriter{w: w}
+
+	ew.printf("# HELP log_records_total Log records handled, by level.\n")
+	ew.printf("# TYPE log_records_total counter\n")
+	for _, level := range prometheusLevels {
+		ew.printf("log_records_total{level=%q} %d\n", level, s.Records[level])
+	}
+
+	ew.printf("# HELP log_write_errors_total Failed writes to the log output.\n")
+	ew.printf("# TYPE log_write_errors_total counter\n")
+	ew.printf("log_write_errors_total %d\n", s.WriteErrors)
+
+	ew.printf("# HELP log_dropped_total Log records dropped by rate limiting or full queues.\n")
+	ew.printf("# TYPE log_dropped_total counter\n")
+	ew.printf("log_dropped_total %d\n", s.Dropped)
+
+	ew.printf("# HELP log_bytes_written_total Bytes written to the log output.\n")
+	ew.printf("# TYPE log_bytes_written_total counter\n")
+	ew.printf("log_bytes_written_total %d\n", s.BytesWritten)
+
+	ew.printf("# HELP log_format_duration_seconds Time spent formatting log records.\n")
+	ew.printf("# TYPE log_format_duration_seconds histogram\n")
+	var cumulative uint64
+	for _, b := range s.FormatDuration {
+		cumulative += b.Count
+		le := "+Inf"
+		if b.UpperBound > 0 {
+			le = strconv.FormatFloat(b.UpperBound.Seconds(), 'g', -1, 64)
+		}
+		ew.printf("log_format_duration_seconds_bucket{le=%q} %d\n", le, cumulative)
+	}
+	ew.printf("log_format_duration_seconds_sum %s\n", strconv.FormatFloat(s.FormatDurationSum.Seconds(), 'g', -1, 64))
+	ew.printf("log_format_duration_seconds_count %d\n", cumulative)
+
+	return ew.err
+}
+
+// PrometheusHandler returns an http.Handler serving m in the Prometheus
+// text exposition format, e.g. mounted at /metrics/log.
+func (m *Metrics) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w)
+	})
+}
+
+// errWriter remembers the first write error so a sequence of writes can be
+// checked once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
@@ -0,0 +1,154 @@
+package humanlog
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// W3C Trace Context and Baggage header names.
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+	BaggageHeader     = "baggage"
+)
+
+// TraceParent is a parsed W3C traceparent header.
+type TraceParent struct {
+	// TraceID is the lowercase hex trace ID.
+	TraceID string
+	// ParentID is the lowercase hex ID of the caller's span.
+	ParentID string
+	// Flags holds the trace flags; bit 0 is the sampled flag.
+	Flags byte
+}
+
+// ParseTraceParent parses a traceparent header value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceParent(s string) (TraceParent, error) {
+	invalid := fmt.Errorf("humanlog: invalid traceparent %q", s)
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[3]) != 2 {
+		return TraceParent{}, invalid
+	}
+	// Version ff is forbidden, and version 00 has exactly four fields
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return TraceParent{}, invalid
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || !isHexID(parts[1], 16) || !isHexID(parts[2], 8) {
+		return TraceParent{}, invalid
+	}
+	return TraceParent{TraceID: strings.ToLower(parts[1]), ParentID: strings.ToLower(parts[2]), Flags: flags[0]}, nil
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&1 == 1
+}
+
+// String formats tp as a version 00 traceparent header value.
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tp.TraceID, tp.ParentID, tp.Flags)
+}
+
+// ParseBaggage parses a baggage header value into its key/value pairs,
+// discarding member properties and malformed members.
+func ParseBaggage(s string) map[string]string {
+	baggage := map[string]string{}
+	for _, member := range strings.Split(s, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if v, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			baggage[key] = v
+		}
+	}
+	return baggage
+}
+
+type (
+	traceParentKey struct{}
+	traceStateKey  struct{}
+	baggageKey     struct{}
+)
+
+// TraceParentFromContext returns the traceparent stored by
+// TraceContextMiddleware, if any.
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	tp, ok := ctx.Value(traceParentKey{}).(TraceParent)
+	return tp, ok
+}
+
+// TraceStateFromContext returns the raw tracestate header stored by
+// TraceContextMiddleware.
+func TraceStateFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(traceStateKey{}).(string)
+	return s
+}
+
+// BaggageFromContext returns the baggage stored by TraceContextMiddleware.
+// The map must not be modified.
+func BaggageFromContext(ctx context.Context) map[string]string {
+	b, _ := ctx.Value(baggageKey{}).(map[string]string)
+	return b
+}
+
+// TraceContextOptions configures TraceContextMiddleware.
+type TraceContextOptions struct {
+	// BaggageKeys lists the baggage entries added to log records as
+	// attributes with the same keys, e.g. "tenant" or "user_tier".
+	// Default: none
+	BaggageKeys []string
+}
+
+// TraceContextMiddleware returns HTTP middleware that parses the incoming
+// traceparent, tracestate and baggage headers and stores them in the
+// request context. The trace ID and the caller's span ID are also stored
+// with WithTraceID and WithSpanID, so ContextLogger, Options.SpanContext
+// with ContextSpanContext and the JSON trace fields pick them up without a
+// tracing SDK. Invalid traceparent headers are ignored.
+func TraceContextMiddleware(opts *TraceContextOptions) func(http.Handler) http.Handler {
+	var options TraceContextOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if tp, err := ParseTraceParent(r.Header.Get(TraceParentHeader)); err == nil {
+				ctx = context.WithValue(ctx, traceParentKey{}, tp)
+				ctx = WithSpanID(WithTraceID(ctx, tp.TraceID), tp.ParentID)
+				if state := r.Header.Get(TraceStateHeader); state != "" {
+					ctx = context.WithValue(ctx, traceStateKey{}, state)
+				}
+			}
+
+			if header := strings.Join(r.Header.Values(BaggageHeader), ","); header != "" {
+				baggage := ParseBaggage(header)
+				ctx = context.WithValue(ctx, baggageKey{}, baggage)
+
+				var attrs []slog.Attr
+				for _, key := range options.BaggageKeys {
+					if v, ok := baggage[key]; ok {
+						attrs = append(attrs, slog.String(key, v))
+					}
+				}
+				if len(attrs) > 0 {
+					ctx = WithContextAttrs(ctx, attrs...)
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, true},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-00", false, false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", true, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true, false},
+		{"garbage", true, false},
+	}
+	for _, tt := range tests {
+		tp, err := ParseTraceParent(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTraceParent(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && tp.Sampled() != tt.sampled {
+			t.Errorf("ParseTraceParent(%q).Sampled() = %v, want %v", tt.in, tp.Sampled(), tt.sampled)
+		}
+	}
+
+	tp, _ := ParseTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01")
+	if got := tp.String(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParseBaggage(t *testing.T) {
+	got := ParseBaggage("tenant=acme, user%20tier = gold%20plus;prop=1,broken,=x")
+	if len(got) != 2 || got["tenant"] != "acme" || got["user%20tier"] != "gold plus" {
+		t.Errorf("ParseBaggage() = %v", got)
+	}
+}
+
+func TestTraceContextMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelInfo, DisableColor: true, SpanContext: ContextSpanContext}))
+
+	var state string
+	var baggage map[string]string
+	handler := TraceContextMiddleware(&TraceContextOptions{BaggageKeys: []string{"tenant"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state = TraceStateFromContext(r.Context())
+		baggage = BaggageFromContext(r.Context())
+		logger.InfoContext(r.Context(), "Handling")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	req.Header.Set(TraceStateHeader, "vendor=abc")
+	req.Header.Set(BaggageHeader, "tenant=acme,secret=hidden")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	for _, want := range []string{"trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7", "trace_sampled=false", "tenant=acme"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q missing %q", out, want)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("unselected baggage key logged: %q", out)
+	}
+	if state != "vendor=abc" || baggage["secret"] != "hidden" {
+		t.Errorf("context tracestate = %q, baggage = %v", state, baggage)
+	}
+}
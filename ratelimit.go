@@ -0,0 +1,198 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SuppressedKey is the attribute added to the first record emitted after
+// the rate limiter dropped records. Its value is the number dropped.
+const SuppressedKey = "suppressed"
+
+// maxRateLimitKeys bounds the number of per-key buckets kept in memory.
+const maxRateLimitKeys = 10000
+
+// RateLimitOptions configures a RateLimitHandler. A zero rate disables the
+// corresponding bucket.
+type RateLimitOptions struct {
+	// Rate is the number of records per second allowed in total.
+	Rate float64
+
+	// Burst is the number of records allowed in total before Rate applies.
+	// Default: 1 if Rate is set
+	Burst int
+
+	// PerKeyRate is the number of records per second allowed per key.
+	PerKeyRate float64
+
+	// PerKeyBurst is the number of records allowed per key before
+	// PerKeyRate applies.
+	// Default: 1 if PerKeyRate is set
+	PerKeyBurst int
+
+	// Key returns the key a record is rate-limited under.
+	// Default: the record's message
+	Key func(r slog.Record) string
+
+	// Metrics, if set, counts suppressed records as dropped.
+	Metrics *Metrics
+}
+
+// RateLimitHandler is a slog.Handler that protects the process from log
+// storms with a global and a per-key token bucket. Dropped records are
+// counted, and the count is attached as SuppressedKey to the next record
+// that gets through.
+type RateLimitHandler struct {
+	h     slog.Handler
+	state *rateLimitState
+}
+
+// rateLimitState is shared by a RateLimitHandler and every handler derived
+// from it, so all loggers draw from the same buckets.
+type rateLimitState struct {
+	opts RateLimitOptions
+	now  func() time.Time
+
+	mu         sync.Mutex
+	global     tokenBucket
+	keys       map[string]*tokenBucket
+	suppressed uint64
+}
+
+// tokenBucket refills at rate tokens per second up to burst.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitHandler returns a handler that passes records to h within
+// the configured rates.
+func NewRateLimitHandler(h slog.Handler, opts RateLimitOptions) *RateLimitHandler {
+	if opts.Rate > 0 && opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+	if opts.PerKeyRate > 0 && opts.PerKeyBurst <= 0 {
+		opts.PerKeyBurst = 1
+	}
+	if opts.Key == nil {
+		opts.Key = func(r slog.Record) string { return r.Message }
+	}
+	return &RateLimitHandler{
+		h: h,
+		state: &rateLimitState{
+			opts:   opts,
+			now:    time.Now,
+			global: tokenBucket{tokens: float64(opts.Burst)},
+			keys:   make(map[string]*tokenBucket),
+		},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (rl *RateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return rl.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler if both buckets have a
+// token available, and drops it otherwise.
+func (rl *RateLimitHandler) Handle(ctx context.Context, r slog.Record) error {
+	suppressed, ok := rl.state.allow(r)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Uint64(SuppressedKey, suppressed))
+	}
+	return rl.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new RateLimitHandler sharing the buckets, whose
+// records include the given attributes.
+func (rl *RateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RateLimitHandler{h: rl.h.WithAttrs(attrs), state: rl.state}
+}
+
+// WithGroup returns a new RateLimitHandler sharing the buckets, whose
+// records use the given group.
+func (rl *RateLimitHandler) WithGroup(name string) slog.Handler {
+	return &RateLimitHandler{h: rl.h.WithGroup(name), state: rl.state}
+}
+
+// Suppressed returns the number of records dropped since the last record
+// that was let through.
+func (rl *RateLimitHandler) Suppressed() uint64 {
+	rl.state.mu.Lock()
+	defer rl.state.mu.Unlock()
+	return rl.state.suppressed
+}
+
+// allow reports whether r may be emitted and, if so, how many records were
+// suppressed before it.
+func (s *rateLimitState) allow(r slog.Record) (uint64, bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var key *tokenBucket
+	if s.opts.PerKeyRate > 0 {
+		k := s.opts.Key(r)
+		key = s.keys[k]
+		if key == nil {
+			if len(s.keys) >= maxRateLimitKeys {
+				s.pruneKeys(now)
+			}
+			key = &tokenBucket{tokens: float64(s.opts.PerKeyBurst), last: now}
+			s.keys[k] = key
+		}
+		key.refill(now, s.opts.PerKeyRate, s.opts.PerKeyBurst)
+	}
+	if s.opts.Rate > 0 {
+		s.global.refill(now, s.opts.Rate, s.opts.Burst)
+	}
+
+	// Take from both buckets only if both have a token, so a record dropped
+	// by one bucket does not consume the other's budget.
+	if (key != nil && key.tokens < 1) || (s.opts.Rate > 0 && s.global.tokens < 1) {
+		s.suppressed++
+		s.opts.Metrics.countDropped()
+		return 0, false
+	}
+	if key != nil {
+		key.tokens--
+	}
+	if s.opts.Rate > 0 {
+		s.global.tokens--
+	}
+
+	suppressed := s.suppressed
+	s.suppressed = 0
+	return suppressed, true
+}
+
+// pruneKeys drops buckets that have refilled completely, which behave the
+// same as a fresh bucket. If none have, it starts over.
+func (s *rateLimitState) pruneKeys(now time.Time) {
+	for k, b := range s.keys {
+		b.refill(now, s.opts.PerKeyRate, s.opts.PerKeyBurst)
+		if b.tokens >= float64(s.opts.PerKeyBurst) {
+			delete(s.keys, k)
+		}
+	}
+	if len(s.keys) >= maxRateLimitKeys {
+		clear(s.keys)
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+	}
+	b.last = now
+}
@@ -0,0 +1,62 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h := NewRateLimitHandler(NewHandler(&buf, &Options{DisableColor: true}), RateLimitOptions{
+		Rate:        10,
+		Burst:       10,
+		PerKeyRate:  1,
+		PerKeyBurst: 2,
+	})
+	h.state.now = func() time.Time { return now }
+	logger := slog.New(h)
+
+	// The per-message bucket lets two through, then drops the rest
+	for range 5 {
+		logger.Info("Storm")
+	}
+	// Other messages have their own bucket
+	logger.Info("Unrelated")
+
+	if got := strings.Count(buf.String(), "Storm"); got != 2 {
+		t.Errorf("emitted %d storm records, want 2", got)
+	}
+	if !strings.Contains(buf.String(), "Unrelated") {
+		t.Errorf("output = %q, unrelated message should not be limited", buf.String())
+	}
+	if !strings.Contains(buf.String(), "suppressed=3") {
+		t.Errorf("output = %q, next record should report suppressed=3", buf.String())
+	}
+	if got := h.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d, want 0 after being reported", got)
+	}
+
+	// The global bucket caps all messages together
+	buf.Reset()
+	for i := range 20 {
+		logger.Info("Distinct " + strconv.Itoa(i))
+		now = now.Add(time.Millisecond)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 7 {
+		t.Errorf("emitted %d records, want the 7 tokens left in the global bucket", got)
+	}
+
+	// Tokens refill over time
+	buf.Reset()
+	now = now.Add(2 * time.Second)
+	logger.Info("Storm")
+	if !strings.Contains(buf.String(), "Storm") || !strings.Contains(buf.String(), "suppressed=13") {
+		t.Errorf("output = %q, want Storm with suppressed=13 after refill", buf.String())
+	}
+}
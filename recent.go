@@ -0,0 +1,249 @@
+package humanlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRecentSize is the number of records a RecentLogs keeps.
+const defaultRecentSize = 1000
+
+// RecentLogs is a slog.Handler that keeps the most recent records in memory
+// and serves them over HTTP, so operators can inspect a running service
+// without shelling into the box. Combine it with the regular output using
+// NewTeeHandler or NewMultiHandler, and mount it on a debug mux:
+//
+//	recent := humanlog.NewRecentLogs(500, slog.LevelDebug)
+//	logger := slog.New(humanlog.NewMultiHandler(humanlog.NewHandler(os.Stderr, nil), recent))
+//	http.Handle("/debug/logs", recent)
+//
+// ServeHTTP supports these query parameters:
+//
+//	level=warn          only records at or above the level
+//	attr=tenant=acme    only records with the attribute; may be repeated
+//	limit=50            only the newest N matching records
+//	format=json         a JSON array instead of plain text
+type RecentLogs struct {
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+	store *recentStore
+}
+
+// recentStore is the ring of records shared by a RecentLogs and every
+// handler derived from it.
+type recentStore struct {
+	mu      sync.Mutex
+	entries []recentEntry
+	next    int
+}
+
+// recentEntry is a stored record with its attributes flattened into
+// dotted keys.
+type recentEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// NewRecentLogs creates a RecentLogs keeping the last size records at or
+// above level. If size is not positive, 1000 records are kept.
+func NewRecentLogs(size int, level slog.Leveler) *RecentLogs {
+	if size <= 0 {
+		size = defaultRecentSize
+	}
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &RecentLogs{level: level, store: &recentStore{entries: make([]recentEntry, 0, size)}}
+}
+
+// Enabled reports whether records at the given level are kept.
+func (rl *RecentLogs) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= rl.level.Level()
+}
+
+// Handle stores the record, evicting the oldest one if the buffer is full.
+func (rl *RecentLogs) Handle(_ context.Context, r slog.Record) error {
+	rl.store.add(newRecentEntry(rl.attrs, rl.group, r))
+	return nil
+}
+
+// WithAttrs returns a new RecentLogs sharing the buffer, whose records
+// include the given attributes.
+func (rl *RecentLogs) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rl2 := *rl
+	rl2.attrs = append([]slog.Attr{}, rl.attrs...)
+	for _, a := range attrs {
+		rl2.attrs = flattenAttrs(rl2.attrs, rl.group, a)
+	}
+	return &rl2
+}
+
+// WithGroup returns a new RecentLogs sharing the buffer, which qualifies
+// attribute keys with name.
+func (rl *RecentLogs) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return rl
+	}
+	rl2 := *rl
+	if rl.group != "" {
+		name = rl.group + "." + name
+	}
+	rl2.group = name
+	return &rl2
+}
+
+// ServeHTTP writes the stored records, oldest first, filtered by the
+// query parameters described on RecentLogs.
+func (rl *RecentLogs) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	minLevel := slog.Level(math.MinInt)
+	if s := q.Get("level"); s != "" {
+		if err := minLevel.UnmarshalText([]byte(s)); err != nil {
+			http.Error(w, "invalid level: "+s, http.StatusBadRequest)
+			return
+		}
+	}
+	var attrFilters [][2]string
+	for _, s := range q["attr"] {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok {
+			http.Error(w, "invalid attr filter, want key=value: "+s, http.StatusBadRequest)
+			return
+		}
+		attrFilters = append(attrFilters, [2]string{key, value})
+	}
+	limit := 0
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid limit: "+s, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	var matched []recentEntry
+	for _, e := range rl.store.snapshot() {
+		if e.Level >= minLevel && e.matches(attrFilters) {
+			matched = append(matched, e)
+		}
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+
+	if q.Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(recentJSON(matched))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	h := newEntryTextHandler(w)
+	for _, e := range matched {
+		_ = h.Handle(req.Context(), e.record())
+	}
+}
+
+// newRecentEntry flattens r and the handler attributes attrs, qualified by
+// group, into a recentEntry.
+func newRecentEntry(attrs []slog.Attr, group string, r slog.Record) recentEntry {
+	all := append(make([]slog.Attr, 0, len(attrs)+r.NumAttrs()), attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		all = flattenAttrs(all, group, a)
+		return true
+	})
+	return recentEntry{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: all}
+}
+
+// newEntryTextHandler returns a Handler rendering stored entries of any
+// level as plain text to w.
+func newEntryTextHandler(w io.Writer) *Handler {
+	return NewHandler(w, &Options{Level: slog.Level(math.MinInt), TimeFormat: time.RFC3339Nano, DisableColor: true})
+}
+
+// record rebuilds a slog.Record from e.
+func (e recentEntry) record() slog.Record {
+	r := slog.NewRecord(e.Time, e.Level, e.Message, 0)
+	r.AddAttrs(e.Attrs...)
+	return r
+}
+
+func (s *recentStore) add(e recentEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) < cap(s.entries) {
+		s.entries = append(s.entries, e)
+		return
+	}
+	s.entries[s.next] = e
+	s.next = (s.next + 1) % len(s.entries)
+}
+
+// snapshot returns a copy of the stored records, oldest first.
+func (s *recentStore) snapshot() []recentEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]recentEntry, 0, len(s.entries))
+	out = append(out, s.entries[s.next:]...)
+	return append(out, s.entries[:s.next]...)
+}
+
+// matches reports whether e has every key=value pair in filters.
+func (e recentEntry) matches(filters [][2]string) bool {
+	for _, f := range filters {
+		found := false
+		for _, a := range e.Attrs {
+			if a.Key == f[0] && flatValue(a.Value) == f[1] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// recentJSONEntry is the JSON representation of a stored record.
+type recentJSONEntry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"msg"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+func recentJSON(entries []recentEntry) []recentJSONEntry {
+	out := make([]recentJSONEntry, 0, len(entries))
+	for _, e := range entries {
+		je := recentJSONEntry{Time: e.Time, Level: e.Level.String(), Message: e.Message}
+		if len(e.Attrs) > 0 {
+			je.Attrs = make(map[string]any, len(e.Attrs))
+			for _, a := range e.Attrs {
+				switch a.Value.Kind() {
+				case slog.KindBool, slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindString:
+					je.Attrs[a.Key] = a.Value.Any()
+				default:
+					je.Attrs[a.Key] = flatValue(a.Value)
+				}
+			}
+		}
+		out = append(out, je)
+	}
+	return out
+}
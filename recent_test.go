@@ -0,0 +1,66 @@
+package humanlog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecentLogs_ServeHTTP(t *testing.T) {
+	recent := NewRecentLogs(3, slog.LevelDebug)
+	logger := slog.New(recent)
+
+	logger.Debug("Evicted")
+	logger.Debug("Cache miss", "key", "a")
+	logger.With("tenant", "acme").Warn("Slow query", "ms", 250)
+	logger.WithGroup("req").Error("Upstream failed", "tenant", "globex")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+		drop  []string
+	}{
+		{name: "all", query: "", want: []string{"Cache miss", "Slow query", "Upstream failed"}, drop: []string{"Evicted"}},
+		{name: "level", query: "?level=warn", want: []string{"Slow query", "Upstream failed"}, drop: []string{"Cache miss"}},
+		{name: "attr", query: "?attr=tenant=acme", want: []string{"Slow query"}, drop: []string{"Upstream failed"}},
+		{name: "group attr", query: "?attr=req.tenant=globex", want: []string{"Upstream failed"}, drop: []string{"Slow query"}},
+		{name: "limit", query: "?limit=1", want: []string{"Upstream failed"}, drop: []string{"Slow query"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			recent.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs"+tt.query, nil))
+
+			body := rec.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("body = %q, should contain %q", body, s)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(body, s) {
+					t.Errorf("body = %q, should not contain %q", body, s)
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	recent.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?format=json&level=warn", nil))
+	var entries []recentJSONEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if len(entries) != 2 || entries[0].Message != "Slow query" || entries[0].Attrs["ms"] != float64(250) {
+		t.Errorf("JSON entries = %+v", entries)
+	}
+
+	rec = httptest.NewRecorder()
+	recent.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?level=loud", nil))
+	if rec.Code != 400 {
+		t.Errorf("invalid level status = %d, want 400", rec.Code)
+	}
+}
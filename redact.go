@@ -0,0 +1,118 @@
+package humanlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// redactedValue replaces the values of redacted attributes.
+const redactedValue = "[REDACTED]"
+
+// redactHandler is a slog.Handler that replaces the values of attributes
+// whose keys match one of its patterns before passing records on, so
+// secrets never reach any sink. Patterns are matched like FormatterRegistry
+// patterns: against both the group-qualified key and the bare key.
+type redactHandler struct {
+	h      slog.Handler
+	keys   []string
+	prefix string // groups opened with WithGroup, joined with dots
+}
+
+// validRedactKeys reports an error for a malformed pattern.
+func validRedactKeys(keys []string) error {
+	for _, key := range keys {
+		if _, err := path.Match(key, ""); err != nil || key == "" {
+			return fmt.Errorf("humanlog: invalid redact pattern %q", key)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler with matching attribute
+// values replaced.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.NumAttrs() == 0 {
+		return h.h.Handle(ctx, r)
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		r2.AddAttrs(h.redact(h.prefix, a))
+		return true
+	})
+	return h.h.Handle(ctx, r2)
+}
+
+// WithAttrs returns a new handler whose records include the given
+// attributes, redacted.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(h.prefix, a)
+	}
+	return &redactHandler{h: h.h.WithAttrs(redacted), keys: h.keys, prefix: h.prefix}
+}
+
+// WithGroup returns a new handler that qualifies attributes with name.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	prefix := name
+	if h.prefix != "" {
+		prefix = h.prefix + "." + name
+	}
+	return &redactHandler{h: h.h.WithGroup(name), keys: h.keys, prefix: prefix}
+}
+
+// redact returns a with its value replaced if its key, qualified with
+// prefix, matches, descending into groups.
+func (h *redactHandler) redact(prefix string, a slog.Attr) slog.Attr {
+	key := prefix
+	if a.Key != "" && prefix != "" {
+		key = prefix + "." + a.Key
+	} else if a.Key != "" {
+		key = a.Key
+	}
+
+	a.Value = a.Value.Resolve()
+	if a.Key != "" && h.matches(key) {
+		a.Value = slog.StringValue(redactedValue)
+		return a
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	group := a.Value.Group()
+	redacted := make([]slog.Attr, len(group))
+	for i, ga := range group {
+		redacted[i] = h.redact(key, ga)
+	}
+	a.Value = slog.GroupValue(redacted...)
+	return a
+}
+
+// matches reports whether the qualified key or its last element matches a
+// pattern.
+func (h *redactHandler) matches(key string) bool {
+	leaf := key
+	if i := strings.LastIndexByte(key, '.'); i != -1 {
+		leaf = key[i+1:]
+	}
+	for _, pattern := range h.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, leaf); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,372 @@
+package humanlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// recordFormatVersion is written with every encoded record, so decoders
+// can reject records from a newer, incompatible encoder.
+const recordFormatVersion = 1
+
+// RecordEncoder is a slog.Handler that serializes records, one JSON object
+// per line, in a self-describing format that keeps attribute kinds and
+// group structure. Replay re-emits the records through any handler, so
+// records captured once can be rendered later in other formats or used as
+// test fixtures:
+//
+//	{"v":1,"time":"2024-01-02T03:04:05Z","level":0,"msg":"Started","attrs":[["port","i",8080],["req","g",[["id","s","a1"]]]]}
+//
+// Each attribute is [key, kind, value] with kind one of s (string),
+// i (int64), u (uint64), f (float64, as a string so NaN and infinities
+// survive), b (bool), d (duration in nanoseconds), t (RFC 3339 time),
+// g (group), e (error message) or a (any other value, as JSON). The
+// source location is not preserved.
+type RecordEncoder struct {
+	out    *recordOutput
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+// recordOutput is the writer shared by an encoder and every encoder
+// derived from it.
+type recordOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecordEncoder creates a RecordEncoder writing records at or above
+// level to w. If level is nil, all records are written.
+func NewRecordEncoder(w io.Writer, level slog.Leveler) *RecordEncoder {
+	if level == nil {
+		level = slog.Level(math.MinInt)
+	}
+	return &RecordEncoder{out: &recordOutput{w: w}, level: level}
+}
+
+// Enabled reports whether records at the given level are written.
+func (e *RecordEncoder) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= e.level.Level()
+}
+
+// Handle writes the record with the encoder's attributes and groups
+// folded into its attributes.
+func (e *RecordEncoder) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r2.AddAttrs(e.attrs...)
+	r2.AddAttrs(nestAttrs(e.groups, attrs)...)
+	return e.Encode(r2)
+}
+
+// WithAttrs returns a new encoder sharing the writer, whose records
+// include the given attributes.
+func (e *RecordEncoder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	e2 := *e
+	e2.attrs = append(append([]slog.Attr{}, e.attrs...), nestAttrs(e.groups, attrs)...)
+	return &e2
+}
+
+// WithGroup returns a new encoder sharing the writer, which nests
+// attributes in the group name.
+func (e *RecordEncoder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return e
+	}
+	e2 := *e
+	e2.groups = append(append([]string{}, e.groups...), name)
+	return &e2
+}
+
+// Encode writes r as one line.
+func (e *RecordEncoder) Encode(r slog.Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(`{"v":`)
+	buf.WriteString(strconv.Itoa(recordFormatVersion))
+	if !r.Time.IsZero() {
+		buf.WriteString(`,"time":`)
+		writeJSONString(&buf, r.Time.Format(time.RFC3339Nano))
+	}
+	buf.WriteString(`,"level":`)
+	buf.WriteString(strconv.Itoa(int(r.Level)))
+	buf.WriteString(`,"msg":`)
+	writeJSONString(&buf, r.Message)
+	if r.NumAttrs() > 0 {
+		attrs := make([]slog.Attr, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		})
+		buf.WriteString(`,"attrs":`)
+		encodeAttrs(&buf, attrs)
+	}
+	buf.WriteString("}\n")
+
+	e.out.mu.Lock()
+	defer e.out.mu.Unlock()
+	_, err := e.out.w.Write(buf.Bytes())
+	return err
+}
+
+// nestAttrs wraps attrs in the given groups, innermost last.
+func nestAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// encodeAttrs writes attrs as a JSON array of [key, kind, value] triples.
+func encodeAttrs(buf *bytes.Buffer, attrs []slog.Attr) {
+	buf.WriteByte('[')
+	first := true
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if a.Equal(slog.Attr{}) || v.Kind() == slog.KindGroup && len(v.Group()) == 0 {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		buf.WriteByte('[')
+		writeJSONString(buf, a.Key)
+		switch v.Kind() {
+		case slog.KindString:
+			buf.WriteString(`,"s",`)
+			writeJSONString(buf, v.String())
+		case slog.KindInt64:
+			buf.WriteString(`,"i",`)
+			buf.WriteString(strconv.FormatInt(v.Int64(), 10))
+		case slog.KindUint64:
+			buf.WriteString(`,"u",`)
+			buf.WriteString(strconv.FormatUint(v.Uint64(), 10))
+		case slog.KindFloat64:
+			buf.WriteString(`,"f",`)
+			writeJSONString(buf, strconv.FormatFloat(v.Float64(), 'g', -1, 64))
+		case slog.KindBool:
+			buf.WriteString(`,"b",`)
+			buf.WriteString(strconv.FormatBool(v.Bool()))
+		case slog.KindDuration:
+			buf.WriteString(`,"d",`)
+			buf.WriteString(strconv.FormatInt(int64(v.Duration()), 10))
+		case slog.KindTime:
+			buf.WriteString(`,"t",`)
+			writeJSONString(buf, v.Time().Format(time.RFC3339Nano))
+		case slog.KindGroup:
+			buf.WriteString(`,"g",`)
+			encodeAttrs(buf, v.Group())
+		default:
+			if err, ok := v.Any().(error); ok {
+				buf.WriteString(`,"e",`)
+				writeJSONString(buf, err.Error())
+			} else if b, err := json.Marshal(v.Any()); err == nil {
+				buf.WriteString(`,"a",`)
+				buf.Write(b)
+			} else {
+				buf.WriteString(`,"a",`)
+				writeJSONString(buf, fmt.Sprintf("%+v", v.Any()))
+			}
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(']')
+}
+
+// writeJSONString writes s as a JSON string.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+// RecordDecoder reads records written by a RecordEncoder.
+type RecordDecoder struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+// NewRecordDecoder creates a RecordDecoder reading from r.
+func NewRecordDecoder(r io.Reader) *RecordDecoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	return &RecordDecoder{sc: sc}
+}
+
+// encodedRecord is the line layout written by RecordEncoder.Encode.
+type encodedRecord struct {
+	V     int             `json:"v"`
+	Time  string          `json:"time"`
+	Level int             `json:"level"`
+	Msg   string          `json:"msg"`
+	Attrs json.RawMessage `json:"attrs"`
+}
+
+// Decode returns the next record. Blank lines are skipped. At the end of
+// the input it returns io.EOF.
+func (d *RecordDecoder) Decode() (slog.Record, error) {
+	for d.sc.Scan() {
+		d.line++
+		line := bytes.TrimSpace(d.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		r, err := decodeRecord(line)
+		if err != nil {
+			return slog.Record{}, fmt.Errorf("humanlog: decode record on line %d: %w", d.line, err)
+		}
+		return r, nil
+	}
+	if err := d.sc.Err(); err != nil {
+		return slog.Record{}, fmt.Errorf("humanlog: read records: %w", err)
+	}
+	return slog.Record{}, io.EOF
+}
+
+func decodeRecord(line []byte) (slog.Record, error) {
+	var er encodedRecord
+	if err := json.Unmarshal(line, &er); err != nil {
+		return slog.Record{}, err
+	}
+	if er.V < 1 || er.V > recordFormatVersion {
+		return slog.Record{}, fmt.Errorf("unsupported format version %d", er.V)
+	}
+	var t time.Time
+	if er.Time != "" {
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, er.Time); err != nil {
+			return slog.Record{}, err
+		}
+	}
+	r := slog.NewRecord(t, slog.Level(er.Level), er.Msg, 0)
+	if len(er.Attrs) > 0 {
+		attrs, err := decodeAttrs(er.Attrs)
+		if err != nil {
+			return slog.Record{}, err
+		}
+		r.AddAttrs(attrs...)
+	}
+	return r, nil
+}
+
+// decodeAttrs parses an array of [key, kind, value] triples.
+func decodeAttrs(data json.RawMessage) ([]slog.Attr, error) {
+	var triples [][]json.RawMessage
+	if err := json.Unmarshal(data, &triples); err != nil {
+		return nil, err
+	}
+	attrs := make([]slog.Attr, 0, len(triples))
+	for _, t := range triples {
+		if len(t) != 3 {
+			return nil, fmt.Errorf("attribute has %d elements, want 3", len(t))
+		}
+		var key, kind string
+		if err := json.Unmarshal(t[0], &key); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(t[1], &kind); err != nil {
+			return nil, err
+		}
+		v, err := decodeValue(kind, t[2])
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", key, err)
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: v})
+	}
+	return attrs, nil
+}
+
+func decodeValue(kind string, raw json.RawMessage) (slog.Value, error) {
+	switch kind {
+	case "s":
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return slog.StringValue(s), err
+	case "i":
+		var n int64
+		err := json.Unmarshal(raw, &n)
+		return slog.Int64Value(n), err
+	case "u":
+		var n uint64
+		err := json.Unmarshal(raw, &n)
+		return slog.Uint64Value(n), err
+	case "f":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return slog.Value{}, err
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return slog.Float64Value(f), err
+	case "b":
+		var b bool
+		err := json.Unmarshal(raw, &b)
+		return slog.BoolValue(b), err
+	case "d":
+		var n int64
+		err := json.Unmarshal(raw, &n)
+		return slog.DurationValue(time.Duration(n)), err
+	case "t":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return slog.Value{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return slog.TimeValue(t), err
+	case "g":
+		attrs, err := decodeAttrs(raw)
+		return slog.GroupValue(attrs...), err
+	case "e":
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return slog.AnyValue(errors.New(s)), err
+	case "a":
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v any
+		err := dec.Decode(&v)
+		return slog.AnyValue(v), err
+	default:
+		return slog.Value{}, fmt.Errorf("unknown kind %q", kind)
+	}
+}
+
+// Replay decodes the records written by a RecordEncoder from r and passes
+// each one enabled by h to h.Handle. It stops at the first decoding or
+// handler error.
+func Replay(r io.Reader, h slog.Handler) error {
+	ctx := context.Background()
+	dec := NewRecordDecoder(r)
+	for {
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, rec); err != nil {
+			return err
+		}
+	}
+}
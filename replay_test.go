@@ -0,0 +1,110 @@
+package humanlog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordEncoder_RoundTrip(t *testing.T) {
+	captured := new(bytes.Buffer)
+	logger := slog.New(NewRecordEncoder(captured, nil)).With("svc", "api").WithGroup("req")
+	logger.Debug("Handled",
+		slog.String("path", "/a\nb"),
+		slog.Int("status", 200),
+		slog.Uint64("bytes", math.MaxUint64),
+		slog.Float64("ratio", math.Inf(1)),
+		slog.Bool("cached", true),
+		slog.Duration("took", 1500*time.Millisecond),
+		slog.Time("at", time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)),
+		slog.Any("err", errors.New("boom")),
+		slog.Any("tags", []string{"x", "y"}),
+		slog.Group("user", slog.String("id", "u1")),
+	)
+
+	// Replaying into another encoder must reproduce the same line
+	replayed := new(bytes.Buffer)
+	if err := Replay(bytes.NewReader(captured.Bytes()), NewRecordEncoder(replayed, nil)); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if replayed.String() != captured.String() {
+		t.Errorf("replayed =\n%s\nwant\n%s", replayed, captured)
+	}
+
+	r, err := NewRecordDecoder(captured).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	got := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		for _, fa := range flattenAttrs(nil, "", a) {
+			got[fa.Key] = fa.Value
+		}
+		return true
+	})
+	if r.Level != slog.LevelDebug || r.Message != "Handled" {
+		t.Errorf("record = %v %q, want DEBUG \"Handled\"", r.Level, r.Message)
+	}
+	checks := map[string]slog.Kind{
+		"svc":         slog.KindString,
+		"req.status":  slog.KindInt64,
+		"req.bytes":   slog.KindUint64,
+		"req.ratio":   slog.KindFloat64,
+		"req.cached":  slog.KindBool,
+		"req.took":    slog.KindDuration,
+		"req.at":      slog.KindTime,
+		"req.err":     slog.KindAny,
+		"req.user.id": slog.KindString,
+		"req.tags":    slog.KindAny,
+		"req.path":    slog.KindString,
+	}
+	for key, kind := range checks {
+		if v, ok := got[key]; !ok || v.Kind() != kind {
+			t.Errorf("attr %s = %v (%v), want kind %v", key, v, v.Kind(), kind)
+		}
+	}
+	if _, ok := got["req.err"].Any().(error); !ok {
+		t.Errorf("req.err = %T, want an error", got["req.err"].Any())
+	}
+	if got["req.bytes"].Uint64() != math.MaxUint64 || !math.IsInf(got["req.ratio"].Float64(), 1) {
+		t.Errorf("numbers = %v %v, want max uint64 and +Inf", got["req.bytes"], got["req.ratio"])
+	}
+}
+
+func TestReplay_Handler(t *testing.T) {
+	captured := new(bytes.Buffer)
+	enc := NewRecordEncoder(captured, slog.LevelInfo)
+	slog.New(enc).Info("Started", "port", 8080)
+	slog.New(enc).Debug("Dropped")
+
+	out := new(bytes.Buffer)
+	h := NewHandler(out, &Options{Deterministic: true, Level: slog.LevelDebug})
+	if err := Replay(captured, h); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got := out.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "Started") || !strings.Contains(got, "port=8080") {
+		t.Errorf("output = %q, want the one captured record", got)
+	}
+}
+
+func TestReplay_Errors(t *testing.T) {
+	tests := []struct {
+		name, input string
+	}{
+		{"not JSON", "garbage\n"},
+		{"newer version", `{"v":99,"level":0,"msg":"x"}` + "\n"},
+		{"unknown kind", `{"v":1,"level":0,"msg":"x","attrs":[["k","z",1]]}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Replay(strings.NewReader("\n"+tt.input), NewRecordEncoder(new(bytes.Buffer), nil))
+			if err == nil || !strings.Contains(err.Error(), "line 2") {
+				t.Errorf("Replay() error = %v, want an error on line 2", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package humanlog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter wraps an http.ResponseWriter to record the response
+// status, the number of body bytes written and whether the connection was
+// flushed or hijacked, for use in logging middleware. It supports
+// http.Flusher, http.Hijacker and io.ReaderFrom when the wrapped writer
+// does, and Unwrap lets http.ResponseController reach the wrapped writer.
+type ResponseWriter struct {
+	w        http.ResponseWriter
+	status   int
+	written  int64
+	flushed  bool
+	hijacked bool
+}
+
+// NewResponseWriter wraps w.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{w: w}
+}
+
+// Status returns the response status code, or 0 if no header has been
+// written yet.
+func (rw *ResponseWriter) Status() int {
+	return rw.status
+}
+
+// BytesWritten returns the number of response body bytes written.
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.written
+}
+
+// Flushed reports whether the response was flushed.
+func (rw *ResponseWriter) Flushed() bool {
+	return rw.flushed
+}
+
+// Hijacked reports whether the connection was hijacked.
+func (rw *ResponseWriter) Hijacked() bool {
+	return rw.hijacked
+}
+
+// Header returns the wrapped writer's header map.
+func (rw *ResponseWriter) Header() http.Header {
+	return rw.w.Header()
+}
+
+// WriteHeader records the status code and writes the header.
+// Informational 1xx headers other than 101 are passed through without
+// being recorded, as the final status follows them.
+func (rw *ResponseWriter) WriteHeader(status int) {
+	if rw.status == 0 && (status >= 200 || status == http.StatusSwitchingProtocols) {
+		rw.status = status
+	}
+	rw.w.WriteHeader(status)
+}
+
+// Write writes body bytes, implying status 200 if no header was written.
+func (rw *ResponseWriter) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.w.Write(p)
+	rw.written += int64(n)
+	return n, err
+}
+
+// ReadFrom copies r into the response, using the wrapped writer's
+// io.ReaderFrom, such as sendfile support, when available.
+func (rw *ResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := rw.w.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(writerOnly{rw.w}, r)
+	}
+	rw.written += n
+	return n, err
+}
+
+// writerOnly hides every method but Write, so io.Copy does not loop back
+// into ReadFrom.
+type writerOnly struct{ io.Writer }
+
+// Flush sends buffered data to the client if the wrapped writer supports
+// it.
+func (rw *ResponseWriter) Flush() {
+	if f, ok := rw.w.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+		rw.flushed = true
+	}
+}
+
+// Hijack takes over the connection if the wrapped writer supports it, and
+// returns http.ErrNotSupported otherwise.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, brw, err := h.Hijack()
+	if err == nil {
+		rw.hijacked = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.w
+}
@@ -0,0 +1,59 @@
+package humanlog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+
+	rw.WriteHeader(http.StatusCreated)
+	_, _ = rw.Write([]byte("abc"))
+	_, _ = rw.ReadFrom(strings.NewReader("defg"))
+	rw.Flush()
+
+	if rw.Status() != http.StatusCreated || rw.BytesWritten() != 7 || !rw.Flushed() {
+		t.Errorf("Status() = %d, BytesWritten() = %d, Flushed() = %v", rw.Status(), rw.BytesWritten(), rw.Flushed())
+	}
+	if rec.Body.String() != "abcdefg" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	// httptest.ResponseRecorder cannot be hijacked
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) || rw.Hijacked() {
+		t.Errorf("Hijack() error = %v, Hijacked() = %v", err, rw.Hijacked())
+	}
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Errorf("ResponseController.Flush() error = %v", err)
+	}
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	var hijacked bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := NewResponseWriter(w)
+		conn, _, err := rw.Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		hijacked = rw.Hijacked()
+		_, _ = conn.Write([]byte("HTTP/1.1 204 No Content\r\n\r\n"))
+		_ = conn.Close()
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if !hijacked {
+		t.Error("Hijacked() = false after Hijack")
+	}
+}
@@ -0,0 +1,209 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// BufferedKey is the attribute added to records replayed by a
+// RingBufferHandler, marking them as held back until an error occurred.
+const BufferedKey = "buffered"
+
+const (
+	// defaultRingSize is the number of records kept per key.
+	defaultRingSize = 100
+	// maxRingKeys bounds the number of buffers; the oldest is evicted first.
+	maxRingKeys = 1000
+)
+
+// RingBufferOptions configures a RingBufferHandler.
+type RingBufferOptions struct {
+	// Level is the minimum level written immediately. Records below it
+	// that the wrapped handler enables are kept in memory instead.
+	// Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// TriggerLevel is the level at which buffered records are written
+	// out ahead of the triggering record.
+	// Default: slog.LevelError
+	TriggerLevel slog.Leveler
+
+	// Size is the number of records kept per key.
+	// Default: 100
+	Size int
+
+	// Key groups buffered records, so an error only dumps the records
+	// that led up to it. Go does not expose goroutine identity, so
+	// records are grouped per request instead.
+	// Default: the request ID set with WithRequestID, or one shared buffer
+	Key func(ctx context.Context, r slog.Record) string
+}
+
+// RingBufferHandler is a slog.Handler that keeps recent low-level records
+// in memory and only writes them when an error occurs, giving the full
+// context of a failure without paying for Debug output on every request.
+//
+// The wrapped handler must enable the levels to be buffered, e.g.
+//
+//	base := humanlog.NewHandler(os.Stderr, &humanlog.Options{Level: slog.LevelDebug})
+//	logger := slog.New(humanlog.NewRingBufferHandler(base, nil))
+//
+// writes Info and above as usual, and Debug records only when they precede
+// an Error with the same request ID. Replayed records are marked with
+// BufferedKey=true.
+type RingBufferHandler struct {
+	h     slog.Handler
+	state *ringState
+}
+
+// ringState is shared by a RingBufferHandler and every handler derived from it.
+type ringState struct {
+	opts RingBufferOptions
+
+	mu    sync.Mutex
+	rings map[string]*ring
+	order []string // keys in creation order, for eviction
+}
+
+// ring holds the most recent records for one key.
+type ring struct {
+	entries []ringEntry
+	next    int
+}
+
+// ringEntry is a held-back record and the handler to replay it through.
+type ringEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// NewRingBufferHandler returns a handler that writes records to h,
+// holding back low-level ones until an error occurs.
+func NewRingBufferHandler(h slog.Handler, opts *RingBufferOptions) *RingBufferHandler {
+	var options RingBufferOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelInfo
+	}
+	if options.TriggerLevel == nil {
+		options.TriggerLevel = slog.LevelError
+	}
+	if options.Size <= 0 {
+		options.Size = defaultRingSize
+	}
+	if options.Key == nil {
+		options.Key = requestIDKey
+	}
+	return &RingBufferHandler{h: h, state: &ringState{opts: options, rings: make(map[string]*ring)}}
+}
+
+// requestIDKey returns the request ID stored in ctx, if any.
+func requestIDKey(ctx context.Context, _ slog.Record) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (rb *RingBufferHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return rb.h.Enabled(ctx, level)
+}
+
+// Handle buffers records below Level. Records at TriggerLevel or above are
+// preceded by the buffered records for the same key.
+func (rb *RingBufferHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := rb.state
+	key := s.opts.Key(ctx, r)
+
+	if r.Level < s.opts.Level.Level() {
+		s.push(key, ringEntry{ctx: context.WithoutCancel(ctx), h: rb.h, r: r.Clone()})
+		return nil
+	}
+
+	var errs []error
+	if r.Level >= s.opts.TriggerLevel.Level() {
+		for _, e := range s.take(key) {
+			e.r.AddAttrs(slog.Bool(BufferedKey, true))
+			if err := e.h.Handle(e.ctx, e.r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := rb.h.Handle(ctx, r); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new RingBufferHandler sharing the buffers, whose
+// records include the given attributes.
+func (rb *RingBufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RingBufferHandler{h: rb.h.WithAttrs(attrs), state: rb.state}
+}
+
+// WithGroup returns a new RingBufferHandler sharing the buffers, whose
+// records use the given group.
+func (rb *RingBufferHandler) WithGroup(name string) slog.Handler {
+	return &RingBufferHandler{h: rb.h.WithGroup(name), state: rb.state}
+}
+
+// Discard drops the records buffered under key, e.g. when a request
+// completes without error.
+func (rb *RingBufferHandler) Discard(key string) {
+	rb.state.mu.Lock()
+	defer rb.state.mu.Unlock()
+	rb.state.remove(key)
+}
+
+// push adds e to the ring for key, overwriting the oldest entry when full.
+func (s *ringState) push(key string, e ringEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rg := s.rings[key]
+	if rg == nil {
+		if len(s.order) >= maxRingKeys {
+			s.remove(s.order[0])
+		}
+		rg = &ring{entries: make([]ringEntry, 0, s.opts.Size)}
+		s.rings[key] = rg
+		s.order = append(s.order, key)
+	}
+	if len(rg.entries) < s.opts.Size {
+		rg.entries = append(rg.entries, e)
+		return
+	}
+	rg.entries[rg.next] = e
+	rg.next = (rg.next + 1) % s.opts.Size
+}
+
+// take removes and returns the records buffered for key, oldest first.
+func (s *ringState) take(key string) []ringEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rg := s.rings[key]
+	if rg == nil {
+		return nil
+	}
+	s.remove(key)
+	return append(rg.entries[rg.next:], rg.entries[:rg.next]...)
+}
+
+// remove must be called with s.mu held.
+func (s *ringState) remove(key string) {
+	if _, ok := s.rings[key]; !ok {
+		return
+	}
+	delete(s.rings, key)
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
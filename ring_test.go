@@ -0,0 +1,54 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRingBufferHandler_DumpsOnError(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewHandler(&buf, &Options{Level: slog.LevelDebug, DisableColor: true})
+	h := NewRingBufferHandler(base, &RingBufferOptions{Size: 2})
+	logger := slog.New(h)
+
+	failing := WithRequestID(context.Background(), "req-1")
+	healthy := WithRequestID(context.Background(), "req-2")
+
+	logger.DebugContext(failing, "Step 1")
+	logger.DebugContext(failing, "Step 2")
+	logger.DebugContext(failing, "Step 3")
+	logger.DebugContext(healthy, "Unrelated step")
+	logger.InfoContext(failing, "Visible")
+
+	if got := buf.String(); strings.Contains(got, "Step") || !strings.Contains(got, "Visible") {
+		t.Fatalf("before error output = %q, want only the Info record", got)
+	}
+
+	logger.ErrorContext(failing, "Request failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"Step 2", "Step 3"} {
+		if !strings.Contains(lines[i+1], want) || !strings.Contains(lines[i+1], "buffered=true") {
+			t.Errorf("line %d = %q, want replayed %q marked buffered=true", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[3], "Request failed") {
+		t.Errorf("last line = %q, want the error", lines[3])
+	}
+	if strings.Contains(buf.String(), "Unrelated step") {
+		t.Error("records from another request should stay buffered")
+	}
+
+	// The buffer is emptied by the dump
+	buf.Reset()
+	logger.ErrorContext(failing, "Failed again")
+	if strings.Contains(buf.String(), "Step") {
+		t.Errorf("output = %q, records should only be dumped once", buf.String())
+	}
+}
@@ -0,0 +1,441 @@
+package humanlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp embedded in rotated file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix is appended to the names of compressed backups.
+const compressSuffix = ".gz"
+
+// RotateInterval selects time-based rotation boundaries.
+type RotateInterval int
+
+const (
+	// RotateNever disables time-based rotation. This is the default.
+	RotateNever RotateInterval = iota
+	// RotateHourly rotates at the start of every hour.
+	RotateHourly
+	// RotateDaily rotates at midnight.
+	RotateDaily
+)
+
+// RotateOptions configures a RotatingWriter.
+type RotateOptions struct {
+	// Filename is the file written to. Rotated files are kept next to it
+	// as "<name>-<timestamp><ext>", e.g. "app-2025-01-02T15-04-05.000.log",
+	// with a counter such as ".1" before the extension when several
+	// rotations happen within one millisecond.
+	Filename string
+
+	// MaxSize is the size in bytes at which the file is rotated.
+	// Zero disables size-based rotation.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files to keep.
+	// Zero keeps all of them.
+	MaxBackups int
+
+	// MaxAge removes rotated files older than this duration.
+	// Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// Interval rotates the file at hourly or daily boundaries, in
+	// addition to any size-based rotation.
+	Interval RotateInterval
+
+	// Location is the time zone that defines rotation boundaries,
+	// e.g. which midnight daily rotation happens at.
+	// Default: time.Local
+	Location *time.Location
+
+	// Compress gzips rotated files in the background.
+	Compress bool
+
+	// LockFile takes an advisory exclusive lock (flock) on the current
+	// file around every write, so processes appending to the same file
+	// never interleave their lines. Options.LockFile sets it for the
+	// handler's writes.
+	LockFile bool
+}
+
+// RotatingWriter is an io.WriteCloser that writes to a file and rotates it
+// once it exceeds a configured size or crosses an hourly or daily boundary.
+// Rotated files can be compressed and are pruned by count and age. It is
+// safe for concurrent use.
+type RotatingWriter struct {
+	opts RotateOptions
+
+	mu           sync.Mutex
+	file         *os.File // nil after Close, or while reopening fails
+	closed       bool
+	size         int64
+	nextRotation time.Time
+	now          func() time.Time
+
+	// millMu serializes compression and cleanup of backups, which run
+	// in the background so rotation does not block writers.
+	millMu sync.Mutex
+	mills  sync.WaitGroup
+}
+
+// NewRotatingWriter opens, or creates, the configured file for appending.
+func NewRotatingWriter(opts RotateOptions) (*RotatingWriter, error) {
+	if opts.Filename == "" {
+		return nil, errors.New("humanlog: rotating writer requires a filename")
+	}
+
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	w := &RotatingWriter{opts: opts, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the current file, rotating first if p would push the
+// file past MaxSize or a rotation boundary has passed. A single write is
+// never split across files.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	return w.write(p, w.opts.LockFile)
+}
+
+// write is Write, locking the file around the write if lock is set.
+func (w *RotatingWriter) write(p []byte, lock bool) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.reopen(); err != nil {
+		return 0, err
+	}
+	dueBySize := w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize
+	dueByTime := !w.nextRotation.IsZero() && !w.now().Before(w.nextRotation)
+	if dueBySize || dueByTime {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	if lock {
+		n, err = flockWrite(w.file, p)
+	} else {
+		n, err = w.file.Write(p)
+	}
+	w.size += int64(n)
+	return n, err
+}
+
+// lockedRotatingWriter writes to a RotatingWriter with its file locked,
+// for Options.LockFile.
+type lockedRotatingWriter struct {
+	w *RotatingWriter
+}
+
+func (l lockedRotatingWriter) Write(p []byte) (int, error) {
+	return l.w.write(p, true)
+}
+
+// Rotate closes the current file, moves it aside as a backup and starts
+// a new one.
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.reopen(); err != nil {
+		return err
+	}
+	return w.rotate()
+}
+
+// Close closes the current file and waits for background compression
+// and cleanup to finish.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	w.closed = true
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+
+	w.mills.Wait()
+	return err
+}
+
+// Sync commits the current file to stable storage, so a Handler's Sync
+// policy reaches the disk.
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// open opens the log file for appending, creating its directory if needed.
+func (w *RotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.opts.Filename), 0o755); err != nil {
+		return fmt.Errorf("humanlog: create log directory: %w", err)
+	}
+	f, err := os.OpenFile(w.opts.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("humanlog: open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("humanlog: stat log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	w.nextRotation = nextRotation(w.now(), w.opts.Interval, w.opts.Location)
+	return nil
+}
+
+// reopen opens the file again if opening it failed after a rotation, so
+// a transient failure does not stop logging for good. It must be called
+// with w.mu held.
+func (w *RotatingWriter) reopen() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file == nil {
+		return w.open()
+	}
+	return nil
+}
+
+// nextRotation returns the first interval boundary after t in loc, or the
+// zero time if time-based rotation is disabled.
+func nextRotation(t time.Time, interval RotateInterval, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+	default:
+		return time.Time{}
+	}
+}
+
+// rotate must be called with w.mu held.
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("humanlog: close log file: %w", err)
+	}
+	w.file = nil
+
+	now := w.now()
+	backup, err := w.moveToBackup(now)
+	if err != nil {
+		return fmt.Errorf("humanlog: rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	if backup == "" {
+		return nil
+	}
+	w.mills.Add(1)
+	go func() {
+		defer w.mills.Done()
+		w.mill(backup, now)
+	}()
+	return nil
+}
+
+// mill compresses a fresh backup if configured and applies retention.
+// Errors are ignored: a backup that cannot be compressed or removed is
+// left in place rather than interrupting logging.
+func (w *RotatingWriter) mill(backup string, now time.Time) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.opts.Compress {
+		_ = compressFile(backup)
+	}
+	_ = w.removeOldBackups(now)
+}
+
+// compressFile gzips path into path+".gz" and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// backupName returns the name of the n-th backup rotated within the
+// millisecond of t: "app-<timestamp>.log", then "app-<timestamp>.1.log"
+// and so on.
+func (w *RotatingWriter) backupName(t time.Time, n int) string {
+	dir := filepath.Dir(w.opts.Filename)
+	base := filepath.Base(w.opts.Filename)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext) + "-" + t.In(w.opts.Location).Format(backupTimeFormat)
+	if n > 0 {
+		name += "." + strconv.Itoa(n)
+	}
+	return filepath.Join(dir, name+ext)
+}
+
+// moveToBackup moves the current file aside under the first backup name
+// for t that is not taken, compressed or not, and returns that name, or
+// "" if there was no file. An existing backup is never replaced: the file
+// is hard-linked to its new name, which fails if the name exists, and
+// renamed only where links are unsupported.
+func (w *RotatingWriter) moveToBackup(t time.Time) (string, error) {
+	for n := 0; ; n++ {
+		backup := w.backupName(t, n)
+		if exists(backup) || exists(backup+compressSuffix) {
+			continue
+		}
+		err := os.Link(w.opts.Filename, backup)
+		switch {
+		case err == nil:
+			return backup, os.Remove(w.opts.Filename)
+		case errors.Is(err, os.ErrExist):
+			continue
+		case errors.Is(err, os.ErrNotExist):
+			return "", nil
+		}
+		if err := os.Rename(w.opts.Filename, backup); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", nil
+			}
+			return "", err
+		}
+		return backup, nil
+	}
+}
+
+// exists reports whether a file named path exists.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// backupFile is a rotated file and the time it was rotated.
+type backupFile struct {
+	path string
+	time time.Time
+	seq  int // counter of backups rotated within the same millisecond
+}
+
+// backups lists rotated files, newest first.
+func (w *RotatingWriter) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.opts.Filename)
+	base := filepath.Base(w.opts.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, compressSuffix)
+		stamp, ok := strings.CutSuffix(stamp, ext)
+		if !ok {
+			continue
+		}
+		seq := 0
+		if len(stamp) > len(backupTimeFormat) && stamp[len(backupTimeFormat)] == '.' {
+			n, err := strconv.Atoi(stamp[len(backupTimeFormat)+1:])
+			if err != nil || n <= 0 {
+				continue
+			}
+			stamp, seq = stamp[:len(backupTimeFormat)], n
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, w.opts.Location)
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].time.Equal(files[j].time) {
+			return files[i].time.After(files[j].time)
+		}
+		return files[i].seq > files[j].seq
+	})
+	return files, nil
+}
+
+// removeOldBackups deletes backups beyond MaxBackups or older than MaxAge
+// relative to now.
+func (w *RotatingWriter) removeOldBackups(now time.Time) error {
+	if w.opts.MaxBackups <= 0 && w.opts.MaxAge <= 0 {
+		return nil
+	}
+	files, err := w.backups()
+	if err != nil {
+		return fmt.Errorf("humanlog: list log backups: %w", err)
+	}
+
+	cutoff := now.Add(-w.opts.MaxAge)
+	var errs []error
+	for i, f := range files {
+		tooMany := w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups
+		tooOld := w.opts.MaxAge > 0 && f.time.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
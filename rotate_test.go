@@ -0,0 +1,233 @@
+package humanlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns successive times one second apart.
+func fakeClock(start time.Time) func() time.Time {
+	t := start
+	return func() time.Time {
+		t = t.Add(time.Second)
+		return t
+	}
+}
+
+func TestRotatingWriter_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingWriter(RotateOptions{Filename: filename, MaxSize: 100, MaxBackups: 2, Location: time.UTC})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	w.now = fakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer func() { _ = w.Close() }()
+
+	logger := slog.New(NewHandler(w, &Options{DisableColor: true, AddSource: false}))
+	for i := 0; i < 10; i++ {
+		logger.Info("Rotating record", slog.Int("i", i))
+	}
+
+	w.mills.Wait()
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups() error = %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2 (MaxBackups)", len(backups))
+	}
+	if name := filepath.Base(backups[0].path); !strings.HasPrefix(name, "app-2025-01-01T00-00-") || !strings.HasSuffix(name, ".000.log") {
+		t.Errorf("newest backup = %s, want timestamped name", backups[0].path)
+	}
+
+	// Every line must be intact in exactly one file
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(current) > 100 {
+		t.Errorf("current file is %d bytes, want at most MaxSize", len(current))
+	}
+	if !strings.Contains(string(current), "i=9") {
+		t.Errorf("current file = %q, should contain the latest record", current)
+	}
+}
+
+func TestRotatingWriter_RapidRotationsKeepEveryLine(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingWriter(RotateOptions{Filename: filename, MaxSize: 10, Location: time.UTC})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	// Every rotation happens within the same millisecond
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	for i := range 50 {
+		if _, err := fmt.Fprintf(w, "line %02d\n", i); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups() error = %v", err)
+	}
+	if len(backups) != 49 {
+		t.Errorf("got %d backups, want 49", len(backups))
+	}
+	lines := 0
+	for _, path := range append([]string{filename}, backupPaths(backups)...) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		lines += strings.Count(string(data), "\n")
+	}
+	if lines != 50 {
+		t.Errorf("found %d lines across all files, want 50", lines)
+	}
+	if got := filepath.Base(backups[0].path); got != "app-2025-01-01T00-00-00.000.48.log" {
+		t.Errorf("newest backup = %s, want the highest counter first", got)
+	}
+}
+
+func backupPaths(files []backupFile) []string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+func TestRotatingWriter_ReopensAfterFailedOpen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "logs", "app.log")
+	w, err := NewRotatingWriter(RotateOptions{Filename: filename})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	// Simulate a rotation whose reopen failed
+	w.mu.Lock()
+	_ = w.file.Close()
+	w.file = nil
+	w.mu.Unlock()
+
+	if _, err := w.Write([]byte("recovered\n")); err != nil {
+		t.Fatalf("Write() error = %v, want the file reopened", err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "recovered\n" {
+		t.Errorf("file = %q, want the record", data)
+	}
+}
+
+func TestRotatingWriter_WriteAfterClose(t *testing.T) {
+	w, err := NewRotatingWriter(RotateOptions{Filename: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil {
+		t.Error("Write() after Close should fail")
+	}
+}
+
+func TestRotatingWriter_SyncErrors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingWriter(RotateOptions{Filename: filename})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	h := NewHandler(w, &Options{Sync: SyncErrors, DisableColor: true})
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "Failed", 0)); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filename); !strings.Contains(string(data), "Failed") {
+		t.Errorf("file = %q, want the error record", data)
+	}
+
+	// A failing sync surfaces, so the handler does reach the file
+	_ = w.file.Close()
+	if err := h.Close(); err == nil {
+		t.Error("Close() error = nil, want the sync failure of the closed file")
+	}
+}
+
+func TestRotatingWriter_DailyCompressAndMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	helsinki := time.FixedZone("EET", 2*60*60)
+
+	// 23:30 local time, 30 minutes before the daily boundary
+	now := time.Date(2025, 1, 1, 21, 30, 0, 0, time.UTC)
+	w, err := NewRotatingWriter(RotateOptions{
+		Filename: filename,
+		Interval: RotateDaily,
+		Location: helsinki,
+		Compress: true,
+		MaxAge:   48 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	w.now = func() time.Time { return now }
+	w.nextRotation = nextRotation(now, RotateDaily, helsinki)
+	defer func() { _ = w.Close() }()
+
+	if want := time.Date(2025, 1, 2, 0, 0, 0, 0, helsinki); !w.nextRotation.Equal(want) {
+		t.Fatalf("nextRotation = %v, want local midnight %v", w.nextRotation, want)
+	}
+
+	// A stale backup that retention should remove
+	stale := filepath.Join(dir, "app-2024-12-01T00-00-00.000.log.gz")
+	if err := os.WriteFile(stale, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("before midnight\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := w.Write([]byte("after midnight\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.mills.Wait()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups() error = %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1: %+v", len(backups), backups)
+	}
+	if want := "app-2025-01-02T00-30-00.000.log.gz"; filepath.Base(backups[0].path) != want {
+		t.Errorf("backup = %s, want %s", filepath.Base(backups[0].path), want)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale backup should have been removed, stat error = %v", err)
+	}
+
+	current, _ := os.ReadFile(filename)
+	if string(current) != "after midnight\n" {
+		t.Errorf("current file = %q, want only the record after midnight", current)
+	}
+}
@@ -0,0 +1,184 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// Route describes a single destination of a Router.
+type Route struct {
+	// Writer is where records for this route are written.
+	Writer io.Writer
+
+	// Options configures formatting for this route. Options.Level is the
+	// minimum level routed here. If nil, default options are used.
+	Options *Options
+
+	// Accept optionally restricts which levels reach this route beyond
+	// Options.Level. A nil Accept accepts every enabled level.
+	Accept func(level slog.Level) bool
+
+	// Async, if set, delivers records to this route on a background
+	// goroutine through an AsyncHandler configured with these options, so
+	// a slow writer does not delay the other routes or the caller.
+	// Default: records are written on the caller's goroutine
+	Async *AsyncOptions
+}
+
+// Router is a slog.Handler that dispatches records to multiple writers,
+// each formatted with its own Options. Handle writes to the routes one
+// after another on the caller's goroutine, so a slow route delays the
+// routes after it and the caller. Set Route.Async to move a slow sink off
+// the logging path; Close then drains its queue.
+type Router struct {
+	routes []routeHandler
+}
+
+type routeHandler struct {
+	h      *Handler
+	async  *AsyncHandler // wraps h when Route.Async is set
+	accept func(level slog.Level) bool
+}
+
+// NewRouter creates a Router writing to the given routes.
+// It panics if any route has a nil writer.
+func NewRouter(routes ...Route) *Router {
+	r := &Router{routes: make([]routeHandler, 0, len(routes))}
+	for _, route := range routes {
+		rh := routeHandler{
+			h:      NewHandler(route.Writer, route.Options),
+			accept: route.Accept,
+		}
+		if route.Async != nil {
+			rh.async = NewAsyncHandler(rh.h, route.Async)
+		}
+		r.routes = append(r.routes, rh)
+	}
+	return r
+}
+
+// Enabled reports whether any route handles records at the given level.
+func (r *Router) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, rh := range r.routes {
+		if rh.enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle writes the record to every route that accepts its level, in
+// order. A failing route does not prevent delivery to the remaining routes;
+// all write errors are joined and returned. Errors from async routes are
+// reported through their AsyncOptions.OnError instead.
+func (r *Router) Handle(ctx context.Context, rec slog.Record) error {
+	var errs []error
+	for _, rh := range r.routes {
+		if !rh.enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := rh.handle(ctx, rec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new Router whose routes all include the given attributes.
+func (r *Router) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return r.derive(func(h *Handler) *Handler {
+		return h.WithAttrs(attrs).(*Handler)
+	})
+}
+
+// WithGroup returns a new Router whose routes all use the given group.
+func (r *Router) WithGroup(name string) slog.Handler {
+	return r.derive(func(h *Handler) *Handler {
+		return h.WithGroup(name).(*Handler)
+	})
+}
+
+// derive returns a copy of r with fn applied to every route. Derived routes
+// keep sharing their parent's output, and therefore its lock, as well as
+// the queue of an async route.
+func (r *Router) derive(fn func(h *Handler) *Handler) *Router {
+	r2 := &Router{routes: make([]routeHandler, len(r.routes))}
+	for i, rh := range r.routes {
+		h := fn(rh.h)
+		var async *AsyncHandler
+		if rh.async != nil {
+			async = &AsyncHandler{h: h, queue: rh.async.queue}
+		}
+		r2.routes[i] = routeHandler{h: h, async: async, accept: rh.accept}
+	}
+	return r2
+}
+
+func (rh routeHandler) handle(ctx context.Context, rec slog.Record) error {
+	if rh.async != nil {
+		return rh.async.Handle(ctx, rec)
+	}
+	return rh.h.Handle(ctx, rec)
+}
+
+func (rh routeHandler) enabled(ctx context.Context, level slog.Level) bool {
+	if rh.accept != nil && !rh.accept(level) {
+		return false
+	}
+	return rh.h.Enabled(ctx, level)
+}
+
+// NewSplitHandler creates a Router that writes records at or above errLevel
+// to errOut and everything below it to out, the convention many CI systems
+// and process supervisors expect from stdout and stderr. Both writers use
+// opts, and opts.Level still sets the overall minimum level. If errLevel is
+// nil, slog.LevelWarn is used.
+//
+// Example:
+//
+//	logger := slog.New(humanlog.NewSplitHandler(os.Stdout, os.Stderr, nil, nil))
+func NewSplitHandler(out, errOut io.Writer, errLevel slog.Leveler, opts *Options) *Router {
+	if errLevel == nil {
+		errLevel = slog.LevelWarn
+	}
+	return NewRouter(
+		Route{
+			Writer:  out,
+			Options: opts,
+			Accept:  func(level slog.Level) bool { return level < errLevel.Level() },
+		},
+		Route{
+			Writer:  errOut,
+			Options: opts,
+			Accept:  func(level slog.Level) bool { return level >= errLevel.Level() },
+		},
+	)
+}
+
+// Flush writes any output buffered because of Options.BufferSize on every route.
+func (r *Router) Flush() error {
+	var errs []error
+	for _, rh := range r.routes {
+		if err := rh.h.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every route's Handler, first draining the queues of async
+// routes. See Handler.Close.
+func (r *Router) Close() error {
+	var errs []error
+	for _, rh := range r.routes {
+		if rh.async != nil {
+			rh.async.Close()
+		}
+		if err := rh.h.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRouter_RoutesByLevel(t *testing.T) {
+	console := new(bytes.Buffer)
+	debug := new(bytes.Buffer)
+
+	r := NewRouter(
+		Route{
+			Writer:  console,
+			Options: &Options{Level: slog.LevelInfo, DisableColor: true},
+		},
+		Route{
+			Writer:  debug,
+			Options: &Options{Level: slog.LevelDebug, DisableColor: true},
+			Accept:  func(level slog.Level) bool { return level < slog.LevelInfo },
+		},
+	)
+	logger := slog.New(r).With(slog.String("component", "router"))
+
+	logger.Debug("Debug only")
+	logger.Info("Console only")
+
+	if got := console.String(); strings.Contains(got, "Debug only") || !strings.Contains(got, "Console only") {
+		t.Errorf("console route output = %q, want only the info record", got)
+	}
+	if got := debug.String(); !strings.Contains(got, "Debug only") || strings.Contains(got, "Console only") {
+		t.Errorf("debug route output = %q, want only the debug record", got)
+	}
+	if !strings.Contains(console.String(), "component=router") {
+		t.Errorf("console route output = %q, should contain component=router", console.String())
+	}
+}
+
+func TestRouter_DerivedRoutesShareOutput(t *testing.T) {
+	r := NewRouter(Route{Writer: new(bytes.Buffer)})
+	derived := r.WithGroup("request").WithAttrs([]slog.Attr{slog.String("id", "1")}).(*Router)
+
+	if derived.routes[0].h.out != r.routes[0].h.out {
+		t.Error("derived router should share the route output and its lock")
+	}
+}
+
+func TestNewSplitHandler(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	logger := slog.New(NewSplitHandler(stdout, stderr, nil, &Options{Level: slog.LevelDebug, DisableColor: true}))
+	logger.Debug("Debug line")
+	logger.Info("Info line")
+	logger.Warn("Warn line")
+	logger.Error("Error line")
+
+	if got := stdout.String(); !strings.Contains(got, "Debug line") || !strings.Contains(got, "Info line") ||
+		strings.Contains(got, "Warn line") || strings.Contains(got, "Error line") {
+		t.Errorf("stdout = %q, want only Debug and Info", got)
+	}
+	if got := stderr.String(); strings.Contains(got, "Info line") ||
+		!strings.Contains(got, "Warn line") || !strings.Contains(got, "Error line") {
+		t.Errorf("stderr = %q, want only Warn and Error", got)
+	}
+}
+
+func TestRouter_AsyncRoute(t *testing.T) {
+	console := new(bytes.Buffer)
+	slow := new(bytes.Buffer)
+
+	r := NewRouter(
+		Route{Writer: console, Options: &Options{DisableColor: true}},
+		Route{Writer: slow, Options: &Options{DisableColor: true}, Async: &AsyncOptions{}},
+	)
+	logger := slog.New(r).With(slog.String("component", "router"))
+	logger.Info("Both routes")
+
+	if got := console.String(); !strings.Contains(got, "Both routes") {
+		t.Errorf("console route output = %q, should be written before Handle returns", got)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := slow.String(); !strings.Contains(got, "Both routes") || !strings.Contains(got, "component=router") {
+		t.Errorf("async route output = %q, should contain the drained record", got)
+	}
+}
@@ -0,0 +1,64 @@
+package humanlog
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ControlMode controls how control characters in messages, keys and
+// unquoted values are rendered. Untrusted input containing "\r", "\n" or
+// escape sequences could otherwise overwrite the terminal or forge
+// additional log lines.
+type ControlMode int
+
+const (
+	// ControlEscape replaces control characters with Go escapes such as
+	// \n, \r and \x1b. This is the default.
+	ControlEscape ControlMode = iota
+	// ControlStrip removes control characters.
+	ControlStrip
+	// ControlKeep writes control characters unchanged. Quoted values are
+	// still escaped.
+	ControlKeep
+)
+
+// isUnsafe reports whether r is a control character or a bidirectional
+// formatting character, which can reorder the text shown on screen.
+func isUnsafe(r rune) bool {
+	return unicode.IsControl(r) || (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// sanitize renders the control characters in s according to mode.
+func sanitize(s string, mode ControlMode) string {
+	if mode == ControlKeep || strings.IndexFunc(s, isUnsafe) < 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s) + 8)
+	for _, r := range s {
+		if !isUnsafe(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if mode == ControlStrip {
+			continue
+		}
+		switch r {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x80 {
+				fmt.Fprintf(&sb, `\x%02x`, r)
+			} else {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package humanlog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestControlChars(t *testing.T) {
+	log := func(mode ControlMode) string {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &Options{ControlChars: mode, Deterministic: true, TimeFormat: TimeFormatSeconds, MessageWidth: 20}))
+		logger.Info("Login\r\n[00:00:00] INFO",
+			"user", "ana\x1b[2J",
+			"k\ney", 1,
+			"err", errors.New("bad\rthing"),
+			"raw", []any{"a\tb"},
+		)
+		return buf.String()
+	}
+
+	tests := []struct {
+		mode ControlMode
+		want string
+	}{
+		{ControlEscape, `[00:00:00] INFO  Login\r\n[00:00:0... err="bad\rthing" k\ney=1 raw=[a\tb] user="ana\x1b[2J"` + "\n"},
+		{ControlStrip, `[00:00:00] INFO  Login[00:00:00] INFO err="badthing" key=1 raw=[ab] user="ana[2J"` + "\n"},
+		{ControlKeep, "[00:00:00] INFO  Login\r\n[00:00:00]... err=\"bad\\rthing\" k\ney=1 raw=[a\tb] user=\"ana\\x1b[2J\"\n"},
+	}
+	for _, tt := range tests {
+		if got := log(tt.mode); got != tt.want {
+			t.Errorf("mode %d output =\n%q\nwant\n%q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSanitize_Bidi(t *testing.T) {
+	if got := sanitize("admin\u202e", ControlEscape); got != `admin\u202e` {
+		t.Errorf("sanitize() = %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package humanlog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// semanticStyle returns the style for well-known HTTP attributes: status
+// codes by class and request methods by verb, so access logs read at a
+// glance. It returns "" for other attributes and unrecognized values.
+func semanticStyle(key string, v slog.Value) string {
+	if i := strings.LastIndexByte(key, '.'); i != -1 {
+		key = key[i+1:]
+	}
+	switch key {
+	case "status", "status_code":
+		return statusStyle(v.Resolve())
+	case "method":
+		return methodStyle(v.Resolve().String())
+	}
+	return ""
+}
+
+// statusStyle colors an HTTP status code by class.
+func statusStyle(v slog.Value) string {
+	var code int64
+	switch v.Kind() {
+	case slog.KindInt64:
+		code = v.Int64()
+	case slog.KindUint64:
+		code = int64(min(v.Uint64(), 1000))
+	case slog.KindFloat64:
+		code = int64(v.Float64())
+		if float64(code) != v.Float64() {
+			return ""
+		}
+	case slog.KindString:
+		// Parse only three digits, as a failed parse allocates
+		s := v.String()
+		if len(s) != 3 || strings.Trim(s, "0123456789") != "" {
+			return ""
+		}
+		code, _ = strconv.ParseInt(s, 10, 64)
+	default:
+		return ""
+	}
+	switch {
+	case code >= 200 && code < 300:
+		return StyleGreen
+	case code >= 300 && code < 400:
+		return StyleCyan
+	case code >= 400 && code < 500:
+		return StyleYellow
+	case code >= 500 && code < 600:
+		return StyleRed
+	}
+	return ""
+}
+
+// methodStyle colors an HTTP request method: reads blue, writes green or
+// yellow and deletes red.
+func methodStyle(method string) string {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return StyleBlue
+	case "POST":
+		return StyleGreen
+	case "PUT", "PATCH":
+		return StyleYellow
+	case "DELETE":
+		return StyleRed
+	}
+	return ""
+}
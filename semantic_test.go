@@ -0,0 +1,64 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSemanticStyle(t *testing.T) {
+	tests := []struct {
+		key  string
+		val  slog.Value
+		want string
+	}{
+		{"status", slog.IntValue(204), StyleGreen},
+		{"status_code", slog.IntValue(301), StyleCyan},
+		{"http.status", slog.StringValue("404"), StyleYellow},
+		{"status", slog.Float64Value(503), StyleRed},
+		{"status", slog.IntValue(42), ""},
+		{"status", slog.StringValue("ok"), ""},
+		{"code", slog.IntValue(500), ""},
+		{"method", slog.StringValue("GET"), StyleBlue},
+		{"req.method", slog.StringValue("DELETE"), StyleRed},
+		{"method", slog.StringValue("BREW"), ""},
+	}
+	for _, tt := range tests {
+		if got := semanticStyle(tt.key, tt.val); got != tt.want {
+			t.Errorf("semanticStyle(%q, %v) = %q, want %q", tt.key, tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_HTTPColor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{TimeFormat: TimeFormatSeconds}))
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	LogHTTPRequest(logger, req, http.StatusInternalServerError, time.Millisecond)
+
+	out := buf.String()
+	for _, want := range []string{
+		"request.method=" + StyleGreen + "POST" + colorReset,
+		"request.status_code=" + StyleRed + "500" + colorReset,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+
+	// Highlights take precedence and the colors can be disabled
+	buf.Reset()
+	logger = slog.New(NewHandler(&buf, &Options{
+		DisableHTTPColor: true,
+		Highlights:       []HighlightRule{{Key: "method", Style: StyleBold}},
+	}))
+	logger.Info("Done", "method", "GET", "status", 200)
+	out = buf.String()
+	if !strings.Contains(out, "method="+StyleBold+"GET"+colorReset) || !strings.Contains(out, "status=200") {
+		t.Errorf("output %q, want bold method and plain status", out)
+	}
+}
@@ -0,0 +1,113 @@
+package humanlog
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// Queryer is the query interface shared by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLOptions configures a SQLLogger.
+type SQLOptions struct {
+	// Level is the level of successful queries.
+	// Default: slog.LevelDebug
+	Level slog.Leveler
+
+	// SlowThreshold escalates queries taking at least this long to
+	// SlowLevel. Zero disables slow-query detection.
+	SlowThreshold time.Duration
+
+	// SlowLevel is the level of slow queries.
+	// Default: slog.LevelWarn
+	SlowLevel slog.Leveler
+
+	// LogArgs adds the query arguments to records under "args". By default
+	// only their count is logged, since arguments often hold personal data
+	// or secrets.
+	LogArgs bool
+}
+
+// SQLLogger wraps a Queryer and logs every query with a DatabaseQuery
+// under the "query" key, plus "rows_affected" for Exec. Failed queries are
+// logged at Error. Scan errors of QueryRowContext are reported by
+// sql.Row.Scan and are not logged.
+type SQLLogger struct {
+	q      Queryer
+	logger *slog.Logger
+	opts   SQLOptions
+}
+
+// NewSQLLogger wraps q, such as a *sql.DB, logging to logger.
+func NewSQLLogger(q Queryer, logger *slog.Logger, opts *SQLOptions) *SQLLogger {
+	var options SQLOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelDebug
+	}
+	if options.SlowLevel == nil {
+		options.SlowLevel = slog.LevelWarn
+	}
+	return &SQLLogger{q: q, logger: logger, opts: options}
+}
+
+// ExecContext executes query and logs it with the number of rows affected.
+func (s *SQLLogger) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.q.ExecContext(ctx, query, args...)
+	var extra []slog.Attr
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			extra = append(extra, slog.Int64("rows_affected", n))
+		}
+	}
+	s.log(ctx, query, args, time.Since(start), err, extra...)
+	return res, err
+}
+
+// QueryContext executes query and logs it. The duration covers the query
+// up to the first row, not reading the rows.
+func (s *SQLLogger) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	s.log(ctx, query, args, time.Since(start), err)
+	return rows, err
+}
+
+// QueryRowContext executes query and logs it.
+func (s *SQLLogger) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := s.q.QueryRowContext(ctx, query, args...)
+	s.log(ctx, query, args, time.Since(start), row.Err())
+	return row
+}
+
+// log emits the record for one query.
+func (s *SQLLogger) log(ctx context.Context, query string, args []any, d time.Duration, err error, extra ...slog.Attr) {
+	level := s.opts.Level.Level()
+	msg := "Database query executed"
+	switch {
+	case err != nil:
+		level, msg = slog.LevelError, "Database query failed"
+	case s.opts.SlowThreshold > 0 && d >= s.opts.SlowThreshold:
+		level, msg = s.opts.SlowLevel.Level(), "Slow database query"
+	}
+	if !s.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{slog.Any("query", DatabaseQuery{Query: query, Args: args, Duration: d, Error: err})}
+	if s.opts.LogArgs && len(args) > 0 {
+		attrs = append(attrs, slog.Any("args", args))
+	}
+	attrs = append(attrs, extra...)
+	s.logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,131 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDriver is a minimal database/sql driver. Queries containing "fail"
+// return an error and queries containing "sleep" take 20ms.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) run() error {
+	if strings.Contains(s.query, "sleep") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if strings.Contains(s.query, "fail") {
+		return errors.New("syntax error")
+	}
+	return nil
+}
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func init() {
+	sql.Register("humanlog-fake", fakeDriver{})
+}
+
+func TestSQLLogger(t *testing.T) {
+	db, err := sql.Open("humanlog-fake", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelDebug, UseJSON: true}))
+	q := NewSQLLogger(db, logger, &SQLOptions{SlowThreshold: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	if _, err := q.ExecContext(ctx, "UPDATE users SET name = ?", "secret"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	m := decodeJSONLine(t, &buf)
+	query, _ := m["query"].(map[string]any)
+	if m["level"] != "DEBUG" || m["rows_affected"] != 3.0 || query["arg_count"] != 1.0 || m["args"] != nil {
+		t.Errorf("exec record = %v", m)
+	}
+
+	buf.Reset()
+	var n int
+	if err := q.QueryRowContext(ctx, "SELECT sleep").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("QueryRowContext() = %d, %v", n, err)
+	}
+	if m := decodeJSONLine(t, &buf); m["level"] != "WARN" || m["msg"] != "Slow database query" {
+		t.Errorf("slow record = %v", m)
+	}
+
+	buf.Reset()
+	if _, err := q.QueryContext(ctx, "SELECT fail"); err == nil {
+		t.Fatal("QueryContext() error = nil, want error")
+	}
+	m = decodeJSONLine(t, &buf)
+	query, _ = m["query"].(map[string]any)
+	if m["level"] != "ERROR" || query["error"] != "syntax error" {
+		t.Errorf("failed record = %v", m)
+	}
+}
+
+func TestSQLLogger_LogArgs(t *testing.T) {
+	db, err := sql.Open("humanlog-fake", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelDebug, UseJSON: true}))
+	q := NewSQLLogger(db, logger, &SQLOptions{LogArgs: true})
+	if _, err := q.ExecContext(context.Background(), "DELETE FROM t WHERE id = ?", 42); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if m := decodeJSONLine(t, &buf); !strings.Contains(buf.String(), `"args":[42]`) {
+		t.Errorf("record = %v, want args", m)
+	}
+}
@@ -0,0 +1,104 @@
+package humanlog
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// StdLogOptions configures NewStdLogger.
+type StdLogOptions struct {
+	// Level is the level of records without a recognised level prefix.
+	// Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// DetectLevel picks the level from a conventional prefix of the
+	// message, such as "ERROR:", "WARN " or "[debug]", and removes the
+	// prefix. Recognised names are trace, debug, info, warn, warning,
+	// error, err, fatal and panic, in any case, except that a name followed
+	// only by a space must be upper case.
+	DetectLevel bool
+}
+
+// NewStdLogger returns a *log.Logger whose output is logged through h, so
+// packages using the standard library logger blend into humanlog output.
+// Each Print call becomes one record; the log.Logger's prefix and flags
+// are left empty because h adds its own time and source.
+func NewStdLogger(h slog.Handler, opts *StdLogOptions) *log.Logger {
+	var options StdLogOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelInfo
+	}
+	return log.New(&stdLogWriter{h: h, opts: options}, "", 0)
+}
+
+// stdLogWriter receives one message per Write from a log.Logger.
+type stdLogWriter struct {
+	h    slog.Handler
+	opts StdLogOptions
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	level := w.opts.Level.Level()
+	if w.opts.DetectLevel {
+		if l, rest, ok := detectLevel(msg); ok {
+			level, msg = l, rest
+		}
+	}
+
+	ctx := context.Background()
+	if !w.h.Enabled(ctx, level) {
+		return len(p), nil
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	return len(p), w.h.Handle(ctx, r)
+}
+
+// stdLevelNames maps level prefixes recognised by detectLevel to levels.
+var stdLevelNames = map[string]slog.Level{
+	"trace":   LevelTrace,
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+	"err":     slog.LevelError,
+	"fatal":   slog.LevelError,
+	"panic":   slog.LevelError,
+}
+
+// detectLevel recognises a leading level name written as "LEVEL:",
+// "LEVEL " or "[LEVEL]" and returns the level and the rest of msg. The
+// space-separated form must be upper case, so ordinary sentences such as
+// "error rate above threshold" are not mistaken for a level.
+func detectLevel(msg string) (slog.Level, string, bool) {
+	var name, rest string
+	if strings.HasPrefix(msg, "[") {
+		end := strings.IndexByte(msg, ']')
+		if end < 0 {
+			return 0, msg, false
+		}
+		name, rest = msg[1:end], msg[end+1:]
+	} else {
+		end := strings.IndexAny(msg, ": ")
+		if end < 0 {
+			return 0, msg, false
+		}
+		name, rest = msg[:end], msg[end+1:]
+		if msg[end] == ' ' && name != strings.ToUpper(name) {
+			return 0, msg, false
+		}
+	}
+
+	level, ok := stdLevelNames[strings.ToLower(name)]
+	if !ok {
+		return 0, msg, false
+	}
+	return level, strings.TrimLeft(rest, ": "), true
+}
@@ -0,0 +1,51 @@
+package humanlog
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/lepinkainen/humanlog/humanlogtest"
+)
+
+func TestNewStdLogger(t *testing.T) {
+	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug)
+	logger := NewStdLogger(capture, &StdLogOptions{DetectLevel: true})
+
+	logger.Print("ERROR: disk full")
+	logger.Print("WARN retrying in 5s")
+	logger.Print("[debug] cache miss")
+	logger.Printf("plain %s", "message")
+	logger.Print("errors are everywhere")
+	logger.Print("error rate above threshold")
+	logger.Print("Warning: low memory")
+
+	want := []struct {
+		level slog.Level
+		msg   string
+	}{
+		{slog.LevelError, "disk full"},
+		{slog.LevelWarn, "retrying in 5s"},
+		{slog.LevelDebug, "cache miss"},
+		{slog.LevelInfo, "plain message"},
+		{slog.LevelInfo, "errors are everywhere"},
+		{slog.LevelInfo, "error rate above threshold"},
+		{slog.LevelWarn, "low memory"},
+	}
+	entries := capture.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %d, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i].Level != w.level || entries[i].Message != w.msg {
+			t.Errorf("entry %d = %v %q, want %v %q", i, entries[i].Level, entries[i].Message, w.level, w.msg)
+		}
+	}
+}
+
+func TestNewStdLogger_NoDetection(t *testing.T) {
+	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug)
+	NewStdLogger(capture, &StdLogOptions{Level: slog.LevelWarn}).Print("ERROR: kept")
+	if e := capture.Entries(); len(e) != 1 || e[0].Level != slog.LevelWarn || e[0].Message != "ERROR: kept" {
+		t.Errorf("entries = %+v", e)
+	}
+}
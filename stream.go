@@ -0,0 +1,188 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultStreamBuffer is the number of records queued per connection
+// before records are dropped for that connection.
+const defaultStreamBuffer = 256
+
+// LogStream is a slog.Handler that broadcasts records to connected
+// browsers and CLIs in real time as Server-Sent Events. Each connection
+// chooses its own filter; a connection that cannot keep up misses records
+// rather than slowing down logging. Mount it alongside the regular output:
+//
+//	stream := humanlog.NewLogStream(slog.LevelDebug)
+//	logger := slog.New(humanlog.NewMultiHandler(humanlog.NewHandler(os.Stderr, nil), stream))
+//	http.Handle("/debug/logs/stream", stream)
+//
+// and follow it with e.g. "curl -N host/debug/logs/stream?level=warn".
+// ServeHTTP accepts the level and format=json query parameters described
+// on RecentLogs. WebSocket is not supported, as it would need a dependency.
+type LogStream struct {
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+	hub   *streamHub
+}
+
+// streamHub tracks the connections of a LogStream and every handler
+// derived from it.
+type streamHub struct {
+	mu   sync.Mutex
+	subs map[*streamSub]struct{}
+}
+
+// streamSub is one connected client.
+type streamSub struct {
+	level slog.Level
+	ch    chan recentEntry
+}
+
+// NewLogStream creates a LogStream broadcasting records at or above level.
+func NewLogStream(level slog.Leveler) *LogStream {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &LogStream{level: level, hub: &streamHub{subs: make(map[*streamSub]struct{})}}
+}
+
+// Enabled reports whether records at the given level are broadcast.
+func (ls *LogStream) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= ls.level.Level()
+}
+
+// Handle sends the record to every connection whose filter accepts it.
+func (ls *LogStream) Handle(_ context.Context, r slog.Record) error {
+	ls.hub.mu.Lock()
+	defer ls.hub.mu.Unlock()
+
+	if len(ls.hub.subs) == 0 {
+		return nil
+	}
+	e := newRecentEntry(ls.attrs, ls.group, r)
+	for sub := range ls.hub.subs {
+		if e.Level < sub.level {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default: // the client is too slow; skip this record for it
+		}
+	}
+	return nil
+}
+
+// WithAttrs returns a new LogStream sharing the connections, whose records
+// include the given attributes.
+func (ls *LogStream) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ls2 := *ls
+	ls2.attrs = append([]slog.Attr{}, ls.attrs...)
+	for _, a := range attrs {
+		ls2.attrs = flattenAttrs(ls2.attrs, ls.group, a)
+	}
+	return &ls2
+}
+
+// WithGroup returns a new LogStream sharing the connections, which
+// qualifies attribute keys with name.
+func (ls *LogStream) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return ls
+	}
+	ls2 := *ls
+	if ls.group != "" {
+		name = ls.group + "." + name
+	}
+	ls2.group = name
+	return &ls2
+}
+
+// Subscribers returns the number of connected clients.
+func (ls *LogStream) Subscribers() int {
+	ls.hub.mu.Lock()
+	defer ls.hub.mu.Unlock()
+	return len(ls.hub.subs)
+}
+
+// ServeHTTP streams records as Server-Sent Events until the client
+// disconnects. Each record is one event; with format=json its data is the
+// JSON object, otherwise the plain text line.
+func (ls *LogStream) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	q := req.URL.Query()
+	sub := &streamSub{level: slog.Level(math.MinInt), ch: make(chan recentEntry, defaultStreamBuffer)}
+	if s := q.Get("level"); s != "" {
+		if err := sub.level.UnmarshalText([]byte(s)); err != nil {
+			http.Error(w, "invalid level: "+s, http.StatusBadRequest)
+			return
+		}
+	}
+	asJSON := q.Get("format") == "json"
+
+	ls.hub.add(sub)
+	defer ls.hub.remove(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var line bytes.Buffer
+	text := newEntryTextHandler(&line)
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case e := <-sub.ch:
+			line.Reset()
+			if asJSON {
+				_ = json.NewEncoder(&line).Encode(recentJSON([]recentEntry{e})[0])
+			} else {
+				_ = text.Handle(req.Context(), e.record())
+			}
+			if _, err := w.Write(sseEvent(line.String())); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (hub *streamHub) add(sub *streamSub) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.subs[sub] = struct{}{}
+}
+
+func (hub *streamHub) remove(sub *streamSub) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	delete(hub.subs, sub)
+}
+
+// sseEvent frames data as a Server-Sent Event, one "data:" field per line.
+func sseEvent(data string) []byte {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(data, "\n"), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
@@ -0,0 +1,51 @@
+package humanlog
+
+import (
+	"bufio"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogStream_ServesSSE(t *testing.T) {
+	stream := NewLogStream(slog.LevelDebug)
+	srv := httptest.NewServer(stream)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?level=warn")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for stream.Subscribers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logger := slog.New(stream).With("service", "api")
+	logger.Info("Filtered out")
+	logger.Warn("Disk almost full", "pct", 93)
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read event error = %v", err)
+	}
+	if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, "Disk almost full") ||
+		!strings.Contains(line, "service=api") || !strings.Contains(line, "pct=93") {
+		t.Errorf("event = %q, want the warning as SSE data", line)
+	}
+}
+
+func TestSSEEvent(t *testing.T) {
+	if got, want := string(sseEvent("first\nsecond\n")), "data: first\ndata: second\n\n"; got != want {
+		t.Errorf("sseEvent() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package humanlog
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// summaryLevelNames name the level buckets of a summary line.
+var summaryLevelNames = [...]string{"trace", "debug", "info", "warn", "error"}
+
+// levelSummary counts the records written to an output since the last
+// summary line of Options.SummaryInterval and SummaryEvery. It is guarded
+// by the output's lock.
+type levelSummary struct {
+	counts  [len(summaryLevelNames)]uint64
+	records int
+	since   time.Time
+	every   int
+	style   string
+	now     func() time.Time
+}
+
+func newLevelSummary(opts *Options) *levelSummary {
+	return &levelSummary{
+		since: opts.now(),
+		every: opts.SummaryEvery,
+		style: opts.style(StyleDim),
+		now:   opts.now,
+	}
+}
+
+// count counts a record at level and reports whether a summary is due.
+func (s *levelSummary) count(level slog.Level) bool {
+	switch {
+	case level >= slog.LevelError:
+		s.counts[4]++
+	case level >= slog.LevelWarn:
+		s.counts[3]++
+	case level >= slog.LevelInfo:
+		s.counts[2]++
+	case level >= slog.LevelDebug:
+		s.counts[1]++
+	default:
+		s.counts[0]++
+	}
+	s.records++
+	return s.every > 0 && s.records >= s.every
+}
+
+// line renders the summary, e.g. "— 120 info, 3 warn in last 30s —", and
+// starts counting anew.
+func (s *levelSummary) line() []byte {
+	now := s.now()
+	elapsed := roundElapsed(now.Sub(s.since))
+
+	buf := []byte(s.style)
+	buf = append(buf, "— "...)
+	if s.records == 0 {
+		buf = append(buf, "no records"...)
+	}
+	sep := false
+	for i, n := range s.counts {
+		if n == 0 {
+			continue
+		}
+		if sep {
+			buf = append(buf, ", "...)
+		}
+		sep = true
+		buf = strconv.AppendUint(buf, n, 10)
+		buf = append(buf, ' ')
+		buf = append(buf, summaryLevelNames[i]...)
+	}
+	buf = append(buf, " in last "...)
+	buf = append(buf, elapsed.String()...)
+	buf = append(buf, " —"...)
+	if s.style != "" {
+		buf = append(buf, colorReset...)
+	}
+	buf = append(buf, '\n')
+
+	*s = levelSummary{since: now, every: s.every, style: s.style, now: s.now}
+	return buf
+}
+
+// roundElapsed rounds d to seconds, or to milliseconds below a second,
+// for display.
+func roundElapsed(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// summarizeEvery writes a summary line every interval until stop is
+// closed.
+func (o *output) summarizeEvery(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Failures are reported to OnError by writeLocked
+			o.mu.Lock()
+			_ = o.writeLocked(o.summary.line())
+			o.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Facility is a syslog facility code as defined by RFC 5424.
+type Facility int
+
+// Syslog facilities.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityLocal0 Facility = iota + 4
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// defaultSyslogSDID is the structured data ID used for record attributes.
+// 32473 is the private enterprise number reserved for documentation.
+const defaultSyslogSDID = "attrs@32473"
+
+// SyslogOptions configures a SyslogHandler.
+type SyslogOptions struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". If empty, the local
+	// syslog daemon is used via its Unix socket.
+	Network string
+
+	// Address is the remote address, e.g. "logs.example.com:514", or the
+	// socket path for Unix networks.
+	Address string
+
+	// Writer, if set, receives the formatted messages instead of a network
+	// connection. Each message is written with a single Write call.
+	Writer io.Writer
+
+	// Facility is the syslog facility. It is a pointer so that FacilityKern,
+	// the zero Facility, can be told apart from unset. Default: FacilityUser
+	Facility *Facility
+
+	// AppName identifies the application. Default: the program name.
+	AppName string
+
+	// Hostname is reported in every message. Default: os.Hostname()
+	Hostname string
+
+	// Level is the minimum level sent. Default: slog.LevelInfo
+	Level slog.Leveler
+
+	// SDID is the structured data ID under which attributes are sent.
+	// Default: "attrs@32473"
+	SDID string
+}
+
+// SyslogHandler is a slog.Handler that sends records as RFC 5424 syslog
+// messages, with record attributes as structured data. Over stream
+// transports ("tcp" and "unix"), messages are framed with octet counting
+// (RFC 6587); datagram transports carry one message per packet.
+type SyslogHandler struct {
+	conn   *syslogConn
+	opts   SyslogOptions
+	attrs  []slog.Attr
+	groups []string
+}
+
+// syslogConn is the connection shared by a handler and every handler
+// derived from it.
+type syslogConn struct {
+	mu      sync.Mutex
+	network string
+	address string
+	w       io.Writer
+	dialed  bool
+}
+
+// localSyslogSockets are the usual local syslog daemon socket paths.
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// NewSyslogHandler creates a SyslogHandler and connects to the configured
+// destination.
+func NewSyslogHandler(opts SyslogOptions) (*SyslogHandler, error) {
+	facility := FacilityUser
+	if opts.Facility != nil {
+		facility = *opts.Facility
+	}
+	opts.Facility = &facility
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.SDID == "" {
+		opts.SDID = defaultSyslogSDID
+	}
+
+	conn := &syslogConn{network: opts.Network, address: opts.Address, w: opts.Writer}
+	if conn.w == nil {
+		if err := conn.dial(); err != nil {
+			return nil, err
+		}
+	}
+	return &SyslogHandler{conn: conn, opts: opts}, nil
+}
+
+// Enabled reports whether records at the given level are sent.
+func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle formats the record as an RFC 5424 message and sends it.
+func (h *SyslogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = flattenAttrs(attrs, prefix, a)
+		return true
+	})
+
+	msg := formatSyslog(&h.opts, r, attrs)
+	return h.conn.write(msg)
+}
+
+// WithAttrs returns a new handler whose messages include the given attributes.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr{}, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		h2.attrs = flattenAttrs(h2.attrs, prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new handler that qualifies attribute names with name.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// Close closes the underlying connection, if the handler dialed one.
+func (h *SyslogHandler) Close() error {
+	return h.conn.close()
+}
+
+// syslogTimeFormat is the RFC 5424 TIMESTAMP layout, which allows at most
+// six fractional digits.
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// formatSyslog renders an RFC 5424 message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID name="value"...] MSG
+func formatSyslog(opts *SyslogOptions, r slog.Record, attrs []slog.Attr) []byte {
+	var sb strings.Builder
+
+	pri := int(*opts.Facility)*8 + syslogSeverity(r.Level)
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	fmt.Fprintf(&sb, "<%d>1 %s %s %s %d - ",
+		pri,
+		ts.Format(syslogTimeFormat),
+		syslogHeaderField(opts.Hostname, 255),
+		syslogHeaderField(opts.AppName, 48),
+		os.Getpid(),
+	)
+
+	if len(attrs) == 0 {
+		sb.WriteString("-")
+	} else {
+		sb.WriteString("[")
+		sb.WriteString(opts.SDID)
+		for _, a := range attrs {
+			sb.WriteString(" ")
+			sb.WriteString(syslogParamName(a.Key))
+			sb.WriteString(`="`)
+			sb.WriteString(syslogParamValue(flatValue(a.Value)))
+			sb.WriteString(`"`)
+		}
+		sb.WriteString("]")
+	}
+
+	if r.Message != "" {
+		sb.WriteString(" ")
+		sb.WriteString(r.Message)
+	}
+	return []byte(sb.String())
+}
+
+// syslogSeverity maps a slog level to a syslog severity code.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError+4:
+		return 2 // critical
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo+2:
+		return 5 // notice
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogHeaderField returns s restricted to printable US-ASCII and maxLen
+// characters, or the nil value "-" if empty.
+func syslogHeaderField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogParamName sanitizes an SD-PARAM name: printable US-ASCII except
+// '=', ' ', ']' and '"', at most 32 characters.
+func syslogParamName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// syslogParamValue escapes '"', '\' and ']' in an SD-PARAM value.
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func (c *syslogConn) dial() error {
+	if c.network == "" {
+		for _, path := range localSyslogSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err := net.Dial(network, path); err == nil {
+					c.network, c.address, c.w, c.dialed = network, path, conn, true
+					return nil
+				}
+			}
+		}
+		return errors.New("humanlog: no local syslog socket found")
+	}
+
+	conn, err := net.Dial(c.network, c.address)
+	if err != nil {
+		return fmt.Errorf("humanlog: dial syslog %s %s: %w", c.network, c.address, err)
+	}
+	c.w, c.dialed = conn, true
+	return nil
+}
+
+// write sends msg, reconnecting once if a dialed connection has failed.
+func (c *syslogConn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.w == nil {
+		if err := c.dial(); err != nil {
+			return err
+		}
+	}
+	_, err := c.w.Write(c.frame(msg))
+	if err == nil || !c.dialed {
+		return err
+	}
+
+	// Reconnect and retry once
+	_ = c.closeLocked()
+	if dialErr := c.dial(); dialErr != nil {
+		return errors.Join(err, dialErr)
+	}
+	_, err = c.w.Write(c.frame(msg))
+	return err
+}
+
+// frame prefixes msg with its length on stream networks, so the receiver
+// can tell messages apart. The network is only known once dialed, because
+// the local daemon may be reached over "unix" or "unixgram".
+func (c *syslogConn) frame(msg []byte) []byte {
+	switch c.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	return msg
+}
+
+func (c *syslogConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeLocked()
+}
+
+func (c *syslogConn) closeLocked() error {
+	if !c.dialed || c.w == nil {
+		return nil
+	}
+	err := c.w.(net.Conn).Close()
+	c.w = nil
+	return err
+}
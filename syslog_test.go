@@ -0,0 +1,158 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandler_Format(t *testing.T) {
+	buf := new(bytes.Buffer)
+	facility := FacilityLocal3
+	h, err := NewSyslogHandler(SyslogOptions{
+		Writer:   buf,
+		Facility: &facility,
+		AppName:  "billing",
+		Hostname: "web-1",
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+
+	logger := slog.New(h).WithGroup("req")
+	logger.Error("Charge failed", slog.String("id", `a"b]c`), slog.Int("amount", 42))
+
+	got := buf.String()
+	// local3 (19) * 8 + error (3) = 155
+	wantPrefix := "<155>1 "
+	if !strings.HasPrefix(got, wantPrefix) {
+		t.Errorf("message = %q, should start with %q", got, wantPrefix)
+	}
+	wantHeader := fmt.Sprintf(" web-1 billing %d - ", os.Getpid())
+	if !strings.Contains(got, wantHeader) {
+		t.Errorf("message = %q, should contain header %q", got, wantHeader)
+	}
+	wantSD := `[attrs@32473 req.id="a\"b\]c" req.amount="42"] Charge failed`
+	if !strings.HasSuffix(got, wantSD) {
+		t.Errorf("message = %q, should end with %q", got, wantSD)
+	}
+}
+
+func TestSyslogHandler_Timestamp(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h, err := NewSyslogHandler(SyslogOptions{Writer: buf, Hostname: "web-1"})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+
+	ts := time.Date(2025, 3, 1, 12, 30, 45, 123456789, time.FixedZone("", 2*60*60))
+	if err := h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "Up", 0)); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	// RFC 5424 allows at most six fractional digits
+	want := " 2025-03-01T12:30:45.123456+02:00 web-1 "
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("message = %q, want timestamp %q", got, want)
+	}
+}
+
+func TestSyslogHandler_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "udp", Address: pc.LocalAddr().String(), AppName: "app"})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	slog.New(h).Info("Over UDP")
+
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	packet := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(packet)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	// user (1) * 8 + informational (6) = 14
+	if got := string(packet[:n]); !strings.HasPrefix(got, "<14>1 ") || !strings.HasSuffix(got, "- Over UDP") {
+		t.Errorf("packet = %q, want a user.info message without structured data", got)
+	}
+}
+
+func TestSyslogHandler_FacilityKern(t *testing.T) {
+	buf := new(bytes.Buffer)
+	facility := FacilityKern
+	h, err := NewSyslogHandler(SyslogOptions{Writer: buf, Facility: &facility})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+
+	slog.New(h).Error("Oops")
+
+	// kern (0) * 8 + error (3) = 3
+	if got := buf.String(); !strings.HasPrefix(got, "<3>1 ") {
+		t.Errorf("message = %q, want a kern.err message", got)
+	}
+}
+
+func TestSyslogHandler_UnixStreamFraming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("cannot listen on a Unix socket: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "unix", Address: path, AppName: "app"})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	logger := slog.New(h)
+	logger.Info("First")
+	logger.Info("Second")
+	_ = h.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var stream []byte
+	chunk := make([]byte, 2048)
+	for {
+		n, err := conn.Read(chunk)
+		stream = append(stream, chunk[:n]...)
+		if err != nil {
+			break
+		}
+	}
+
+	// Each message must be prefixed with its length so they can be split
+	for _, want := range []string{"First", "Second"} {
+		length, rest, ok := strings.Cut(string(stream), " ")
+		if !ok {
+			t.Fatalf("stream = %q, want an octet-counted %q message", stream, want)
+		}
+		var n int
+		if _, err := fmt.Sscan(length, &n); err != nil || n > len(rest) {
+			t.Fatalf("frame length %q is invalid for %q", length, rest)
+		}
+		if msg := rest[:n]; !strings.HasSuffix(msg, "- "+want) {
+			t.Errorf("message = %q, want it to end with %q", msg, want)
+		}
+		stream = []byte(rest[n:])
+	}
+}
@@ -0,0 +1,194 @@
+package humanlog
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// attrTable is a slice-of-struct or slice-of-map attribute value laid out
+// as rows of cells under column names.
+type attrTable struct {
+	key     string // group-qualified
+	columns []string
+	rows    [][]slog.Value // zero cells are missing
+	more    int            // rows not shown
+}
+
+// tableOf returns v as a table if it is a non-empty slice or array whose
+// elements are all structs, maps with string keys or LogValuers resolving
+// to groups.
+func tableOf(key string, v slog.Value, maxRows int) (*attrTable, bool) {
+	if v.Kind() != slog.KindAny {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v.Any())
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Len() == 0 {
+		return nil, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	t := &attrTable{key: key}
+	index := map[string]int{}
+	for i := 0; i < rv.Len(); i++ {
+		cells, ok := tableRow(rv.Index(i))
+		if !ok {
+			return nil, false
+		}
+		if i >= maxRows {
+			t.more++
+			continue
+		}
+		row := make([]slog.Value, len(t.columns))
+		for _, c := range cells {
+			col, seen := index[c.Key]
+			if !seen {
+				col = len(t.columns)
+				index[c.Key] = col
+				t.columns = append(t.columns, c.Key)
+				row = append(row, slog.Value{})
+			}
+			row[col] = c.Value
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, true
+}
+
+// tableRow returns the cells of one table element.
+func tableRow(v reflect.Value) ([]slog.Attr, bool) {
+	if v.CanInterface() {
+		if lv, ok := v.Interface().(slog.LogValuer); ok {
+			if r := slog.AnyValue(lv).Resolve(); r.Kind() == slog.KindGroup {
+				return r.Group(), true
+			}
+		}
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	var cells []slog.Attr
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return nil, false
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				cells = append(cells, slog.Any(f.Name, v.Field(i).Interface()))
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			cells = append(cells, slog.Any(k.String(), v.MapIndex(k).Interface()))
+		}
+	default:
+		return nil, false
+	}
+	return cells, true
+}
+
+// splitTables removes the attributes with tabular values from attrs,
+// descending into groups, and returns them as tables.
+func (h *Handler) splitTables(attrs []slog.Attr, prefix string) ([]slog.Attr, []*attrTable) {
+	var rest []slog.Attr
+	var tables []*attrTable
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		key := a.Key
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if key == "" {
+			key = prefix
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			members, inner := h.splitTables(a.Value.Group(), key)
+			tables = append(tables, inner...)
+			if len(members) > 0 {
+				rest = append(rest, slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)})
+			}
+			continue
+		}
+		if t, ok := tableOf(key, a.Value, h.maxTableRows()); ok {
+			tables = append(tables, t)
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, tables
+}
+
+// maxTableRows returns the number of table rows shown per value.
+func (h *Handler) maxTableRows() int {
+	if h.opts.AnyMaxElements > 0 {
+		return h.opts.AnyMaxElements
+	}
+	return defaultAnyMaxElements
+}
+
+// appendTable appends t as aligned rows at the given depth of the nested
+// layout, below a line naming it. Cells are formatted like attribute
+// values, so formatters and highlights match "key.Column".
+func (h *Handler) appendTable(sb *strings.Builder, t *attrTable, name string, depth int) {
+	indent := strings.Repeat(nestedIndent, depth)
+	sb.WriteString(indent)
+	sb.WriteString(sanitize(name, h.opts.ControlChars))
+	sb.WriteString(":\n")
+
+	cells := make([][]string, len(t.rows)+1)
+	widths := make([]int, len(t.columns))
+	for i, col := range t.columns {
+		col = sanitize(col, h.opts.ControlChars)
+		cells[0] = append(cells[0], col)
+		widths[i] = visibleWidth(col)
+	}
+	for r, row := range t.rows {
+		for i, v := range row {
+			s := ""
+			if !v.Equal(slog.Value{}) {
+				s = formatValue(t.key+"."+t.columns[i], v, &h.opts)
+			}
+			cells[r+1] = append(cells[r+1], s)
+			widths[i] = max(widths[i], visibleWidth(s))
+		}
+		// Rows that lack later columns get empty cells
+		for len(cells[r+1]) < len(t.columns) {
+			cells[r+1] = append(cells[r+1], "")
+		}
+	}
+
+	var line strings.Builder
+	for r, row := range cells {
+		line.Reset()
+		for i, s := range row {
+			if i > 0 {
+				line.WriteString(strings.Repeat(" ", widths[i-1]-visibleWidth(row[i-1])+2))
+			}
+			if r == 0 && !h.opts.DisableColor {
+				s = StyleBold + s + colorReset
+			}
+			line.WriteString(s)
+		}
+		sb.WriteString(indent)
+		sb.WriteString(nestedIndent)
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteString("\n")
+	}
+	if t.more > 0 {
+		fmt.Fprintf(sb, "%s%s... %d more rows\n", indent, nestedIndent, t.more)
+	}
+}
@@ -0,0 +1,64 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type batchResult struct {
+	ID     int
+	Name   string
+	OK     bool
+	secret string
+}
+
+func TestHandler_TableValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		DisableColor:  true,
+		TimeFormat:    TimeFormatSeconds,
+		Deterministic: true,
+		MessageWidth:  8,
+		TableValues:   true,
+	}))
+	results := []batchResult{{1, "alice", true, "x"}, {22, "bob", false, "y"}}
+	logger.Info("Imported", "count", 2, slog.Group("batch", "results", results), "tags", []string{"a"})
+	logger.Info("Merged", "rows", []map[string]any{{"a": 1}, {"b": "two"}})
+
+	want := "[00:00:00] INFO  Imported count=2 tags=[a]\n" +
+		"    batch.results:\n" +
+		"        ID  Name   OK\n" +
+		"        1   alice  true\n" +
+		"        22  bob    false\n" +
+		"[00:00:00] INFO  Merged  \n" +
+		"    rows:\n" +
+		"        a  b\n" +
+		"        1\n" +
+		"           two\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHandler_TableValuesNested(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		DisableColor:   true,
+		TableValues:    true,
+		GroupStyle:     GroupNested,
+		AnyMaxElements: 1,
+	}))
+	logger.Info("Imported", slog.Group("batch", "results", []batchResult{{ID: 1}, {ID: 2}, {ID: 3}}))
+
+	lines := strings.SplitAfter(buf.String(), "\n")[1:]
+	want := "    batch:\n" +
+		"        results:\n" +
+		"            ID  Name  OK\n" +
+		"            1   \"\"    false\n" +
+		"            ... 2 more rows\n"
+	if got := strings.Join(lines, ""); !strings.HasPrefix(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package humanlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// TeeHandler sends every record to a human-readable Handler and to any
+// number of additional handlers, so a single slog.Logger can emit pretty
+// output to the console and machine-readable JSON to a file or aggregator
+// in one call. Each branch applies its own level threshold.
+type TeeHandler struct {
+	branches []slog.Handler
+}
+
+// NewTeeHandler creates a TeeHandler writing to human and others.
+//
+// Example:
+//
+//	tee := humanlog.NewTeeHandler(
+//		humanlog.NewHandler(os.Stderr, nil),
+//		slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}),
+//	)
+func NewTeeHandler(human *Handler, others ...slog.Handler) *TeeHandler {
+	branches := make([]slog.Handler, 0, len(others)+1)
+	branches = append(branches, human)
+	branches = append(branches, others...)
+	return &TeeHandler{branches: branches}
+}
+
+// Enabled reports whether any branch handles records at the given level.
+func (t *TeeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return anyEnabled(ctx, t.branches, level)
+}
+
+// Handle sends the record to every branch enabled for its level.
+// All branches are tried; their errors are joined.
+func (t *TeeHandler) Handle(ctx context.Context, r slog.Record) error {
+	return handleAll(ctx, t.branches, r)
+}
+
+// WithAttrs returns a new TeeHandler whose branches include the given attributes.
+func (t *TeeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TeeHandler{branches: deriveAll(t.branches, func(h slog.Handler) slog.Handler {
+		return h.WithAttrs(attrs)
+	})}
+}
+
+// WithGroup returns a new TeeHandler whose branches use the given group.
+func (t *TeeHandler) WithGroup(name string) slog.Handler {
+	return &TeeHandler{branches: deriveAll(t.branches, func(h slog.Handler) slog.Handler {
+		return h.WithGroup(name)
+	})}
+}
+
+// Close closes every branch that implements io.Closer, such as an
+// AsyncHandler, and joins their errors.
+func (t *TeeHandler) Close() error {
+	var errs []error
+	for _, h := range t.branches {
+		if c, ok := h.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LevelHandler wraps a slog.Handler with an additional minimum level, which
+// is useful for giving a branch of a TeeHandler its own threshold when the
+// wrapped handler's level cannot be configured.
+type LevelHandler struct {
+	level slog.Leveler
+	h     slog.Handler
+}
+
+// NewLevelHandler returns a handler that only passes records at or above
+// level to h.
+func NewLevelHandler(level slog.Leveler, h slog.Handler) *LevelHandler {
+	if lh, ok := h.(*LevelHandler); ok {
+		h = lh.h
+	}
+	return &LevelHandler{level: level, h: h}
+}
+
+// Enabled reports whether level meets both the threshold and h's own level.
+func (lh *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= lh.level.Level() && lh.h.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler.
+func (lh *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return lh.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a new LevelHandler wrapping h.WithAttrs(attrs).
+func (lh *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{level: lh.level, h: lh.h.WithAttrs(attrs)}
+}
+
+// WithGroup returns a new LevelHandler wrapping h.WithGroup(name).
+func (lh *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{level: lh.level, h: lh.h.WithGroup(name)}
+}
+
+// Handler returns the wrapped handler.
+func (lh *LevelHandler) Handler() slog.Handler {
+	return lh.h
+}
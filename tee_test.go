@@ -0,0 +1,41 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTeeHandler_HumanAndJSON(t *testing.T) {
+	console := new(bytes.Buffer)
+	file := new(bytes.Buffer)
+
+	tee := NewTeeHandler(
+		NewHandler(console, &Options{Level: slog.LevelWarn, DisableColor: true}),
+		NewLevelHandler(slog.LevelInfo, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	)
+	logger := slog.New(tee).With(slog.String("service", "api"))
+
+	logger.Debug("Dropped everywhere")
+	logger.Info("File only")
+	logger.Warn("Both branches")
+
+	if got := console.String(); strings.Contains(got, "File only") || !strings.Contains(got, "Both branches") {
+		t.Errorf("console output = %q, want only the warning", got)
+	}
+	if !strings.Contains(console.String(), "service=api") {
+		t.Errorf("console output = %q, should contain service=api", console.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("file output has %d lines, want 2: %q", len(lines), file.String())
+	}
+	if !strings.Contains(lines[0], `"msg":"File only"`) || !strings.Contains(lines[0], `"service":"api"`) {
+		t.Errorf("first JSON line = %s, want File only with service attr", lines[0])
+	}
+	if strings.Contains(file.String(), "Dropped everywhere") {
+		t.Errorf("file output = %q, should respect the branch threshold", file.String())
+	}
+}
@@ -0,0 +1,159 @@
+package humanlog
+
+import (
+	"io"
+	"strconv"
+	"sync"
+)
+
+// clearLine returns the cursor to the start of the line and erases it.
+const clearLine = "\r\033[2K"
+
+// defaultMaxHeld is the number of bytes a paused TerminalWriter holds.
+const defaultMaxHeld = 1 << 20
+
+// TerminalOptions configures a TerminalWriter.
+type TerminalOptions struct {
+	// Clear erases the progress display before log output is written. It
+	// is only called while something drawn is on screen.
+	// Default: erase the current line, enough for a single-line bar or
+	// spinner
+	Clear func(w io.Writer)
+
+	// Redraw, if set, draws the progress display again below the log
+	// output after every write.
+	Redraw func(w io.Writer)
+
+	// MaxHeld is the number of bytes held while paused. Output beyond it
+	// is dropped and reported by a line written on Resume.
+	// Default: 1 MiB
+	MaxHeld int
+}
+
+// TerminalWriter is an io.Writer that shares a terminal between log
+// output and progress bars or spinners, which redraw their line in place
+// and are corrupted by text written in between. Every write first clears
+// what was drawn, then writes the log output and calls Redraw, so records
+// scroll up above the display. Progress updates should be drawn through
+// Draw, or between Lock and Unlock, so they never interleave with a
+// record.
+//
+// Pause holds log output instead, e.g. while a full-screen program owns
+// the terminal, until Resume writes it. It is safe for concurrent use.
+//
+// Example:
+//
+//	tw := humanlog.NewTerminalWriter(os.Stderr, humanlog.TerminalOptions{
+//		Redraw: func(w io.Writer) { fmt.Fprintf(w, "downloading %d%%", pct.Load()) },
+//	})
+//	slog.SetDefault(slog.New(humanlog.NewHandler(tw, nil)))
+//	// on progress: tw.Draw(func(w io.Writer) { fmt.Fprintf(w, "\rdownloading %d%%", pct.Load()) })
+type TerminalWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	opts    TerminalOptions
+	drawn   bool // something drawn is on screen
+	paused  bool
+	held    []byte
+	dropped int
+}
+
+// NewTerminalWriter returns a TerminalWriter writing to w.
+func NewTerminalWriter(w io.Writer, opts TerminalOptions) *TerminalWriter {
+	if opts.Clear == nil {
+		opts.Clear = func(w io.Writer) { _, _ = io.WriteString(w, clearLine) }
+	}
+	if opts.MaxHeld <= 0 {
+		opts.MaxHeld = defaultMaxHeld
+	}
+	return &TerminalWriter{w: w, opts: opts}
+}
+
+// Write clears the display, writes p and redraws the display, or holds p
+// while paused.
+func (t *TerminalWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paused {
+		if len(t.held)+len(p) > t.opts.MaxHeld {
+			t.dropped += len(p)
+		} else {
+			t.held = append(t.held, p...)
+		}
+		return len(p), nil
+	}
+	return t.writeLocked(p)
+}
+
+// writeLocked writes p between clearing and redrawing the display. It must
+// be called with t.mu held.
+func (t *TerminalWriter) writeLocked(p []byte) (int, error) {
+	if t.drawn {
+		t.opts.Clear(t.w)
+		t.drawn = false
+	}
+	n, err := t.w.Write(p)
+	if t.opts.Redraw != nil {
+		t.opts.Redraw(t.w)
+		t.drawn = true
+	}
+	return n, err
+}
+
+// Draw calls fn to update the progress display, holding the lock that
+// log writes take, so the two never interleave. Nothing is drawn while
+// paused.
+func (t *TerminalWriter) Draw(fn func(w io.Writer)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paused {
+		return
+	}
+	fn(t.w)
+	t.drawn = true
+}
+
+// Pause holds log output until Resume, and stops Draw from drawing.
+func (t *TerminalWriter) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = true
+}
+
+// Resume writes the output held since Pause, followed by a line reporting
+// how much was dropped, if any, and writes directly again. The display is
+// assumed to be gone and is only shown again by Redraw or Draw.
+func (t *TerminalWriter) Resume() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.paused {
+		return nil
+	}
+	t.paused = false
+	t.drawn = false
+	held := t.held
+	if t.dropped > 0 {
+		held = append(held, "humanlog: dropped "+strconv.Itoa(t.dropped)+" bytes of log output while paused\n"...)
+	}
+	t.held, t.dropped = nil, 0
+	if len(held) == 0 {
+		return nil
+	}
+	_, err := t.writeLocked(held)
+	return err
+}
+
+// Lock acquires the writer's lock, for progress libraries that write to
+// the terminal themselves; they must call Unlock when done. Log writes
+// block in between.
+func (t *TerminalWriter) Lock() { t.mu.Lock() }
+
+// Unlock releases the lock taken by Lock, noting that the display was
+// drawn.
+func (t *TerminalWriter) Unlock() {
+	t.drawn = true
+	t.mu.Unlock()
+}
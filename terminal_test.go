@@ -0,0 +1,62 @@
+package humanlog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTerminalWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pct := 10
+	tw := NewTerminalWriter(&buf, TerminalOptions{
+		Redraw: func(w io.Writer) { _, _ = io.WriteString(w, "[bar "+strconv.Itoa(pct)+"%]") },
+	})
+	logger := slog.New(NewHandler(tw, &Options{DisableColor: true, TimeFormat: "15:04"}))
+
+	logger.Info("First")
+	pct = 50
+	tw.Draw(func(w io.Writer) { _, _ = io.WriteString(w, clearLine+"[bar 50%]") })
+	logger.Info("Second")
+
+	got := buf.String()
+	// The first record has nothing to clear; every later write clears the bar
+	if strings.HasPrefix(got, clearLine) {
+		t.Errorf("output = %q, want no clear before the first draw", got)
+	}
+	if want := "[bar 50%]" + clearLine + "["; !strings.Contains(got, want) {
+		t.Errorf("output = %q, want the bar cleared before the second record", got)
+	}
+	if !strings.Contains(got, "Second") || !strings.HasSuffix(got, "\n[bar 50%]") {
+		t.Errorf("output = %q, want the bar redrawn below the last record", got)
+	}
+}
+
+func TestTerminalWriter_Pause(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTerminalWriter(&buf, TerminalOptions{MaxHeld: 10})
+
+	tw.Pause()
+	_, _ = io.WriteString(tw, "held\n")
+	_, _ = io.WriteString(tw, "too long\n")
+	tw.Draw(func(w io.Writer) { _, _ = io.WriteString(w, "bar") })
+	if buf.Len() != 0 {
+		t.Fatalf("output while paused = %q, want nothing", buf.String())
+	}
+
+	if err := tw.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	want := "held\nhumanlog: dropped 9 bytes of log output while paused\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output after Resume = %q, want %q", got, want)
+	}
+
+	_, _ = io.WriteString(tw, "direct\n")
+	if got := buf.String(); !strings.HasSuffix(got, "\ndirect\n") {
+		t.Errorf("output = %q, want direct writes after Resume", got)
+	}
+}
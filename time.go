@@ -0,0 +1,172 @@
+package humanlog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// formatTime renders the record timestamp according to the handler's
+// TimeFormat.
+func (h *Handler) formatTime(t time.Time) string {
+	if h.opts.TimeFormat == TimeFormatRelative {
+		return formatElapsed(t.Sub(h.start))
+	}
+	return h.opts.inLocation(t).Format(h.opts.TimeFormat)
+}
+
+// now returns the current time from the configured clock.
+func (o *Options) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+// attrTimeFormat returns the format used for time-valued attributes.
+func (o *Options) attrTimeFormat() string {
+	if o.AttrTimeFormat != "" {
+		return o.AttrTimeFormat
+	}
+	return time.RFC3339
+}
+
+// location returns the time zone times are rendered in, or nil to keep
+// each time's own location.
+func (o *Options) location() *time.Location {
+	if o.UseUTC {
+		return time.UTC
+	}
+	return o.TimeLocation
+}
+
+// inLocation converts t to the configured time zone, if any.
+func (o *Options) inLocation(t time.Time) time.Time {
+	if loc := o.location(); loc != nil {
+		return t.In(loc)
+	}
+	return t
+}
+
+// formatElapsed formats d as "+MM:SS.mmm", growing to "+H:MM:SS.mmm" once
+// an hour has passed. Negative durations are clamped to zero.
+func formatElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	hours := ms / 3600000
+	minutes := ms / 60000 % 60
+	seconds := ms / 1000 % 60
+	millis := ms % 1000
+
+	if hours > 0 {
+		return fmt.Sprintf("+%d:%02d:%02d.%03d", hours, minutes, seconds, millis)
+	}
+	return fmt.Sprintf("+%02d:%02d.%03d", minutes, seconds, millis)
+}
+
+// formatDelta formats d as a fixed-width delta column such as "Δ12ms   ".
+// Durations are rounded so they fit the column: to microseconds below a
+// millisecond, milliseconds below a minute, seconds below an hour and
+// minutes above, which are shown as e.g. "2h05m". Pauses of more than
+// 999 hours are clamped.
+func formatDelta(d time.Duration) string {
+	var s string
+	switch {
+	case d < 0:
+		s = "0s"
+	case d < time.Microsecond:
+		s = d.String()
+	case d < time.Millisecond:
+		s = d.Round(time.Microsecond).String()
+	case d < time.Minute:
+		s = d.Round(time.Millisecond).String()
+	case d < time.Hour:
+		s = d.Round(time.Second).String()
+	default:
+		minutes := int64(d.Round(time.Minute) / time.Minute)
+		if minutes/60 > 999 {
+			s = ">999h"
+		} else {
+			s = fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+		}
+	}
+	return fmt.Sprintf("Δ%-8s", s)
+}
+
+// elidedTime returns the time column for a record whose timestamp repeats
+// the previous one: blanks of the same width, or marker in brackets.
+func elidedTime(timeStr, marker string) string {
+	width := utf8.RuneCountInString(timeStr)
+	if marker == "" {
+		return strings.Repeat(" ", width+3)
+	}
+	return fmt.Sprintf("[%-*s] ", width, marker)
+}
+
+// appendTime appends the record timestamp like formatTime, reusing the
+// previous timestamp when the layout renders both the same.
+func (h *Handler) appendTime(buf *buffer, t time.Time) {
+	if h.opts.TimeFormat == TimeFormatRelative {
+		buf.writeString(formatElapsed(t.Sub(h.start)))
+		return
+	}
+	t = h.opts.inLocation(t)
+	if h.times != nil {
+		*buf = h.times.append(*buf, t)
+		return
+	}
+	*buf = t.AppendFormat(*buf, h.opts.TimeFormat)
+}
+
+// timeCache remembers the last formatted timestamp, so records logged
+// within the same second, or the same millisecond for layouts that show
+// milliseconds, skip formatting it again.
+type timeCache struct {
+	layout string
+	unit   time.Duration
+	last   atomic.Pointer[cachedTime]
+}
+
+type cachedTime struct {
+	slot int64 // time in units since the epoch
+	loc  *time.Location
+	text string
+}
+
+// newTimeCache returns a cache for layout, or nil if the layout shows
+// time finer than milliseconds and would rarely hit.
+func newTimeCache(layout string) *timeCache {
+	if layout == "" || layout == TimeFormatRelative {
+		return nil
+	}
+	// A layout renders every time within a unit alike if it renders the
+	// unit's first and last instants alike
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	for _, unit := range []time.Duration{time.Second, time.Millisecond} {
+		if ref.Format(layout) == ref.Add(unit-1).Format(layout) {
+			return &timeCache{layout: layout, unit: unit}
+		}
+	}
+	return nil
+}
+
+// append appends t formatted with the cache's layout.
+func (c *timeCache) append(dst []byte, t time.Time) []byte {
+	// Slots are only computed for times after the epoch, where dividing
+	// truncates downwards, and well within the range of UnixNano
+	sec := t.Unix()
+	if sec <= 0 || sec >= 1<<33 {
+		return t.AppendFormat(dst, c.layout)
+	}
+	slot := t.UnixNano() / int64(c.unit)
+	if e := c.last.Load(); e != nil && e.slot == slot && e.loc == t.Location() {
+		return append(dst, e.text...)
+	}
+	text := t.Format(c.layout)
+	c.last.Store(&cachedTime{slot: slot, loc: t.Location(), text: text})
+	return append(dst, text...)
+}
@@ -0,0 +1,63 @@
+package humanlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// TraceSampledKey is the attribute key for the sampled flag added by
+// Options.SpanContext.
+const TraceSampledKey = "trace_sampled"
+
+// gcpTraceSampledKey is Google Cloud Logging's sampled flag field.
+const gcpTraceSampledKey = "logging.googleapis.com/trace_sampled"
+
+// SpanContext identifies the active trace span.
+type SpanContext struct {
+	// TraceID is the hex-encoded 16-byte trace ID.
+	TraceID string
+	// SpanID is the hex-encoded 8-byte span ID.
+	SpanID string
+	// Sampled reports whether the trace is sampled.
+	Sampled bool
+}
+
+// SpanContextFunc returns the span active in ctx, or false if there is none.
+//
+// humanlog has no OpenTelemetry dependency; bridge the SDK like this:
+//
+//	func otelSpan(ctx context.Context) (humanlog.SpanContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return humanlog.SpanContext{
+//			TraceID: sc.TraceID().String(),
+//			SpanID:  sc.SpanID().String(),
+//			Sampled: sc.IsSampled(),
+//		}, sc.IsValid()
+//	}
+type SpanContextFunc func(ctx context.Context) (SpanContext, bool)
+
+// ContextSpanContext is a SpanContextFunc returning the IDs stored with
+// WithTraceID and WithSpanID. The sampled flag comes from the traceparent
+// stored by TraceContextMiddleware; other spans are reported as sampled.
+func ContextSpanContext(ctx context.Context) (SpanContext, bool) {
+	traceID, _ := ctx.Value(TraceIDKey).(string)
+	if traceID == "" {
+		return SpanContext{}, false
+	}
+	spanID, _ := ctx.Value(SpanIDKey).(string)
+	sampled := true
+	if tp, ok := TraceParentFromContext(ctx); ok && tp.TraceID == traceID {
+		sampled = tp.Sampled()
+	}
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: sampled}, true
+}
+
+// attrs returns the span as TraceIDKey, SpanIDKey and TraceSampledKey
+// attributes, which the JSON formats map to their trace fields.
+func (sc SpanContext) attrs() []slog.Attr {
+	attrs := []slog.Attr{slog.String(string(TraceIDKey), sc.TraceID)}
+	if sc.SpanID != "" {
+		attrs = append(attrs, slog.String(string(SpanIDKey), sc.SpanID))
+	}
+	return append(attrs, slog.Bool(TraceSampledKey, sc.Sampled))
+}
@@ -0,0 +1,68 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSpanContext_Human(t *testing.T) {
+	var buf bytes.Buffer
+	spanFunc := func(ctx context.Context) (SpanContext, bool) {
+		return SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}, true
+	}
+	logger := slog.New(NewHandler(&buf, &Options{Level: slog.LevelInfo, DisableColor: true, SpanContext: spanFunc}))
+	logger.InfoContext(context.Background(), "Traced")
+
+	for _, want := range []string{"trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7", "trace_sampled=true"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output %q missing %q", buf.String(), want)
+		}
+	}
+}
+
+func TestSpanContext_GoogleCloud(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{
+		Level:        slog.LevelInfo,
+		UseJSON:      true,
+		JSONFormat:   JSONGoogleCloud,
+		GCPProjectID: "proj",
+		SpanContext:  ContextSpanContext,
+	}))
+	ctx := WithSpanID(WithTraceID(context.Background(), "abc"), "def")
+	logger.InfoContext(ctx, "Traced")
+
+	m := decodeJSONLine(t, &buf)
+	if m[gcpTraceKey] != "projects/proj/traces/abc" || m[gcpSpanIDKey] != "def" || m[gcpTraceSampledKey] != true {
+		t.Errorf("trace fields = %v", m)
+	}
+}
+
+func TestSpanContext_Datadog(t *testing.T) {
+	var buf bytes.Buffer
+	spanFunc := func(ctx context.Context) (SpanContext, bool) {
+		return SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}, true
+	}
+	logger := slog.New(NewHandler(&buf, &Options{
+		Level:       slog.LevelInfo,
+		UseJSON:     true,
+		JSONFormat:  JSONDatadog,
+		SpanContext: spanFunc,
+	}))
+	logger.InfoContext(context.Background(), "Traced")
+
+	// Datadog correlates on the decimal low 64 bits of the IDs
+	m := decodeJSONLine(t, &buf)
+	if m[ddTraceIDKey] != "11803532876627986230" || m[ddSpanIDKey] != "67667974448284343" {
+		t.Errorf("trace fields = %v", m)
+	}
+}
+
+func TestContextSpanContext_NoTrace(t *testing.T) {
+	if _, ok := ContextSpanContext(context.Background()); ok {
+		t.Error("ContextSpanContext() reported a span for an empty context")
+	}
+}
@@ -0,0 +1,145 @@
+package humanlog
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// VerbosityLevel returns the level of verbosity n in the style of glog,
+// klog and logr: V(0) is Info, and each step is one level below, so V(4)
+// is Debug and V(8) is LevelTrace.
+func VerbosityLevel(n int) slog.Level {
+	return slog.LevelInfo - slog.Level(n)
+}
+
+// Verbosity is a glog-style verbosity number. It implements flag.Value as
+// a boolean flag, so a bare -v adds one and -v=3 sets it, and slog.Leveler,
+// so it can be used directly as a minimum level.
+type Verbosity int
+
+// String returns the verbosity number.
+func (v *Verbosity) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// Set adds one for "true", as passed by a bare -v, and otherwise parses a
+// non-negative number.
+func (v *Verbosity) Set(s string) error {
+	if s == "true" {
+		*v++
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return fmt.Errorf("humanlog: invalid verbosity %q", s)
+	}
+	*v = Verbosity(n)
+	return nil
+}
+
+// IsBoolFlag lets -v be given without a value.
+func (v *Verbosity) IsBoolFlag() bool { return true }
+
+// Level returns VerbosityLevel(v), implementing slog.Leveler.
+func (v Verbosity) Level() slog.Level {
+	return VerbosityLevel(int(v))
+}
+
+// verbosityShorthand is a flag such as -vv that raises a Verbosity by a
+// fixed amount.
+type verbosityShorthand struct {
+	v *Verbosity
+	n int
+}
+
+func (f verbosityShorthand) String() string   { return "false" }
+func (f verbosityShorthand) IsBoolFlag() bool { return true }
+
+func (f verbosityShorthand) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("humanlog: invalid value %q", s)
+	}
+	if on {
+		*f.v += Verbosity(f.n)
+	}
+	return nil
+}
+
+// RegisterVerbosityFlags registers -v, -vv and -vvv on fs. -v may be
+// repeated or given a number, as in -v=4; -vv and -vvv add two and three.
+// Use the returned Verbosity as the handler level after fs has been
+// parsed.
+//
+// Example:
+//
+//	v := humanlog.RegisterVerbosityFlags(flag.CommandLine)
+//	flag.Parse()
+//	opts := humanlog.DefaultOptions()
+//	opts.Level = v.Level()
+//	slog.SetDefault(slog.New(humanlog.NewHandler(os.Stderr, opts)))
+//	humanlog.V(2).Info("Cache warmed", "entries", n)
+func RegisterVerbosityFlags(fs *flag.FlagSet) *Verbosity {
+	v := new(Verbosity)
+	fs.Var(v, "v", "log `verbosity`; repeat -v or give a number for more detail")
+	fs.Var(verbosityShorthand{v, 2}, "vv", "same as -v=2")
+	fs.Var(verbosityShorthand{v, 3}, "vvv", "same as -v=3")
+	return v
+}
+
+// VLogger wraps an slog.Logger with V(n) verbosity levels, for code
+// written against glog, klog or logr.
+type VLogger struct {
+	logger *slog.Logger
+}
+
+// NewVLogger creates a VLogger writing to logger.
+func NewVLogger(logger *slog.Logger) *VLogger {
+	return &VLogger{logger: logger}
+}
+
+// V returns the logger for verbosity n, whose records are logged at
+// VerbosityLevel(n).
+func (l *VLogger) V(n int) Verbose {
+	return Verbose{logger: l.logger, level: VerbosityLevel(n)}
+}
+
+// Logger returns the wrapped logger.
+func (l *VLogger) Logger() *slog.Logger {
+	return l.logger
+}
+
+// V returns the default logger for verbosity n.
+func V(n int) Verbose {
+	return Verbose{logger: slog.Default(), level: VerbosityLevel(n)}
+}
+
+// Verbose logs at one verbosity level. Whether it is enabled is decided by
+// the handler, so a handler at Info drops every V(n) with n > 0.
+type Verbose struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// Enabled reports whether records at this verbosity are logged, to guard
+// expensive argument computation.
+func (v Verbose) Enabled() bool {
+	return v.logger.Enabled(context.Background(), v.level)
+}
+
+// Info logs msg with the given key-value pairs or attributes.
+func (v Verbose) Info(msg string, args ...any) {
+	v.logger.Log(context.Background(), v.level, msg, args...)
+}
+
+// InfoContext logs msg with the given context, key-value pairs or
+// attributes.
+func (v Verbose) InfoContext(ctx context.Context, msg string, args ...any) {
+	v.logger.Log(ctx, v.level, msg, args...)
+}
@@ -0,0 +1,69 @@
+package humanlog
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRegisterVerbosityFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want Verbosity
+	}{
+		{nil, 0},
+		{[]string{"-v"}, 1},
+		{[]string{"-v", "-v"}, 2},
+		{[]string{"-vv"}, 2},
+		{[]string{"-vvv", "-v"}, 4},
+		{[]string{"-v=5"}, 5},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(new(bytes.Buffer))
+		v := RegisterVerbosityFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.args, err)
+		}
+		if *v != tt.want {
+			t.Errorf("Parse(%q) verbosity = %d, want %d", tt.args, *v, tt.want)
+		}
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	RegisterVerbosityFlags(fs)
+	if err := fs.Parse([]string{"-v=-1"}); err == nil {
+		t.Error("Parse(-v=-1) error = nil, want error")
+	}
+}
+
+func TestVLogger(t *testing.T) {
+	if got := VerbosityLevel(4); got != slog.LevelDebug {
+		t.Errorf("VerbosityLevel(4) = %v, want DEBUG", got)
+	}
+	if got := VerbosityLevel(8); got != LevelTrace {
+		t.Errorf("VerbosityLevel(8) = %v, want TRACE", got)
+	}
+
+	var buf bytes.Buffer
+	verbosity := Verbosity(2)
+	l := NewVLogger(slog.New(NewHandler(&buf, &Options{Level: verbosity.Level(), DisableColor: true})))
+
+	if !l.V(2).Enabled() || l.V(3).Enabled() {
+		t.Errorf("Enabled() at -v=2: V(2) = %v, V(3) = %v, want true, false", l.V(2).Enabled(), l.V(3).Enabled())
+	}
+	l.V(0).Info("Shown always", "n", 1)
+	l.V(2).Info("Shown at two")
+	l.V(3).Info("Hidden")
+
+	out := buf.String()
+	if !strings.Contains(out, "Shown always") || !strings.Contains(out, "Shown at two") {
+		t.Errorf("output = %q, want V(0) and V(2) records", out)
+	}
+	if strings.Contains(out, "Hidden") {
+		t.Errorf("output = %q, want V(3) dropped", out)
+	}
+}
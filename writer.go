@@ -0,0 +1,103 @@
+package humanlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// maxAdapterLine is the length at which a partial line is emitted without
+// waiting for its newline, so a writer that never sends one cannot grow
+// the buffer without bound.
+const maxAdapterLine = 64 * 1024
+
+// WriterAdapter is an io.Writer that logs each line written to it as a
+// record. It suits exec.Cmd output, libraries that log to an io.Writer and
+// http.Server.ErrorLog via log.New. Call Close to log a final line that
+// lacks a trailing newline.
+type WriterAdapter struct {
+	logger *slog.Logger
+	level  slog.Level
+	attrs  []slog.Attr
+	lines  lineSplitter
+}
+
+// NewWriterAdapter returns a WriterAdapter logging lines at level with the
+// given attributes, e.g. slog.String("stream", "stderr").
+func NewWriterAdapter(logger *slog.Logger, level slog.Level, attrs ...slog.Attr) *WriterAdapter {
+	w := &WriterAdapter{logger: logger, level: level, attrs: attrs}
+	w.lines.emit = w.logLine
+	return w
+}
+
+// Write logs every complete line in p and buffers the remainder. Trailing
+// carriage returns are removed and blank lines are skipped. It never
+// returns an error.
+func (w *WriterAdapter) Write(p []byte) (int, error) {
+	w.lines.write(p)
+	return len(p), nil
+}
+
+// Close logs any buffered partial line. The adapter remains usable.
+func (w *WriterAdapter) Close() error {
+	w.lines.flush()
+	return nil
+}
+
+func (w *WriterAdapter) logLine(line []byte) {
+	w.logger.LogAttrs(context.Background(), w.level, string(line), w.attrs...)
+}
+
+// lineSplitter splits a byte stream into lines, passing each non-blank
+// line without its line ending to emit.
+type lineSplitter struct {
+	emit func(line []byte)
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// write emits every complete line in p and buffers the remainder.
+func (s *lineSplitter) write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			break
+		}
+		s.emitLine(s.buf[:i])
+		s.buf = s.buf[i+1:]
+	}
+	if len(s.buf) >= maxAdapterLine {
+		s.emitLine(s.buf)
+		s.buf = s.buf[:0]
+	}
+
+	// Reclaim the consumed prefix of the buffer
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+}
+
+// flush emits any buffered partial line.
+func (s *lineSplitter) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buf) > 0 {
+		s.emitLine(s.buf)
+		s.buf = nil
+	}
+}
+
+func (s *lineSplitter) emitLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	s.emit(line)
+}
@@ -0,0 +1,43 @@
+package humanlog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/lepinkainen/humanlog/humanlogtest"
+)
+
+func TestWriterAdapter(t *testing.T) {
+	capture := humanlogtest.NewCaptureHandler(slog.LevelDebug)
+	w := NewWriterAdapter(slog.New(capture), slog.LevelWarn, slog.String("stream", "stderr"))
+
+	_, _ = w.Write([]byte("first line\r\nsecond "))
+	_, _ = w.Write([]byte("line\n\n   \nthird"))
+	if got := len(capture.Entries()); got != 2 {
+		t.Fatalf("entries before Close = %d, want 2", got)
+	}
+	_ = w.Close()
+
+	entries := capture.Entries()
+	want := []string{"first line", "second line", "third"}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %d, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		stream, _ := e.AttrValue("stream")
+		if e.Message != want[i] || e.Level != slog.LevelWarn || stream.String() != "stderr" {
+			t.Errorf("entry %d = %+v", i, e)
+		}
+	}
+}
+
+func TestWriterAdapter_LongLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterAdapter(slog.New(NewHandler(&buf, &Options{Level: slog.LevelInfo, DisableColor: true})), slog.LevelInfo)
+	_, _ = w.Write([]byte(strings.Repeat("x", maxAdapterLine)))
+	if buf.Len() == 0 {
+		t.Error("unterminated line over the limit was not logged")
+	}
+}